@@ -1,6 +1,8 @@
 package gqlscan_test
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/dustin/go-humanize"
@@ -108,3 +110,45 @@ func BenchmarkScanInterpreted(b *testing.B) {
 		})
 	}
 }
+
+// concatenated returns all valid test inputs as a single document.
+func concatenated() []byte {
+	var s strings.Builder
+	for _, td := range testdata {
+		s.WriteString(td.input)
+		s.WriteString("\n")
+	}
+	return []byte(s.String())
+}
+
+// BenchmarkScanAllConcatenated scans all valid test inputs
+// as a single document. Compare it to earlier versions
+// to detect regressions of the in-memory scanner.
+func BenchmarkScanAllConcatenated(b *testing.B) {
+	in := concatenated()
+	b.SetBytes(int64(len(in)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := gqlscan.ScanAll(in, func(*gqlscan.Iterator) {
+		}); err.IsErr() {
+			panic(err)
+		}
+	}
+}
+
+func BenchmarkScanReaderConcatenated(b *testing.B) {
+	in := concatenated()
+	r := bytes.NewReader(in)
+	b.SetBytes(int64(len(in)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Reset(in)
+		if err := gqlscan.ScanReader(
+			r, gqlscan.ReaderConfig{MaxDefSize: 4 * 1024},
+			func(*gqlscan.Iterator) (err bool) { return false },
+		); err != nil {
+			panic(err)
+		}
+	}
+}
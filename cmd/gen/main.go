@@ -40,7 +40,16 @@ func main() {
 	}
 	defer fl.Close()
 
-	t := template.New("").Funcs(sprig.TxtFuncMap())
+	// reader is true while the scan body of ScanReader is generated
+	// making the templates emit the code that moves the window.
+	var reader bool
+	t := template.New("").Funcs(sprig.TxtFuncMap()).Funcs(template.FuncMap{
+		"reader": func() bool { return reader },
+		"setReader": func(on bool) string {
+			reader = on
+			return ""
+		},
+	})
 	if err := fs.WalkDir(
 		tmpls,
 		".",
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"github.com/graph-guard/gqlscan"
+)
+
+// document is an open text document.
+type document struct {
+	text []byte
+
+	// lines are the indexes of the beginnings of the lines.
+	lines []int
+
+	defs []definition
+
+	// spreads are the names of the fragment spreads.
+	spreads []gqlscan.Span
+
+	// folds are the spans of multiline selection sets and block strings.
+	folds []gqlscan.Span
+
+	err gqlscan.Error
+}
+
+// definition is an executable definition.
+type definition struct {
+	// token is either TokenDefQry, TokenDefMut, TokenDefSub
+	// or TokenDefFrag.
+	token gqlscan.Token
+
+	// name is the span of the operation or fragment name.
+	// Empty for anonymous operations.
+	name gqlscan.Span
+
+	typeCond []byte
+
+	// span is the span of the whole definition, a definition
+	// cut short by a syntax error ends at the end of the document.
+	span gqlscan.Span
+}
+
+// newDocument scans text and records the tokens before
+// the first syntax error.
+func newDocument(text []byte) *document {
+	d := &document{text: text, lines: []int{0}}
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\r':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				i++
+			}
+			d.lines = append(d.lines, i+1)
+		case '\n':
+			d.lines = append(d.lines, i+1)
+		}
+	}
+
+	var sets []int
+	d.err = gqlscan.ScanAll(text, func(i *gqlscan.Iterator) {
+		switch i.Token() {
+		case gqlscan.TokenDefQry, gqlscan.TokenDefMut,
+			gqlscan.TokenDefSub, gqlscan.TokenDefFrag:
+			d.defs = append(d.defs, definition{
+				token: i.Token(),
+				span:  gqlscan.Span{Start: i.IndexHead(), End: len(text)},
+			})
+		case gqlscan.TokenOprName, gqlscan.TokenFragName:
+			d.defs[len(d.defs)-1].name = gqlscan.Span{
+				Start: i.IndexTail(), End: i.IndexHead(),
+			}
+		case gqlscan.TokenFragTypeCond:
+			d.defs[len(d.defs)-1].typeCond = i.Value()
+		case gqlscan.TokenNamedSpread:
+			d.spreads = append(d.spreads, gqlscan.Span{
+				Start: i.IndexTail(), End: i.IndexHead(),
+			})
+		case gqlscan.TokenSet:
+			sets = append(sets, i.IndexHead())
+		case gqlscan.TokenSetEnd:
+			d.fold(sets[len(sets)-1], i.IndexHead())
+			sets = sets[:len(sets)-1]
+			if i.LevelSelect() == 1 {
+				d.defs[len(d.defs)-1].span.End = i.IndexHead() + 1
+			}
+		case gqlscan.TokenStrBlock:
+			d.fold(i.IndexTail(), i.IndexHead())
+		}
+	})
+	return d
+}
+
+// fold records a folding range if start and end are on different lines.
+func (d *document) fold(start, end int) {
+	if d.line(start) < d.line(end) {
+		d.folds = append(d.folds, gqlscan.Span{Start: start, End: end})
+	}
+}
+
+// line returns the zero-based line of the character at index.
+func (d *document) line(index int) int {
+	return sort.Search(len(d.lines), func(l int) bool {
+		return d.lines[l] > index
+	}) - 1
+}
+
+// position returns the position of the character at index.
+// Characters are counted in UTF-16 code units.
+func (d *document) position(index int) Position {
+	if index > len(d.text) {
+		index = len(d.text)
+	}
+	p := Position{Line: d.line(index)}
+	for i := d.lines[p.Line]; i < index; {
+		r, w := utf8.DecodeRune(d.text[i:])
+		if i += w; r >= 0x10000 {
+			p.Character += 2
+		} else {
+			p.Character++
+		}
+	}
+	return p
+}
+
+// index returns the index of the character at p.
+// Positions beyond the end of a line resolve to the end of the line.
+func (d *document) index(p Position) int {
+	if p.Line < 0 {
+		return 0
+	}
+	if p.Line >= len(d.lines) {
+		return len(d.text)
+	}
+	i, c := d.lines[p.Line], 0
+	for i < len(d.text) && c < p.Character &&
+		d.text[i] != '\n' && d.text[i] != '\r' {
+		r, w := utf8.DecodeRune(d.text[i:])
+		if i += w; r >= 0x10000 {
+			c += 2
+		} else {
+			c++
+		}
+	}
+	return i
+}
+
+func (d *document) rangeOf(s gqlscan.Span) Range {
+	return Range{Start: d.position(s.Start), End: d.position(s.End)}
+}
+
+// symbols returns the symbols of the definitions.
+func (d *document) symbols() []DocumentSymbol {
+	r := make([]DocumentSymbol, len(d.defs))
+	for n, def := range d.defs {
+		sym := DocumentSymbol{
+			Name:           string(def.name.In(d.text)),
+			Kind:           symbolKindFunction,
+			Range:          d.rangeOf(def.span),
+			SelectionRange: d.rangeOf(def.name),
+		}
+		switch def.token {
+		case gqlscan.TokenDefQry:
+			sym.Detail = "query"
+		case gqlscan.TokenDefMut:
+			sym.Detail = "mutation"
+		case gqlscan.TokenDefSub:
+			sym.Detail = "subscription"
+		case gqlscan.TokenDefFrag:
+			sym.Detail = "fragment on " + string(def.typeCond)
+			sym.Kind = symbolKindClass
+		}
+		if sym.Name == "" {
+			// Names of symbols mustn't be empty
+			sym.Name = "anonymous " + sym.Detail
+			sym.SelectionRange = Range{
+				Start: sym.Range.Start, End: sym.Range.Start,
+			}
+		}
+		r[n] = sym
+	}
+	return r
+}
+
+// fragAt returns the name of the fragment whose name or spread
+// is at index and whether it's a spread.
+func (d *document) fragAt(index int) (name []byte, spread bool) {
+	in := func(s gqlscan.Span) bool {
+		return s.End > s.Start && index >= s.Start && index <= s.End
+	}
+	for _, s := range d.spreads {
+		if in(s) {
+			return s.In(d.text), true
+		}
+	}
+	for _, def := range d.defs {
+		if def.token == gqlscan.TokenDefFrag && in(def.name) {
+			return def.name.In(d.text), false
+		}
+	}
+	return nil, false
+}
+
+// fragDef returns the fragment definition called name.
+func (d *document) fragDef(name []byte) (definition, bool) {
+	for _, def := range d.defs {
+		if def.token == gqlscan.TokenDefFrag &&
+			string(def.name.In(d.text)) == string(name) {
+			return def, true
+		}
+	}
+	return definition{}, false
+}
@@ -0,0 +1,20 @@
+// Command gqlscan-lsp is a Language Server Protocol server
+// for GraphQL executable documents speaking over stdio.
+//
+// It provides syntax diagnostics, document symbols, folding ranges,
+// semantic tokens, go-to-definition for fragment spreads
+// and references of fragments.
+package main
+
+import (
+	"log"
+	"os"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("gqlscan-lsp: ")
+	if err := serve(os.Stdin, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import "encoding/json"
+
+// The following types mirror the structures of the same name
+// defined by the Language Server Protocol specification.
+
+// Position is a zero-based position in a text document.
+// Character is counted in UTF-16 code units.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+const diagnosticSeverityError = 1
+
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+type PublishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// Symbol kinds used for definitions.
+const (
+	symbolKindClass    = 5
+	symbolKindFunction = 12
+)
+
+type DocumentSymbol struct {
+	Name           string `json:"name"`
+	Detail         string `json:"detail,omitempty"`
+	Kind           int    `json:"kind"`
+	Range          Range  `json:"range"`
+	SelectionRange Range  `json:"selectionRange"`
+}
+
+type FoldingRange struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine"`
+}
+
+type SemanticTokens struct {
+	Data []uint32 `json:"data"`
+}
+
+type TextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type TextDocumentPositionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+type ReferenceParams struct {
+	TextDocumentPositionParams
+	Context struct {
+		IncludeDeclaration bool `json:"includeDeclaration"`
+	} `json:"context"`
+}
+
+type DidOpenTextDocumentParams struct {
+	TextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	} `json:"textDocument"`
+}
+
+type DidChangeTextDocumentParams struct {
+	TextDocument   TextDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		// Range is nil for full content changes,
+		// which is the only kind of change the server accepts.
+		Range *json.RawMessage `json:"range"`
+		Text  string           `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type DidCloseTextDocumentParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// textDocumentSyncFull makes the client send the full content
+// of a document on every change.
+const textDocumentSyncFull = 1
+
+type InitializeResult struct {
+	Capabilities struct {
+		TextDocumentSync       int  `json:"textDocumentSync"`
+		DocumentSymbolProvider bool `json:"documentSymbolProvider"`
+		FoldingRangeProvider   bool `json:"foldingRangeProvider"`
+		DefinitionProvider     bool `json:"definitionProvider"`
+		ReferencesProvider     bool `json:"referencesProvider"`
+		SemanticTokensProvider struct {
+			Legend struct {
+				TokenTypes     []string `json:"tokenTypes"`
+				TokenModifiers []string `json:"tokenModifiers"`
+			} `json:"legend"`
+			Full bool `json:"full"`
+		} `json:"semanticTokensProvider"`
+	} `json:"capabilities"`
+	ServerInfo struct {
+		Name string `json:"name"`
+	} `json:"serverInfo"`
+}
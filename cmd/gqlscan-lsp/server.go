@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/highlight"
+)
+
+// errExitWithoutShutdown is returned by serve when the client
+// requests the server to exit without requesting a shutdown first.
+var errExitWithoutShutdown = errors.New("exit without shutdown")
+
+type server struct {
+	w        io.Writer
+	docs     map[string]*document
+	shutdown bool
+}
+
+// serve reads requests from r and writes responses to w
+// until the client requests the server to exit.
+func serve(r io.Reader, w io.Writer) error {
+	s := &server{w: w, docs: map[string]*document{}}
+	br := bufio.NewReader(r)
+	for {
+		content, err := readMessage(br)
+		if errors.Is(err, io.EOF) {
+			return errExitWithoutShutdown
+		} else if err != nil {
+			return err
+		}
+
+		var m message
+		if err := json.Unmarshal(content, &m); err != nil {
+			err = s.respondErr(nil, &responseError{
+				Code: codeParseError, Message: err.Error(),
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if m.Method == "exit" {
+			if !s.shutdown {
+				return errExitWithoutShutdown
+			}
+			return nil
+		}
+
+		result, err := s.handle(m)
+		var rerr *responseError
+		if err != nil && !errors.As(err, &rerr) {
+			return err
+		}
+		switch {
+		case len(m.ID) == 0:
+			// Notifications are never answered
+			if rerr != nil && rerr.Code != codeMethodNotFound {
+				log.Printf("%s: %s", m.Method, rerr.Message)
+			}
+			continue
+		case rerr != nil:
+			err = s.respondErr(m.ID, rerr)
+		default:
+			err = writeMessage(s.w, response{
+				JSONRPC: "2.0", ID: m.ID, Result: result,
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *server) respondErr(id json.RawMessage, e *responseError) error {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return writeMessage(s.w, errorResponse{JSONRPC: "2.0", ID: id, Error: e})
+}
+
+func (s *server) notify(method string, params interface{}) error {
+	return writeMessage(s.w, notification{
+		JSONRPC: "2.0", Method: method, Params: params,
+	})
+}
+
+// handle handles m and returns the result.
+// Returns a *responseError if the request failed
+// and other errors if writing to the client failed.
+func (s *server) handle(m message) (interface{}, error) {
+	if s.shutdown {
+		return nil, &responseError{
+			Code: codeInvalidRequest, Message: "server is shut down",
+		}
+	}
+	params := func(v interface{}) error {
+		if err := json.Unmarshal(m.Params, v); err != nil {
+			return &responseError{
+				Code:    codeInvalidParams,
+				Message: fmt.Sprintf("invalid params: %v", err),
+			}
+		}
+		return nil
+	}
+
+	switch m.Method {
+	case "initialize":
+		var r InitializeResult
+		r.Capabilities.TextDocumentSync = textDocumentSyncFull
+		r.Capabilities.DocumentSymbolProvider = true
+		r.Capabilities.FoldingRangeProvider = true
+		r.Capabilities.DefinitionProvider = true
+		r.Capabilities.ReferencesProvider = true
+		r.Capabilities.SemanticTokensProvider.Legend.TokenTypes =
+			highlight.SemanticTokenTypes
+		r.Capabilities.SemanticTokensProvider.Legend.TokenModifiers =
+			highlight.SemanticTokenModifiers
+		r.Capabilities.SemanticTokensProvider.Full = true
+		r.ServerInfo.Name = "gqlscan-lsp"
+		return r, nil
+
+	case "initialized":
+		return nil, nil
+
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var p DidOpenTextDocumentParams
+		if err := params(&p); err != nil {
+			return nil, err
+		}
+		return nil, s.update(p.TextDocument.URI, p.TextDocument.Text)
+
+	case "textDocument/didChange":
+		var p DidChangeTextDocumentParams
+		if err := params(&p); err != nil {
+			return nil, err
+		}
+		if len(p.ContentChanges) < 1 {
+			return nil, nil
+		}
+		c := p.ContentChanges[len(p.ContentChanges)-1]
+		if c.Range != nil {
+			return nil, &responseError{
+				Code:    codeInvalidParams,
+				Message: "incremental content changes are not supported",
+			}
+		}
+		return nil, s.update(p.TextDocument.URI, c.Text)
+
+	case "textDocument/didClose":
+		var p DidCloseTextDocumentParams
+		if err := params(&p); err != nil {
+			return nil, err
+		}
+		delete(s.docs, p.TextDocument.URI)
+		return nil, s.publishDiagnostics(p.TextDocument.URI, []Diagnostic{})
+
+	case "textDocument/documentSymbol":
+		var p struct {
+			TextDocument TextDocumentIdentifier `json:"textDocument"`
+		}
+		if err := params(&p); err != nil {
+			return nil, err
+		}
+		d, err := s.doc(p.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		return d.symbols(), nil
+
+	case "textDocument/foldingRange":
+		var p struct {
+			TextDocument TextDocumentIdentifier `json:"textDocument"`
+		}
+		if err := params(&p); err != nil {
+			return nil, err
+		}
+		d, err := s.doc(p.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		r := make([]FoldingRange, len(d.folds))
+		for n, f := range d.folds {
+			r[n] = FoldingRange{StartLine: d.line(f.Start), EndLine: d.line(f.End)}
+		}
+		return r, nil
+
+	case "textDocument/semanticTokens/full":
+		var p struct {
+			TextDocument TextDocumentIdentifier `json:"textDocument"`
+		}
+		if err := params(&p); err != nil {
+			return nil, err
+		}
+		d, err := s.doc(p.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		data := highlight.SemanticTokens(d.text)
+		if data == nil {
+			data = []uint32{}
+		}
+		return SemanticTokens{Data: data}, nil
+
+	case "textDocument/definition":
+		var p TextDocumentPositionParams
+		if err := params(&p); err != nil {
+			return nil, err
+		}
+		d, err := s.doc(p.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		name, spread := d.fragAt(d.index(p.Position))
+		if !spread {
+			return nil, nil
+		}
+		for _, uri := range s.uris(p.TextDocument.URI) {
+			if def, ok := s.docs[uri].fragDef(name); ok {
+				return Location{
+					URI: uri, Range: s.docs[uri].rangeOf(def.name),
+				}, nil
+			}
+		}
+		return nil, nil
+
+	case "textDocument/references":
+		var p ReferenceParams
+		if err := params(&p); err != nil {
+			return nil, err
+		}
+		d, err := s.doc(p.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		name, _ := d.fragAt(d.index(p.Position))
+		if name == nil {
+			return nil, nil
+		}
+		r := []Location{}
+		for _, uri := range s.uris(p.TextDocument.URI) {
+			d := s.docs[uri]
+			if def, ok := d.fragDef(name); ok && p.Context.IncludeDeclaration {
+				r = append(r, Location{URI: uri, Range: d.rangeOf(def.name)})
+			}
+			for _, sp := range d.spreads {
+				if string(sp.In(d.text)) == string(name) {
+					r = append(r, Location{URI: uri, Range: d.rangeOf(sp)})
+				}
+			}
+		}
+		return r, nil
+	}
+	return nil, &responseError{
+		Code:    codeMethodNotFound,
+		Message: fmt.Sprintf("method %q not found", m.Method),
+	}
+}
+
+func (s *server) doc(uri string) (*document, error) {
+	d, ok := s.docs[uri]
+	if !ok {
+		return nil, &responseError{
+			Code:    codeInvalidParams,
+			Message: fmt.Sprintf("document %q isn't open", uri),
+		}
+	}
+	return d, nil
+}
+
+// uris returns the URIs of all open documents
+// starting with first followed by the others in lexical order.
+func (s *server) uris(first string) []string {
+	uris := make([]string, 0, len(s.docs))
+	for uri := range s.docs {
+		if uri != first {
+			uris = append(uris, uri)
+		}
+	}
+	sort.Strings(uris)
+	return append([]string{first}, uris...)
+}
+
+// update replaces the content of a document and publishes
+// its diagnostics.
+func (s *server) update(uri, text string) error {
+	d := newDocument([]byte(text))
+	s.docs[uri] = d
+	diagnostics := []Diagnostic{}
+	if d.err.IsErr() {
+		end := d.err.Index
+		if end < len(d.text) {
+			_, w := utf8.DecodeRune(d.text[end:])
+			end += w
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Range: d.rangeOf(gqlscan.Span{
+				Start: d.err.Index, End: end,
+			}),
+			Severity: diagnosticSeverityError,
+			Source:   "gqlscan",
+			Message:  d.err.Error(),
+		})
+	}
+	return s.publishDiagnostics(uri, diagnostics)
+}
+
+func (s *server) publishDiagnostics(
+	uri string, diagnostics []Diagnostic,
+) error {
+	return s.notify(
+		"textDocument/publishDiagnostics",
+		PublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics},
+	)
+}
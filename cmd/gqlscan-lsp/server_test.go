@@ -0,0 +1,378 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// client is a scripted client talking to an in-process server.
+type client struct {
+	t      *testing.T
+	w      io.Writer
+	r      *bufio.Reader
+	nextID int
+	done   chan error
+}
+
+func newClient(t *testing.T) *client {
+	sr, cw := io.Pipe()
+	cr, sw := io.Pipe()
+	c := &client{t: t, w: cw, r: bufio.NewReader(cr), done: make(chan error, 1)}
+	go func() {
+		err := serve(sr, sw)
+		sw.Close()
+		c.done <- err
+	}()
+	t.Cleanup(func() { cw.Close(); cr.Close() })
+	return c
+}
+
+func (c *client) send(v interface{}) {
+	require.NoError(c.t, writeMessage(c.w, v))
+}
+
+// read reads the next message from the server into v.
+func (c *client) read(v interface{}) {
+	content, err := readMessage(c.r)
+	require.NoError(c.t, err)
+	require.NoError(c.t, json.Unmarshal(content, v))
+}
+
+func (c *client) notify(method string, params interface{}) {
+	c.send(map[string]interface{}{
+		"jsonrpc": "2.0", "method": method, "params": params,
+	})
+}
+
+// request sends a request and returns the result as JSON.
+func (c *client) request(method string, params interface{}) string {
+	c.nextID++
+	c.send(map[string]interface{}{
+		"jsonrpc": "2.0", "id": c.nextID, "method": method, "params": params,
+	})
+	var r struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *responseError  `json:"error"`
+	}
+	c.read(&r)
+	require.Equal(c.t, c.nextID, r.ID)
+	require.Nil(c.t, r.Error)
+	return string(r.Result)
+}
+
+// requestErr sends a request and returns the error.
+func (c *client) requestErr(method string, params interface{}) responseError {
+	c.nextID++
+	c.send(map[string]interface{}{
+		"jsonrpc": "2.0", "id": c.nextID, "method": method, "params": params,
+	})
+	var r struct {
+		ID    int            `json:"id"`
+		Error *responseError `json:"error"`
+	}
+	c.read(&r)
+	require.Equal(c.t, c.nextID, r.ID)
+	require.NotNil(c.t, r.Error)
+	return *r.Error
+}
+
+// diagnostics reads a diagnostics notification.
+func (c *client) diagnostics() PublishDiagnosticsParams {
+	var n struct {
+		Method string                   `json:"method"`
+		Params PublishDiagnosticsParams `json:"params"`
+	}
+	c.read(&n)
+	require.Equal(c.t, "textDocument/publishDiagnostics", n.Method)
+	return n.Params
+}
+
+func (c *client) open(uri, text string) PublishDiagnosticsParams {
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{
+			"uri": uri, "languageId": "graphql", "version": 1, "text": text,
+		},
+	})
+	return c.diagnostics()
+}
+
+func (c *client) exit() error {
+	c.request("shutdown", nil)
+	c.notify("exit", nil)
+	return <-c.done
+}
+
+func pos(uri string, line, char int) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position":     Position{Line: line, Character: char},
+	}
+}
+
+func doc(uri string) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+	}
+}
+
+const (
+	uriA = "file:///a.graphql"
+	uriB = "file:///b.graphql"
+)
+
+const docA = `query Q($v: Int) {
+  a(x: $v) {
+    ...F
+  }
+  ... on T { b }
+}
+fragment F on T {
+  c(s: """
+    😀 block
+  """)
+  ...G
+}
+{ x }`
+
+const docB = "fragment G on T { ...F }"
+
+func TestServer(t *testing.T) {
+	c := newClient(t)
+	require.JSONEq(t, `{
+		"capabilities": {
+			"textDocumentSync": 1,
+			"documentSymbolProvider": true,
+			"foldingRangeProvider": true,
+			"definitionProvider": true,
+			"referencesProvider": true,
+			"semanticTokensProvider": {
+				"legend": {
+					"tokenTypes": [
+						"keyword", "operator", "comment", "function", "type",
+						"property", "parameter", "decorator", "variable",
+						"string", "number", "enumMember"
+					],
+					"tokenModifiers": ["declaration"]
+				},
+				"full": true
+			}
+		},
+		"serverInfo": {"name": "gqlscan-lsp"}
+	}`, c.request("initialize", map[string]interface{}{}))
+	c.notify("initialized", map[string]interface{}{})
+
+	require.Equal(t, PublishDiagnosticsParams{
+		URI: uriA, Diagnostics: []Diagnostic{},
+	}, c.open(uriA, docA))
+	require.Equal(t, PublishDiagnosticsParams{
+		URI: uriB, Diagnostics: []Diagnostic{},
+	}, c.open(uriB, docB))
+
+	require.JSONEq(t, `{"data": [
+		0, 0, 8, 0, 0,
+		0, 9, 1, 3, 1,
+		0, 2, 2, 0, 0,
+		0, 3, 1, 4, 0,
+		0, 2, 1, 1, 0,
+		0, 2, 3, 1, 0,
+		0, 3, 1, 3, 0,
+		0, 2, 1, 1, 0
+	]}`, c.request("textDocument/semanticTokens/full", doc(uriB)))
+
+	require.JSONEq(t, `[
+		{
+			"name": "Q", "detail": "query", "kind": 12,
+			"range": {"start":{"line":0,"character":0},"end":{"line":5,"character":1}},
+			"selectionRange": {"start":{"line":0,"character":6},"end":{"line":0,"character":7}}
+		},
+		{
+			"name": "F", "detail": "fragment on T", "kind": 5,
+			"range": {"start":{"line":6,"character":0},"end":{"line":11,"character":1}},
+			"selectionRange": {"start":{"line":6,"character":9},"end":{"line":6,"character":10}}
+		},
+		{
+			"name": "anonymous query", "detail": "query", "kind": 12,
+			"range": {"start":{"line":12,"character":0},"end":{"line":12,"character":5}},
+			"selectionRange": {"start":{"line":12,"character":0},"end":{"line":12,"character":0}}
+		}
+	]`, c.request("textDocument/documentSymbol", doc(uriA)))
+
+	require.JSONEq(t, `[
+		{"startLine": 1, "endLine": 3},
+		{"startLine": 0, "endLine": 5},
+		{"startLine": 7, "endLine": 9},
+		{"startLine": 6, "endLine": 11}
+	]`, c.request("textDocument/foldingRange", doc(uriA)))
+
+	// Spread of F in the same document
+	require.JSONEq(t, `{
+		"uri": "file:///a.graphql",
+		"range": {"start":{"line":6,"character":9},"end":{"line":6,"character":10}}
+	}`, c.request("textDocument/definition", pos(uriA, 2, 8)))
+
+	// Spread of G in another document
+	require.JSONEq(t, `{
+		"uri": "file:///b.graphql",
+		"range": {"start":{"line":0,"character":9},"end":{"line":0,"character":10}}
+	}`, c.request("textDocument/definition", pos(uriA, 10, 6)))
+
+	// Not a spread
+	require.Equal(t, "null", c.request("textDocument/definition", pos(uriA, 1, 2)))
+
+	require.JSONEq(t, `[
+		{
+			"uri": "file:///a.graphql",
+			"range": {"start":{"line":6,"character":9},"end":{"line":6,"character":10}}
+		},
+		{
+			"uri": "file:///a.graphql",
+			"range": {"start":{"line":2,"character":7},"end":{"line":2,"character":8}}
+		},
+		{
+			"uri": "file:///b.graphql",
+			"range": {"start":{"line":0,"character":21},"end":{"line":0,"character":22}}
+		}
+	]`, c.request("textDocument/references", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uriA},
+		"position":     Position{Line: 6, Character: 10},
+		"context":      map[string]interface{}{"includeDeclaration": true},
+	}))
+
+	require.JSONEq(t, `[
+		{
+			"uri": "file:///b.graphql",
+			"range": {"start":{"line":0,"character":21},"end":{"line":0,"character":22}}
+		},
+		{
+			"uri": "file:///a.graphql",
+			"range": {"start":{"line":2,"character":7},"end":{"line":2,"character":8}}
+		}
+	]`, c.request("textDocument/references", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uriB},
+		"position":     Position{Line: 0, Character: 21},
+		"context":      map[string]interface{}{"includeDeclaration": false},
+	}))
+
+	c.notify("textDocument/didClose", doc(uriB))
+	require.Equal(t, PublishDiagnosticsParams{
+		URI: uriB, Diagnostics: []Diagnostic{},
+	}, c.diagnostics())
+	require.Equal(t, "null", c.request("textDocument/definition", pos(uriA, 10, 6)))
+
+	require.NoError(t, c.exit())
+}
+
+func TestServerDiagnostics(t *testing.T) {
+	for _, td := range []struct {
+		decl   string
+		input  string
+		expect Diagnostic
+	}{
+		{
+			decl(1), "{\n  a(x: 1 ]\n}",
+			Diagnostic{
+				Range: Range{
+					Start: Position{Line: 1, Character: 9},
+					End:   Position{Line: 1, Character: 10},
+				},
+				Severity: 1,
+				Source:   "gqlscan",
+				Message: "error at index 11 (']'): unexpected token; " +
+					"expected argument name",
+			},
+		},
+		{
+			// Characters are counted in UTF-16 code units
+			decl(1), "# 😀\r\n{a(x: \"😀\") ?}",
+			Diagnostic{
+				Range: Range{
+					Start: Position{Line: 1, Character: 12},
+					End:   Position{Line: 1, Character: 13},
+				},
+				Severity: 1,
+				Source:   "gqlscan",
+				Message: "error at index 22 ('?'): unexpected token; " +
+					"expected field name or alias",
+			},
+		},
+		{
+			decl(1), "{\n  a",
+			Diagnostic{
+				Range: Range{
+					Start: Position{Line: 1, Character: 3},
+					End:   Position{Line: 1, Character: 3},
+				},
+				Severity: 1,
+				Source:   "gqlscan",
+				Message: "error at index 5: unexpected end of file; " +
+					"expected field name or alias",
+			},
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			c := newClient(t)
+			c.request("initialize", map[string]interface{}{})
+			require.Equal(t, PublishDiagnosticsParams{
+				URI: uriA, Diagnostics: []Diagnostic{td.expect},
+			}, c.open(uriA, td.input))
+			require.NoError(t, c.exit())
+		})
+	}
+}
+
+func TestServerChange(t *testing.T) {
+	c := newClient(t)
+	c.request("initialize", map[string]interface{}{})
+	require.Len(t, c.open(uriA, "{").Diagnostics, 1)
+	c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uriA, "version": 2},
+		"contentChanges": []map[string]interface{}{
+			{"text": "{a"}, {"text": "{a}"},
+		},
+	})
+	require.Empty(t, c.diagnostics().Diagnostics)
+	require.Equal(t, "[]",
+		c.request("textDocument/foldingRange", doc(uriA)))
+	require.NoError(t, c.exit())
+}
+
+func TestServerErr(t *testing.T) {
+	c := newClient(t)
+	require.Equal(t, responseError{
+		Code: codeMethodNotFound, Message: `method "foo" not found`,
+	}, c.requestErr("foo", nil))
+	require.Equal(t, responseError{
+		Code: codeInvalidParams, Message: `document "file:///x" isn't open`,
+	}, c.requestErr("textDocument/documentSymbol", doc("file:///x")))
+	require.Equal(t, codeInvalidParams,
+		c.requestErr("textDocument/definition", "x").Code)
+
+	// Unknown notifications are ignored
+	c.notify("$/cancelRequest", map[string]interface{}{"id": 1})
+
+	c.request("shutdown", nil)
+	require.Equal(t, responseError{
+		Code: codeInvalidRequest, Message: "server is shut down",
+	}, c.requestErr("initialize", nil))
+	c.notify("exit", nil)
+	require.NoError(t, <-c.done)
+}
+
+func TestServerExitWithoutShutdown(t *testing.T) {
+	c := newClient(t)
+	c.notify("exit", nil)
+	require.ErrorIs(t, <-c.done, errExitWithoutShutdown)
+}
+
+func decl(skipFrames int) string {
+	_, filename, line, _ := runtime.Caller(skipFrames)
+	return fmt.Sprintf("%s:%d", filepath.Base(filename), line)
+}
@@ -0,0 +1,163 @@
+package gqlscan
+
+import (
+	"bytes"
+
+	"github.com/graph-guard/gqlscan/internal/names"
+)
+
+// CompletionContext describes what is expected at a cursor position.
+type CompletionContext struct {
+	// Expect is what is expected at the cursor, or at the beginning
+	// of Prefix if the cursor is at the end of or inside a name.
+	// Expect is ExpectArgName instead of ExpectAfterValueInner after
+	// an argument value, where the next argument name is expected.
+	Expect Expect
+
+	// Prefix is the part of the name before the cursor.
+	// Empty if the cursor isn't at the end of or inside a name.
+	Prefix []byte
+
+	// InComment is true if the cursor is inside a comment.
+	InComment bool
+
+	// LevelSelect is the number of selection sets enclosing the cursor,
+	// which is the level a field at the cursor would be at.
+	LevelSelect int
+
+	// Field is the name of the field whose argument list
+	// or selection set encloses the cursor.
+	// Nil if there is none.
+	Field []byte
+
+	// Arg is the name of the argument whose value encloses the cursor.
+	// Nil if there is none, which includes the cursor following
+	// a complete argument value.
+	Arg []byte
+
+	// Dir is the name of the directive whose argument list encloses
+	// the cursor. Nil if there is none.
+	Dir []byte
+}
+
+// CompletionContextAt returns what is expected at offset in src.
+// The part of src following offset is ignored and thus may be incomplete
+// or invalid. offset is clamped to the range [0, len(src)].
+//
+// All byte slices in the returned context refer to the same underlying
+// memory as src.
+//
+// Returns an Error if src contains a syntax error before offset.
+func CompletionContextAt(src []byte, offset int) (CompletionContext, Error) {
+	if offset < 0 {
+		offset = 0
+	} else if offset > len(src) {
+		offset = len(src)
+	}
+	start := offset
+	for start > 0 && names.IsNameByte(src[start-1]) {
+		start--
+	}
+
+	var (
+		c CompletionContext
+
+		// sets are the names of the fields owning the open selection sets.
+		sets [][]byte
+
+		// owner is the owner of the next selection set.
+		owner []byte
+
+		inArgs bool
+
+		// values is the number of open lists and objects.
+		values int
+
+		// strEnd is the end index of the last string.
+		strEnd int
+	)
+	err := ScanAll(src[:start], func(i *Iterator) {
+		t := i.Token()
+		switch t {
+		case TokenDefQry, TokenDefMut, TokenDefSub, TokenDefFrag:
+			owner = nil
+		case TokenField:
+			owner, c.Field = i.Value(), i.Value()
+		case TokenFragInline:
+			// Inline fragments inherit the owner of their parent set
+			if owner = nil; len(sets) > 0 {
+				owner = sets[len(sets)-1]
+			}
+		case TokenSet:
+			sets = append(sets, owner)
+		case TokenSetEnd:
+			sets = sets[:len(sets)-1]
+		case TokenDirName:
+			c.Dir = i.Value()
+		case TokenArgList:
+			inArgs = true
+		case TokenArgName:
+			c.Arg = i.Value()
+		case TokenArgListEnd:
+			inArgs, c.Arg, c.Dir = false, nil, nil
+		case TokenArr, TokenObj:
+			values++
+		case TokenArrEnd, TokenObjEnd:
+			values--
+		case TokenStr:
+			strEnd = i.IndexHead() + len(`"`)
+		case TokenStrBlock:
+			strEnd = i.IndexHead() + len(`"""`)
+		}
+		if !inArgs && t != TokenDirName && t != TokenArgList {
+			c.Dir = nil
+		}
+	})
+	switch {
+	case !err.IsErr():
+		c.Expect = ExpectDef
+	case err.Code == ErrUnexpEOF && err.Index == start:
+		c.Expect = err.Expectation
+	default:
+		return CompletionContext{}, err
+	}
+
+	if c.Expect == ExpectFieldNameOrAlias {
+		// The field name or alias was read but the field isn't
+		// reported until what follows it is.
+		c.Expect = ExpectAfterFieldName
+	}
+	if inArgs && values == 0 && c.Expect == ExpectAfterValueInner {
+		// The argument value is complete,
+		// a name at the cursor is the name of the next argument.
+		c.Expect, c.Arg = ExpectArgName, nil
+	}
+	if !inArgs {
+		c.Dir = nil
+	}
+	if !inArgs || c.Dir != nil {
+		c.Field = nil
+		if len(sets) > 0 {
+			c.Field = sets[len(sets)-1]
+		}
+	}
+	c.LevelSelect = len(sets)
+
+	if c.Expect == ExpectEndOfString || c.Expect == ExpectEndOfBlockString {
+		return c, Error{}
+	}
+	// Comments don't produce tokens, look for one on the line
+	// of the cursor after the last string.
+	lineStart := bytes.LastIndexAny(src[:start], "\n\r") + 1
+	if lineStart < strEnd {
+		lineStart = strEnd
+	}
+	if bytes.IndexByte(src[lineStart:start], '#') > -1 {
+		c.InComment = true
+		return c, Error{}
+	}
+	if start < offset {
+		c.Prefix = src[start:offset]
+	}
+	return c, Error{}
+}
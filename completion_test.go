@@ -0,0 +1,191 @@
+package gqlscan_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graph-guard/gqlscan"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletionContextAt(t *testing.T) {
+	for _, td := range []struct {
+		decl string
+		// input is the document with the cursor marked by "|".
+		input  string
+		expect gqlscan.CompletionContext
+	}{
+		{decl(1), "|", gqlscan.CompletionContext{
+			Expect: gqlscan.ExpectDef,
+		}},
+		{decl(1), "que|", gqlscan.CompletionContext{
+			Expect: gqlscan.ExpectDef, Prefix: []byte("que"),
+		}},
+		{decl(1), "{a} |", gqlscan.CompletionContext{
+			Expect: gqlscan.ExpectDef,
+		}},
+		{decl(1), "{|}", gqlscan.CompletionContext{
+			Expect: gqlscan.ExpectSel, LevelSelect: 1,
+		}},
+		{decl(1), "{a {b| c}}", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectSel,
+			Prefix:      []byte("b"),
+			LevelSelect: 2,
+			Field:       []byte("a"),
+		}},
+		{decl(1), "{a {b |", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectAfterFieldName,
+			LevelSelect: 2,
+			Field:       []byte("a"),
+		}},
+		{decl(1), "{a {b} |", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectAfterSelection,
+			LevelSelect: 1,
+		}},
+		{decl(1), "{a {... on T {|}}}", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectSel,
+			LevelSelect: 3,
+			Field:       []byte("a"),
+		}},
+		{decl(1), "{a {b(|: 1)}}", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectArgName,
+			LevelSelect: 2,
+			Field:       []byte("b"),
+		}},
+		{decl(1), "{a {b(x: 1, y|: 1)}}", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectArgName,
+			Prefix:      []byte("y"),
+			LevelSelect: 2,
+			Field:       []byte("b"),
+		}},
+		{decl(1), "{a {b(x: [1] |", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectArgName,
+			LevelSelect: 2,
+			Field:       []byte("b"),
+		}},
+		{decl(1), "{a {b(x: {o: 1 |", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectAfterValueInner,
+			LevelSelect: 2,
+			Field:       []byte("b"),
+			Arg:         []byte("x"),
+		}},
+		{decl(1), "{a {b @d(x: 1, y|", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectArgName,
+			Prefix:      []byte("y"),
+			LevelSelect: 2,
+			Field:       []byte("a"),
+			Dir:         []byte("d"),
+		}},
+		{decl(1), "{a {b(x: {o: [E|", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectVal,
+			Prefix:      []byte("E"),
+			LevelSelect: 2,
+			Field:       []byte("b"),
+			Arg:         []byte("x"),
+		}},
+		{decl(1), "{a {b(x: $v|", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectVarRefName,
+			Prefix:      []byte("v"),
+			LevelSelect: 2,
+			Field:       []byte("b"),
+			Arg:         []byte("x"),
+		}},
+		{decl(1), "{a {b(x: 1) @|", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectDir,
+			LevelSelect: 2,
+			Field:       []byte("a"),
+		}},
+		{decl(1), "{a {b(x: 1) @inc|", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectDir,
+			Prefix:      []byte("inc"),
+			LevelSelect: 2,
+			Field:       []byte("a"),
+		}},
+		{decl(1), "{a {b @include(if: |", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectVal,
+			LevelSelect: 2,
+			Field:       []byte("a"),
+			Arg:         []byte("if"),
+			Dir:         []byte("include"),
+		}},
+		{decl(1), "{a {b @d c|", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectAfterFieldName,
+			Prefix:      []byte("c"),
+			LevelSelect: 2,
+			Field:       []byte("a"),
+		}},
+		{decl(1), "query Q($v: |", gqlscan.CompletionContext{
+			Expect: gqlscan.ExpectVarType,
+		}},
+		{decl(1), "query Q($v: [Str|]) {x}", gqlscan.CompletionContext{
+			Expect: gqlscan.ExpectVarType,
+			Prefix: []byte("Str"),
+		}},
+		{decl(1), "fragment F on |", gqlscan.CompletionContext{
+			Expect: gqlscan.ExpectFragTypeCond,
+		}},
+		{decl(1), "{a {b(x: \"a b|", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectEndOfString,
+			LevelSelect: 2,
+			Field:       []byte("b"),
+			Arg:         []byte("x"),
+		}},
+		{decl(1), "{a {b(x: \"\"\"\n# b|", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectEndOfBlockString,
+			LevelSelect: 2,
+			Field:       []byte("b"),
+			Arg:         []byte("x"),
+		}},
+		{decl(1), "{a {b # c|", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectAfterFieldName,
+			InComment:   true,
+			LevelSelect: 2,
+			Field:       []byte("a"),
+		}},
+		{decl(1), "{a(x: \"#\", y: \"\"\"#\"\"\" |", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectArgName,
+			LevelSelect: 1,
+			Field:       []byte("a"),
+		}},
+		{decl(1), "{a @d b(x: |", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectVal,
+			LevelSelect: 1,
+			Field:       []byte("b"),
+			Arg:         []byte("x"),
+		}},
+		{decl(1), "{a # c\n b|", gqlscan.CompletionContext{
+			Expect:      gqlscan.ExpectAfterFieldName,
+			Prefix:      []byte("b"),
+			LevelSelect: 1,
+		}},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			offset := strings.Index(td.input, "|")
+			src := td.input[:offset] + td.input[offset+1:]
+			c, err := gqlscan.CompletionContextAt([]byte(src), offset)
+			require.False(t, err.IsErr(), err.Error())
+			require.Equal(t, td.expect, c)
+		})
+	}
+}
+
+func TestCompletionContextAtOffsetClamped(t *testing.T) {
+	src := []byte("{a}")
+	for _, offset := range []int{-1, len(src) + 1} {
+		c, err := gqlscan.CompletionContextAt(src, offset)
+		require.False(t, err.IsErr(), err.Error())
+		expect, _ := gqlscan.CompletionContextAt(src, 0)
+		if offset > 0 {
+			expect, _ = gqlscan.CompletionContextAt(src, len(src))
+		}
+		require.Equal(t, expect, c)
+	}
+}
+
+func TestCompletionContextAtErr(t *testing.T) {
+	c, err := gqlscan.CompletionContextAt([]byte("{a(x: ]) b}"), 9)
+	require.Equal(t, gqlscan.ErrUnexpToken, err.Code)
+	require.Equal(t, 6, err.Index)
+	require.Zero(t, c)
+}
@@ -0,0 +1,353 @@
+package complexity
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/internal/ast"
+)
+
+// ErrFragCycle is returned when fragment spreads form a cycle.
+var ErrFragCycle = errors.New("fragment cycle")
+
+// DefaultListArgs are the default names of the list size arguments.
+var DefaultListArgs = []string{"first", "last", "limit"}
+
+// Config configures the cost analysis.
+type Config struct {
+	// Weights maps either field names ("name")
+	// or schema coordinates ("Type.name") to field weights.
+	// Schema coordinates take precedence over field names
+	// and only apply if the parent type of the field is known.
+	// Weights must not be negative.
+	Weights map[string]int
+
+	// DefaultWeight is the weight of fields that have no assigned weight.
+	// Fields weigh 1 if DefaultWeight is zero.
+	// DefaultWeight must not be negative.
+	DefaultWeight int
+
+	// ListArgs are the names of the arguments that define the size of
+	// a list field. DefaultListArgs are used if ListArgs is nil.
+	ListArgs []string
+
+	// DefaultListSize is the list size assumed for list size arguments
+	// whose value is neither a literal nor a known variable value.
+	// Lists are assumed to be of size 1 if DefaultListSize is zero.
+	DefaultListSize int
+
+	// RootTypes maps TokenDefQry, TokenDefMut and TokenDefSub to
+	// the names of the root operation types.
+	// "Query", "Mutation" and "Subscription" are used by default.
+	RootTypes map[gqlscan.Token]string
+
+	// FieldType returns the named type of the field of parentType
+	// or nil if it's unknown. The parent type of a field is unknown
+	// if FieldType is nil unless it's defined by a type condition.
+	FieldType func(parentType, field []byte) []byte
+}
+
+// Result is the result of a cost analysis.
+type Result struct {
+	// Total is the total cost of the operation.
+	Total int
+
+	// Path is the path of response names to the most expensive leaf.
+	Path []string
+}
+
+// Cost computes the cost of the operation called oprName in src,
+// or the only operation if oprName is empty.
+// vars is the JSON object of variable values and may be nil.
+//
+// The cost of a field is its weight plus the cost of its selections,
+// multiplied by the largest value of its list size arguments.
+// The cost of fragments and inline fragments is the cost of their
+// selections, all type conditions are assumed to apply.
+//
+// Returns an error if conf has negative weights,
+// a gqlscan.Error if src isn't lexically valid,
+// gqlscan.ErrOprNotFound, gqlscan.ErrOprAmbiguous,
+// gqlscan.ErrFragUndefined or gqlscan.ErrFragAmbiguous if the operation
+// can't be selected and ErrFragCycle if named spreads form a cycle.
+func Cost(src, oprName, vars []byte, conf Config) (Result, error) {
+	if err := conf.check(); err != nil {
+		return Result{}, err
+	}
+	d, err := ast.Parse(src)
+	if err != nil {
+		return Result{}, err
+	}
+	opr, err := d.Opr(oprName)
+	if err != nil {
+		return Result{}, err
+	}
+
+	c := &coster{
+		doc:   d,
+		conf:  conf,
+		vars:  map[string]int{},
+		frags: map[*ast.Definition]cost{},
+	}
+	if c.conf.ListArgs == nil {
+		c.conf.ListArgs = DefaultListArgs
+	}
+	if err := c.initVars(opr, vars); err != nil {
+		return Result{}, err
+	}
+
+	rootType := []byte(rootTypeName(opr.Token))
+	if t, ok := conf.RootTypes[opr.Token]; ok {
+		rootType = []byte(t)
+	}
+	r, err := c.sels(opr.Sels, rootType, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	res := Result{Total: r.total}
+	for p := r.path; p != nil; p = p.next {
+		res.Path = append(res.Path, string(p.name))
+	}
+	return res, nil
+}
+
+// check returns an error if c has negative weights,
+// which the saturating arithmetic doesn't support.
+func (c Config) check() error {
+	if c.DefaultWeight < 0 {
+		return fmt.Errorf("negative default weight: %d", c.DefaultWeight)
+	}
+	for k, w := range c.Weights {
+		if w < 0 {
+			return fmt.Errorf("negative weight of %q: %d", k, w)
+		}
+	}
+	return nil
+}
+
+// cost is the cost of a selection set.
+type cost struct {
+	total int
+	path  *pathNode
+}
+
+// pathNode is a node of the path to the most expensive leaf.
+type pathNode struct {
+	name []byte
+	next *pathNode
+}
+
+type coster struct {
+	doc  *ast.Document
+	conf Config
+
+	// vars maps variable names to integer values.
+	vars map[string]int
+
+	// frags memoizes the costs of fragment definitions.
+	frags map[*ast.Definition]cost
+}
+
+// initVars records the integer variable values and defaults.
+func (c *coster) initVars(opr *ast.Definition, vars []byte) error {
+	for _, v := range opr.Vars {
+		if v.Def == nil || v.Def.Token != gqlscan.TokenInt {
+			continue
+		}
+		if n, err := strconv.Atoi(string(v.Def.Raw)); err == nil {
+			c.vars[string(v.Name.Val)] = n
+		}
+	}
+	if len(vars) < 1 {
+		return nil
+	}
+	var values map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(vars))
+	dec.UseNumber()
+	if err := dec.Decode(&values); err != nil {
+		return fmt.Errorf("decoding variables: %w", err)
+	}
+	for k, v := range values {
+		if n, ok := v.(json.Number); ok {
+			if i, err := strconv.Atoi(n.String()); err == nil {
+				c.vars[k] = i
+			}
+		}
+	}
+	return nil
+}
+
+func (c *coster) sels(
+	sels []*ast.Selection, parentType []byte, stack []*ast.Definition,
+) (r cost, err error) {
+	max := -1
+	for _, s := range sels {
+		var sc cost
+		switch s.Token {
+		case gqlscan.TokenField:
+			sc, err = c.field(s, parentType, stack)
+		case gqlscan.TokenFragInline:
+			t := parentType
+			if s.Name.Val != nil {
+				t = s.Name.Val
+			}
+			sc, err = c.sels(s.Sels, t, stack)
+		case gqlscan.TokenNamedSpread:
+			sc, err = c.frag(s.Name.Val, stack)
+		}
+		if err != nil {
+			return cost{}, err
+		}
+		r.total = add(r.total, sc.total)
+		if sc.total > max {
+			max, r.path = sc.total, sc.path
+		}
+	}
+	return r, nil
+}
+
+func (c *coster) field(
+	s *ast.Selection, parentType []byte, stack []*ast.Definition,
+) (cost, error) {
+	var fieldType []byte
+	if c.conf.FieldType != nil && parentType != nil {
+		fieldType = c.conf.FieldType(parentType, s.Name.Val)
+	}
+	r, err := c.sels(s.Sels, fieldType, stack)
+	if err != nil {
+		return cost{}, err
+	}
+	r.total = mul(add(c.weight(parentType, s.Name.Val), r.total), c.listSize(s))
+	r.path = &pathNode{name: s.ResponseName(), next: r.path}
+	return r, nil
+}
+
+func (c *coster) frag(name []byte, stack []*ast.Definition) (cost, error) {
+	f, err := c.doc.Frag(name)
+	if err != nil {
+		return cost{}, err
+	}
+	if r, ok := c.frags[f]; ok {
+		return r, nil
+	}
+	for _, sf := range stack {
+		if sf == f {
+			return cost{}, errCycle(stack, name)
+		}
+	}
+	r, err := c.sels(f.Sels, f.TypeCond.Val, append(stack, f))
+	if err != nil {
+		return cost{}, err
+	}
+	c.frags[f] = r
+	return r, nil
+}
+
+func (c *coster) weight(parentType, field []byte) int {
+	if parentType != nil {
+		coord := string(parentType) + "." + string(field)
+		if w, ok := c.conf.Weights[coord]; ok {
+			return w
+		}
+	}
+	if w, ok := c.conf.Weights[string(field)]; ok {
+		return w
+	}
+	if c.conf.DefaultWeight != 0 {
+		return c.conf.DefaultWeight
+	}
+	return 1
+}
+
+// listSize returns the largest value of the scalar
+// list size arguments of s.
+func (c *coster) listSize(s *ast.Selection) int {
+	size := 1
+	for _, a := range s.Args {
+		if !c.isListArg(a.Name.Val) {
+			continue
+		}
+		n, ok := 0, false
+		switch a.Val.Token {
+		case gqlscan.TokenArr, gqlscan.TokenObj:
+			continue
+		case gqlscan.TokenInt:
+			var err error
+			n, err = strconv.Atoi(string(a.Val.Raw))
+			ok = err == nil
+		case gqlscan.TokenVarRef:
+			n, ok = c.vars[string(a.Val.Raw)]
+		}
+		if !ok {
+			n = c.conf.DefaultListSize
+			if n == 0 {
+				n = 1
+			}
+		}
+		if n > size {
+			size = n
+		}
+	}
+	return size
+}
+
+func (c *coster) isListArg(name []byte) bool {
+	for _, a := range c.conf.ListArgs {
+		if a == string(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func rootTypeName(t gqlscan.Token) string {
+	switch t {
+	case gqlscan.TokenDefMut:
+		return "Mutation"
+	case gqlscan.TokenDefSub:
+		return "Subscription"
+	}
+	return "Query"
+}
+
+// add returns a+b saturating at math.MaxInt.
+// a and b must not be negative.
+func add(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+// mul returns a*b saturating at math.MaxInt.
+// a and b must not be negative.
+func mul(a, b int) int {
+	if a != 0 && b > math.MaxInt/a {
+		return math.MaxInt
+	}
+	return a * b
+}
+
+// errCycle returns an ErrFragCycle error for the fragments in stack
+// starting with the fragment called name.
+func errCycle(stack []*ast.Definition, name []byte) error {
+	var b strings.Builder
+	for n := range stack {
+		if string(stack[n].Name.Val) != string(name) {
+			continue
+		}
+		for _, f := range stack[n:] {
+			b.Write(f.Name.Val)
+			b.WriteString(" -> ")
+		}
+		break
+	}
+	b.Write(name)
+	return fmt.Errorf("%w: %s", ErrFragCycle, b.String())
+}
@@ -0,0 +1,213 @@
+package complexity_test
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/complexity"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCost(t *testing.T) {
+	for _, td := range []struct {
+		decl    string
+		input   string
+		oprName string
+		vars    string
+		conf    complexity.Config
+		expect  complexity.Result
+	}{
+		{
+			decl(1),
+			`{a}`, "", "",
+			complexity.Config{},
+			complexity.Result{Total: 1, Path: []string{"a"}},
+		},
+		{
+			decl(1),
+			`{a b {c d}}`, "", "",
+			complexity.Config{},
+			complexity.Result{Total: 4, Path: []string{"b", "c"}},
+		},
+		{
+			decl(1),
+			`{users(first: 1000) {friends(first: 1000) {name}}}`, "", "",
+			complexity.Config{},
+			complexity.Result{
+				Total: 1000 * (1 + 1000*(1+1)),
+				Path:  []string{"users", "friends", "name"},
+			},
+		},
+		{
+			// The largest list size argument wins
+			decl(1),
+			`{a(first: 2, last: 5, x: 100) {b}}`, "", "",
+			complexity.Config{},
+			complexity.Result{Total: 10, Path: []string{"a", "b"}},
+		},
+		{
+			decl(1),
+			`query Q($n: Int, $m: Int = 3, $k: Int) {
+				a(first: $n) {x} b(limit: $m) {x} c(last: $k) {x}
+			}`, "", `{"n": 10}`,
+			complexity.Config{DefaultListSize: 7},
+			complexity.Result{Total: 20 + 6 + 14, Path: []string{"a", "x"}},
+		},
+		{
+			decl(1),
+			`{a(first: [5], last: {n: 5}) {b}}`, "", "",
+			complexity.Config{DefaultListSize: 7},
+			complexity.Result{Total: 2, Path: []string{"a", "b"}},
+		},
+		{
+			decl(1),
+			`{a(count: 4) {b} c(first: 2) @d(first: 100) {d}}`, "", "",
+			complexity.Config{ListArgs: []string{"count"}},
+			complexity.Result{Total: 8 + 2, Path: []string{"a", "b"}},
+		},
+		{
+			decl(1),
+			`{x: a y: b(first: 2) {z: c}}`, "", "",
+			complexity.Config{Weights: map[string]int{"c": 10}},
+			complexity.Result{Total: 1 + 22, Path: []string{"y", "z"}},
+		},
+		{
+			decl(1),
+			`query A {a} mutation M {a {b}}`, "M", "",
+			complexity.Config{
+				Weights: map[string]int{"Mutation.a": 5, "a": 2, "B.b": 3},
+				FieldType: func(parentType, field []byte) []byte {
+					if string(parentType) == "Mutation" {
+						return []byte("B")
+					}
+					return nil
+				},
+			},
+			complexity.Result{Total: 8, Path: []string{"a", "b"}},
+		},
+		{
+			decl(1),
+			`{a ...on T {b} ...on U {c}} fragment F on T {b}`, "", "",
+			complexity.Config{
+				Weights:       map[string]int{"U.c": 5, "b": 2},
+				DefaultWeight: 3,
+			},
+			complexity.Result{Total: 3 + 2 + 5, Path: []string{"c"}},
+		},
+		{
+			decl(1),
+			`{a(first: 3) {...F} b {...F}}
+			fragment F on T {c(first: 2) {...G}}
+			fragment G on T {d e}`, "", "",
+			complexity.Config{},
+			complexity.Result{
+				Total: 3*(1+2*(1+2)) + (1 + 2*(1+2)),
+				Path:  []string{"a", "c", "d"},
+			},
+		},
+		{
+			decl(1),
+			`{a(first: 9223372036854775807) {b(first: 1000000) {c}}}`, "", "",
+			complexity.Config{DefaultListSize: math.MaxInt},
+			complexity.Result{Total: math.MaxInt, Path: []string{"a", "b", "c"}},
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			var vars []byte
+			if td.vars != "" {
+				vars = []byte(td.vars)
+			}
+			r, err := complexity.Cost(
+				[]byte(td.input), []byte(td.oprName), vars, td.conf,
+			)
+			require.NoError(t, err)
+			require.Equal(t, td.expect, r)
+		})
+	}
+}
+
+func TestCostErr(t *testing.T) {
+	for _, td := range []struct {
+		decl    string
+		input   string
+		oprName string
+		expect  error
+		msg     string
+	}{
+		{
+			decl(1),
+			`{...F} fragment F on T {a {...G}} fragment G on T {...F}`, "",
+			complexity.ErrFragCycle, "fragment cycle: F -> G -> F",
+		},
+		{
+			decl(1),
+			`{...F}`, "",
+			gqlscan.ErrFragUndefined, `undefined fragment: "F"`,
+		},
+		{
+			decl(1),
+			`{...F} fragment F on T {a} fragment F on T {b}`, "",
+			gqlscan.ErrFragAmbiguous, `ambiguous fragment: "F"`,
+		},
+		{
+			decl(1),
+			`query A {a}`, "B",
+			gqlscan.ErrOprNotFound, `operation not found: "B"`,
+		},
+		{
+			decl(1),
+			`query A {a} mutation B {b}`, "",
+			gqlscan.ErrOprAmbiguous, `ambiguous operation`,
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			_, err := complexity.Cost(
+				[]byte(td.input), []byte(td.oprName), nil, complexity.Config{},
+			)
+			require.ErrorIs(t, err, td.expect)
+			require.Equal(t, td.msg, err.Error())
+		})
+	}
+
+	t.Run("negative weight", func(t *testing.T) {
+		_, err := complexity.Cost(
+			[]byte(`{a b}`), nil, nil, complexity.Config{
+				Weights: map[string]int{"a": 1, "b": -1},
+			},
+		)
+		require.EqualError(t, err, `negative weight of "b": -1`)
+	})
+
+	t.Run("negative default weight", func(t *testing.T) {
+		_, err := complexity.Cost(
+			[]byte(`{a}`), nil, nil, complexity.Config{DefaultWeight: -1},
+		)
+		require.EqualError(t, err, `negative default weight: -1`)
+	})
+
+	t.Run("invalid variables", func(t *testing.T) {
+		_, err := complexity.Cost(
+			[]byte(`{a}`), nil, []byte(`{`), complexity.Config{},
+		)
+		require.Error(t, err)
+	})
+
+	t.Run("scan error", func(t *testing.T) {
+		_, err := complexity.Cost(
+			[]byte(`{a(first: 1`), nil, nil, complexity.Config{},
+		)
+		var e gqlscan.Error
+		require.ErrorAs(t, err, &e)
+		require.Equal(t, gqlscan.ErrUnexpEOF, e.Code)
+	})
+}
+
+func decl(skipFrames int) string {
+	_, filename, line, _ := runtime.Caller(skipFrames)
+	return fmt.Sprintf("%s:%d", filepath.Base(filename), line)
+}
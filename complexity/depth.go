@@ -0,0 +1,122 @@
+package complexity
+
+import (
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/internal/ast"
+)
+
+// OprDepth is the effective depth of an operation.
+type OprDepth struct {
+	// Token is either TokenDefQry, TokenDefMut or TokenDefSub.
+	Token gqlscan.Token
+
+	// Name is the operation name, nil for anonymous operations.
+	Name []byte
+
+	// Depth is the maximum selection level of a field.
+	Depth int
+
+	// Path is the path of response names to the deepest field.
+	Path []string
+}
+
+// Depth computes the effective depth of every operation in src
+// in order of definition.
+//
+// Unlike Iterator.LevelSelect, Depth resolves named spreads through
+// their fragment definitions. The depth of an operation is the maximum
+// LevelSelect of its fields after all named spreads are replaced with
+// equivalent inline fragments (see transform.InlineFragments),
+// such that the depth of { a { ...F } } fragment F on T { b }
+// is 3, just like the depth of { a { ...on T { b } } }.
+//
+// Returns a gqlscan.Error if src isn't lexically valid and
+// ErrFragCycle, gqlscan.ErrFragUndefined or
+// gqlscan.ErrFragAmbiguous if a named spread can't be resolved.
+func Depth(src []byte) ([]OprDepth, error) {
+	d, err := ast.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	m := &depthMeter{doc: d, frags: map[*ast.Definition]depth{}}
+	var oprs []OprDepth
+	for _, def := range d.Defs {
+		if def.Token == gqlscan.TokenDefFrag {
+			continue
+		}
+		r, err := m.sels(def.Sels, nil)
+		if err != nil {
+			return nil, err
+		}
+		o := OprDepth{Token: def.Token, Name: def.Name.Val, Depth: r.max}
+		for p := r.path; p != nil; p = p.next {
+			o.Path = append(o.Path, string(p.name))
+		}
+		oprs = append(oprs, o)
+	}
+	return oprs, nil
+}
+
+// depth is the depth of a selection set.
+type depth struct {
+	max  int
+	path *pathNode
+}
+
+type depthMeter struct {
+	doc *ast.Document
+
+	// frags memoizes the depths of fragment definitions.
+	frags map[*ast.Definition]depth
+}
+
+func (m *depthMeter) sels(
+	sels []*ast.Selection, stack []*ast.Definition,
+) (r depth, err error) {
+	for _, s := range sels {
+		var sd depth
+		switch s.Token {
+		case gqlscan.TokenField:
+			if sd, err = m.sels(s.Sels, stack); err != nil {
+				return depth{}, err
+			}
+			sd.max++
+			sd.path = &pathNode{name: s.ResponseName(), next: sd.path}
+		case gqlscan.TokenFragInline:
+			if sd, err = m.sels(s.Sels, stack); err != nil {
+				return depth{}, err
+			}
+			sd.max++
+		case gqlscan.TokenNamedSpread:
+			if sd, err = m.frag(s.Name.Val, stack); err != nil {
+				return depth{}, err
+			}
+			sd.max++
+		}
+		if sd.max > r.max {
+			r = sd
+		}
+	}
+	return r, nil
+}
+
+func (m *depthMeter) frag(name []byte, stack []*ast.Definition) (depth, error) {
+	f, err := m.doc.Frag(name)
+	if err != nil {
+		return depth{}, err
+	}
+	if r, ok := m.frags[f]; ok {
+		return r, nil
+	}
+	for _, sf := range stack {
+		if sf == f {
+			return depth{}, errCycle(stack, name)
+		}
+	}
+	r, err := m.sels(f.Sels, append(stack, f))
+	if err != nil {
+		return depth{}, err
+	}
+	m.frags[f] = r
+	return r, nil
+}
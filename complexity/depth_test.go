@@ -0,0 +1,133 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/complexity"
+	"github.com/graph-guard/gqlscan/transform"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepth(t *testing.T) {
+	for _, td := range []struct {
+		decl   string
+		input  string
+		expect []complexity.OprDepth
+	}{
+		{
+			decl(1),
+			`{a}`,
+			[]complexity.OprDepth{
+				{Token: gqlscan.TokenDefQry, Depth: 1, Path: []string{"a"}},
+			},
+		},
+		{
+			decl(1),
+			`{ a { ...F } } fragment F on T { b { c { d } } }`,
+			[]complexity.OprDepth{{
+				Token: gqlscan.TokenDefQry,
+				Depth: 5,
+				Path:  []string{"a", "b", "c", "d"},
+			}},
+		},
+		{
+			decl(1),
+			`query Q { a { x: b { c } } d { ...on T { e { f { g } } } } }
+			fragment F on T { a { b { c { d { e } } } } }
+			mutation M($v: Int = 1) { ...F ...G }
+			fragment G on T { x { ...F } }`,
+			[]complexity.OprDepth{
+				{
+					Token: gqlscan.TokenDefQry,
+					Name:  []byte("Q"),
+					Depth: 5,
+					Path:  []string{"d", "e", "f", "g"},
+				},
+				{
+					Token: gqlscan.TokenDefMut,
+					Name:  []byte("M"),
+					Depth: 8,
+					Path:  []string{"x", "a", "b", "c", "d", "e"},
+				},
+			},
+		},
+		{
+			// Unused fragments are ignored
+			decl(1),
+			`subscription { a @d(x: { y: [1] }) }
+			fragment F on T { ...F }`,
+			[]complexity.OprDepth{
+				{Token: gqlscan.TokenDefSub, Depth: 1, Path: []string{"a"}},
+			},
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			r, err := complexity.Depth([]byte(td.input))
+			require.NoError(t, err)
+			require.Equal(t, td.expect, r)
+		})
+	}
+}
+
+func TestDepthLevelSelect(t *testing.T) {
+	// The depth is the maximum LevelSelect of all fields
+	// after inlining all fragments
+	for _, input := range []string{
+		`{a}`,
+		`{a {b {c}} d {e}}`,
+		`query($x: [Int] = [1]) {a(b: {c: [$x]}) {...on T {d {e}}} f}`,
+		`{a {...F} ...on T {b {...F}}} fragment F on T {c {d}}`,
+	} {
+		t.Run("", func(t *testing.T) {
+			r, err := complexity.Depth([]byte(input))
+			require.NoError(t, err)
+			require.Len(t, r, 1)
+
+			inlined, err := transform.InlineFragments(nil, []byte(input))
+			require.NoError(t, err)
+
+			var max int
+			serr := gqlscan.ScanAll(inlined, func(i *gqlscan.Iterator) {
+				if i.Token() == gqlscan.TokenField && i.LevelSelect() > max {
+					max = i.LevelSelect()
+				}
+			})
+			require.False(t, serr.IsErr(), serr.Error())
+			require.Equal(t, max, r[0].Depth)
+		})
+	}
+}
+
+func TestDepthErr(t *testing.T) {
+	for _, td := range []struct {
+		decl   string
+		input  string
+		expect error
+		msg    string
+	}{
+		{
+			decl(1),
+			`{ a { ...F } } fragment F on T { b { ...G } }
+			fragment G on T { c { ...F } }`,
+			complexity.ErrFragCycle, "fragment cycle: F -> G -> F",
+		},
+		{
+			decl(1),
+			`{ a } { ...F }`,
+			gqlscan.ErrFragUndefined, `undefined fragment: "F"`,
+		},
+		{
+			decl(1),
+			`{ ...F } fragment F on T { a } fragment F on T { b }`,
+			gqlscan.ErrFragAmbiguous, `ambiguous fragment: "F"`,
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			_, err := complexity.Depth([]byte(td.input))
+			require.ErrorIs(t, err, td.expect)
+			require.Equal(t, td.msg, err.Error())
+		})
+	}
+}
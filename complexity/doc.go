@@ -0,0 +1,6 @@
+// Package complexity provides static complexity analysis
+// of GraphQL operations built on top of the gqlscan token stream.
+//
+// The analysis is purely syntactic and doesn't require a schema.
+// Field types can optionally be provided to apply per-type weights.
+package complexity
@@ -0,0 +1,52 @@
+// Package gqlerr provides GraphQL errors in the shape
+// defined by the response section of the GraphQL specification.
+package gqlerr
+
+import "unicode/utf8"
+
+// Error is a GraphQL error.
+type Error struct {
+	Message    string                 `json:"message"`
+	Locations  []Location             `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Location is a location in a GraphQL document.
+// Lines and columns start at 1.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// LocationOf returns the location of the character at index in src.
+// Columns are counted in characters, not bytes.
+// "\n", "\r\n" and "\r" are all recognized as line terminators.
+func LocationOf(src []byte, index int) Location {
+	if index > len(src) {
+		index = len(src)
+	}
+	l := Location{Line: 1, Column: 1}
+	for i := 0; i < index; {
+		switch src[i] {
+		case '\r':
+			if i+1 < len(src) && src[i+1] == '\n' {
+				i++
+			}
+			fallthrough
+		case '\n':
+			l.Line++
+			l.Column = 1
+			i++
+			continue
+		}
+		_, w := utf8.DecodeRune(src[i:])
+		i += w
+		l.Column++
+	}
+	return l
+}
@@ -0,0 +1,71 @@
+package gqlerr_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/graph-guard/gqlscan/gqlerr"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocationOf(t *testing.T) {
+	for _, td := range []struct {
+		decl   string
+		src    string
+		index  int
+		expect gqlerr.Location
+	}{
+		{decl(1), ``, 0, gqlerr.Location{Line: 1, Column: 1}},
+		{decl(1), `{a}`, 1, gqlerr.Location{Line: 1, Column: 2}},
+		{decl(1), "{\n a}", 3, gqlerr.Location{Line: 2, Column: 2}},
+		{decl(1), "{\r\n a}", 4, gqlerr.Location{Line: 2, Column: 2}},
+		{decl(1), "{\r\r a}", 4, gqlerr.Location{Line: 3, Column: 2}},
+		{decl(1), "{ # ü\n a(s: \"ä\") }", 12, gqlerr.Location{Line: 2, Column: 6}},
+		{decl(1), `{a}`, 10, gqlerr.Location{Line: 1, Column: 4}},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			require.Equal(t, td.expect, gqlerr.LocationOf([]byte(td.src), td.index))
+		})
+	}
+}
+
+func TestErrorJSON(t *testing.T) {
+	for _, td := range []struct {
+		decl   string
+		err    *gqlerr.Error
+		expect string
+	}{
+		{
+			decl(1),
+			&gqlerr.Error{Message: "failed"},
+			`{"message":"failed"}`,
+		},
+		{
+			decl(1),
+			&gqlerr.Error{
+				Message:    "failed",
+				Locations:  []gqlerr.Location{{Line: 1, Column: 2}},
+				Path:       []interface{}{"a", 1},
+				Extensions: map[string]interface{}{"code": "X"},
+			},
+			`{"message":"failed","locations":[{"line":1,"column":2}],` +
+				`"path":["a",1],"extensions":{"code":"X"}}`,
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			b, err := json.Marshal(td.err)
+			require.NoError(t, err)
+			require.Equal(t, td.expect, string(b))
+			require.Equal(t, td.err.Message, td.err.Error())
+		})
+	}
+}
+
+func decl(skipFrames int) string {
+	_, filename, line, _ := runtime.Caller(skipFrames)
+	return fmt.Sprintf("%s:%d", filepath.Base(filename), line)
+}
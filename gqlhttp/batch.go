@@ -0,0 +1,192 @@
+package gqlhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/graph-guard/gqlscan/gqlerr"
+)
+
+type batchCtxKey struct{}
+
+// BatchFromContext returns the batch of requests parsed by
+// the middleware or nil if ctx doesn't carry one.
+func BatchFromContext(ctx context.Context) []*Request {
+	b, _ := ctx.Value(batchCtxKey{}).([]*Request)
+	return b
+}
+
+// serveBatch serves the batch in body.
+//
+// Batches that can't be parsed or exceed the batch limits are responded
+// with 400 and {"errors": [...]}. If any of the requests fails then the
+// whole batch is responded with an array of {"errors": [...]} in the
+// order of the requests, where the valid requests carry an error
+// with the code CodeBatchRejected.
+func (c Config) serveBatch(
+	next http.Handler, rs responder, r *http.Request, body []byte,
+) {
+	reqs, errs, e := c.parseBatch(body)
+	if e != nil {
+		rs.respond(http.StatusBadRequest, e)
+		return
+	}
+
+	tokens, costs := make([]int, len(reqs)), make([]int, len(reqs))
+	prepare := func(n int) {
+		if errs[n] != nil {
+			return
+		}
+		if tokens[n], errs[n] = c.prepare(reqs[n]); errs[n] != nil {
+			return
+		}
+		if c.MaxBatchCost > 0 {
+			var err error
+			if costs[n], err = c.Cost(reqs[n]); err != nil {
+				errs[n] = toGQLErr(err)
+			}
+		}
+	}
+	if c.Parallel {
+		var wg sync.WaitGroup
+		wg.Add(len(reqs))
+		for n := range reqs {
+			go func(n int) {
+				defer wg.Done()
+				prepare(n)
+			}(n)
+		}
+		wg.Wait()
+	} else {
+		for n := range reqs {
+			prepare(n)
+		}
+	}
+
+	for _, e := range errs {
+		if e != nil {
+			rs.failBatch(errs)
+			return
+		}
+	}
+	if e := c.checkBudget(tokens, costs); e != nil {
+		rs.respond(http.StatusBadRequest, e)
+		return
+	}
+	next.ServeHTTP(rs.w, r.WithContext(
+		context.WithValue(r.Context(), batchCtxKey{}, reqs),
+	))
+}
+
+// parseBatch extracts the requests from the JSON array in body.
+// Returns the errors of the individual requests in errs
+// and an error in e if the batch can't be parsed.
+func (c Config) parseBatch(
+	body []byte,
+) (reqs []*Request, errs []*gqlerr.Error, e *gqlerr.Error) {
+	if c.MaxBatchSize == 0 {
+		return nil, nil, errBadRequest("batching is disabled")
+	}
+	errMalformed := func(err error) *gqlerr.Error {
+		return errBadRequest(fmt.Sprintf("decoding request body: %s", err))
+	}
+
+	r := jsonReader{b: body}
+	r.skipSpace()
+	r.i++ // '['
+	r.skipSpace()
+	if r.is(']') {
+		return nil, nil, errBadRequest("empty batch")
+	}
+	var buf []byte
+	for {
+		r.skipSpace()
+		start := r.i
+		if err := r.skipValue(); err != nil {
+			return nil, nil, errMalformed(err)
+		}
+		if len(reqs) >= c.MaxBatchSize {
+			return nil, nil, &gqlerr.Error{
+				Message: fmt.Sprintf(
+					"batch exceeds the limit of %d requests", c.MaxBatchSize,
+				),
+				Extensions: map[string]interface{}{"code": CodeLimitExceeded},
+			}
+		}
+		var req *Request
+		req, buf, e = fromJSON(buf, body[start:r.i])
+		reqs, errs = append(reqs, req), append(errs, e)
+
+		r.skipSpace()
+		switch {
+		case r.is(','):
+			r.i++
+		case r.is(']'):
+			r.i++
+			if err := r.end(); err != nil {
+				return nil, nil, errMalformed(err)
+			}
+			return reqs, errs, nil
+		default:
+			return nil, nil, errMalformed(r.errSyntax())
+		}
+	}
+}
+
+// checkBudget enforces the batch budgets.
+func (c Config) checkBudget(tokens, costs []int) *gqlerr.Error {
+	var totalTokens, totalCost int
+	for n := range tokens {
+		totalTokens += tokens[n]
+		totalCost += costs[n]
+	}
+	var msg string
+	switch {
+	case c.MaxBatchTokens > 0 && totalTokens > c.MaxBatchTokens:
+		msg = fmt.Sprintf(
+			"batch exceeds the limit of %d tokens", c.MaxBatchTokens,
+		)
+	case c.MaxBatchCost > 0 && totalCost > c.MaxBatchCost:
+		msg = fmt.Sprintf(
+			"batch exceeds the maximum cost of %d", c.MaxBatchCost,
+		)
+	default:
+		return nil
+	}
+	return &gqlerr.Error{
+		Message:    msg,
+		Extensions: map[string]interface{}{"code": CodeLimitExceeded},
+	}
+}
+
+// failBatch responds with the errors of the requests of a batch.
+func (rs responder) failBatch(errs []*gqlerr.Error) {
+	status := http.StatusOK
+	resp := make([]response, len(errs))
+	for n, e := range errs {
+		if e == nil {
+			e = &gqlerr.Error{
+				Message: "not executed because of errors " +
+					"in other requests of the batch",
+				Extensions: map[string]interface{}{"code": CodeBatchRejected},
+			}
+		} else if s := rs.status(e); s > status {
+			status = s
+		}
+		resp[n] = response{Errors: []*gqlerr.Error{e}}
+	}
+	rs.respondJSON(status, resp)
+}
+
+// toGQLErr returns err if it's a *gqlerr.Error,
+// otherwise an error with the message of err.
+func toGQLErr(err error) *gqlerr.Error {
+	var e *gqlerr.Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return &gqlerr.Error{Message: err.Error()}
+}
@@ -0,0 +1,223 @@
+package gqlhttp_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/complexity"
+	"github.com/graph-guard/gqlscan/gqlerr"
+	"github.com/graph-guard/gqlscan/gqlhttp"
+
+	"github.com/stretchr/testify/require"
+)
+
+func cost(r *gqlhttp.Request) (int, error) {
+	res, err := complexity.Cost(
+		r.Query, r.OperationName, r.Variables, complexity.Config{},
+	)
+	return res.Total, err
+}
+
+func TestHandlerBatch(t *testing.T) {
+	const body = ` [
+		{"query": "{a}"},
+		{"query": "query Q($n: Int) {b(first: $n) {c}}", "variables": {"n": 2}},
+		{"query": "query A {a} mutation B {b}", "operationName": "B"}
+	] `
+	for _, parallel := range []bool{false, true} {
+		var actual []*gqlhttp.Request
+		h := gqlhttp.Handler(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Nil(t, gqlhttp.FromContext(r.Context()))
+				actual = gqlhttp.BatchFromContext(r.Context())
+				w.WriteHeader(http.StatusTeapot)
+			}),
+			gqlhttp.Config{
+				MaxBatchSize:   3,
+				MaxBatchTokens: 32,
+				MaxBatchCost:   6,
+				Cost:           cost,
+				Parallel:       parallel,
+			},
+		)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, post(gqlhttp.MediaTypeJSON, nil, body))
+		require.Equal(t, http.StatusTeapot, w.Code)
+		require.Len(t, actual, 3)
+
+		require.Equal(t, `{a}`, string(actual[0].Query))
+		require.Equal(t, gqlscan.TokenDefQry, actual[0].Operation.Token)
+
+		require.Equal(t, `{"n": 2}`, string(actual[1].Variables))
+		require.Equal(t, "Q", string(actual[1].Operation.Name))
+
+		require.Equal(t, "B", string(actual[2].OperationName))
+		require.Equal(t, gqlscan.TokenDefMut, actual[2].Operation.Token)
+	}
+}
+
+func TestHandlerBatchErr(t *testing.T) {
+	conf := gqlhttp.Config{
+		MaxTokens:      8,
+		MaxBatchSize:   2,
+		MaxBatchTokens: 12,
+		MaxBatchCost:   3,
+		Cost:           cost,
+	}
+	for _, td := range []struct {
+		decl   string
+		conf   gqlhttp.Config
+		body   string
+		accept string
+		status int
+		expect string
+	}{
+		{
+			decl: decl(1), conf: gqlhttp.Config{},
+			body:   `[{"query": "{a}"}]`,
+			status: http.StatusBadRequest,
+			expect: `{"errors":[{"message":"batching is disabled",` +
+				`"extensions":{"code":"BAD_REQUEST"}}]}`,
+		},
+		{
+			decl: decl(1), conf: conf,
+			body:   `[]`,
+			status: http.StatusBadRequest,
+			expect: `{"errors":[{"message":"empty batch",` +
+				`"extensions":{"code":"BAD_REQUEST"}}]}`,
+		},
+		{
+			decl: decl(1), conf: conf,
+			body:   `[{"query": "{a}"} {"query": "{b}"}]`,
+			status: http.StatusBadRequest,
+			expect: `{"errors":[{"message":"decoding request body: ` +
+				`malformed JSON: unexpected '{' at index 18",` +
+				`"extensions":{"code":"BAD_REQUEST"}}]}`,
+		},
+		{
+			decl: decl(1), conf: conf,
+			body:   `[{"query": "{a}"}, {"query": "{b}"}, {"query": "{c}"}]`,
+			status: http.StatusBadRequest,
+			expect: `{"errors":[{"message":"batch exceeds the limit of 2 requests",` +
+				`"extensions":{"code":"LIMIT_EXCEEDED"}}]}`,
+		},
+		{
+			decl: decl(1), conf: conf,
+			body:   `[{"query": "{a b c d}"}, {"query": "{a b c d}"}]`,
+			status: http.StatusBadRequest,
+			expect: `{"errors":[{"message":"batch exceeds the limit of 12 tokens",` +
+				`"extensions":{"code":"LIMIT_EXCEEDED"}}]}`,
+		},
+		{
+			decl: decl(1), conf: conf,
+			body:   `[{"query": "{a}"}, {"query": "{b(first: 3)}"}]`,
+			status: http.StatusBadRequest,
+			expect: `{"errors":[{"message":"batch exceeds the maximum cost of 3",` +
+				`"extensions":{"code":"LIMIT_EXCEEDED"}}]}`,
+		},
+		{
+			decl: decl(1), conf: conf,
+			body:   `[{"query": "{a}"}, {"query": "{b"}]`,
+			status: http.StatusOK,
+			expect: `[{"errors":[{"message":"not executed because of errors ` +
+				`in other requests of the batch",` +
+				`"extensions":{"code":"BATCH_REJECTED"}}]},` +
+				`{"errors":[{"message":"error at index 2: unexpected end of file; ` +
+				`expected field name or alias","locations":[{"line":1,"column":3}],` +
+				`"extensions":{"code":"GRAPHQL_PARSE_FAILED"}}]}]`,
+		},
+		{
+			decl: decl(1), conf: conf,
+			body:   `[{"query": "{b"}, {"query": "{a}"}]`,
+			accept: gqlhttp.MediaTypeGraphQLResponse,
+			status: http.StatusBadRequest,
+			expect: `[{"errors":[{"message":"error at index 2: unexpected end ` +
+				`of file; expected field name or alias","locations":[{"line":1,"column":3}],` +
+				`"extensions":{"code":"GRAPHQL_PARSE_FAILED"}}]},` +
+				`{"errors":[{"message":"not executed because of errors ` +
+				`in other requests of the batch",` +
+				`"extensions":{"code":"BATCH_REJECTED"}}]}]`,
+		},
+		{
+			decl: decl(1), conf: conf,
+			body:   `[{"variables": {}}, {"query": "{...F}"}]`,
+			status: http.StatusBadRequest,
+			expect: `[{"errors":[{"message":"missing query",` +
+				`"extensions":{"code":"BAD_REQUEST"}}]},` +
+				`{"errors":[{"message":"undefined fragment: \"F\"",` +
+				`"extensions":{"code":"OPERATION_RESOLUTION_FAILURE"}}]}]`,
+		},
+		{
+			decl: decl(1),
+			conf: gqlhttp.Config{
+				MaxBatchSize: 2,
+				MaxBatchCost: 1,
+				Cost: func(r *gqlhttp.Request) (int, error) {
+					if r.OperationName != nil {
+						return 0, errors.New("unknown cost")
+					}
+					return 1, nil
+				},
+			},
+			body:   `[{"query": "{a}"}, {"query": "query A {a}", "operationName": "A"}]`,
+			status: http.StatusOK,
+			expect: `[{"errors":[{"message":"not executed because of errors ` +
+				`in other requests of the batch",` +
+				`"extensions":{"code":"BATCH_REJECTED"}}]},` +
+				`{"errors":[{"message":"unknown cost"}]}]`,
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			for _, parallel := range []bool{false, true} {
+				td.conf.Parallel = parallel
+				h := gqlhttp.Handler(
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						t.Fatal("unexpected call to next")
+					}),
+					td.conf,
+				)
+				r := post(gqlhttp.MediaTypeJSON, nil, td.body)
+				if td.accept != "" {
+					r = accept(r, td.accept)
+				}
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, r)
+				require.Equal(t, td.status, w.Code)
+				require.Equal(t, td.expect, w.Body.String())
+				requireErrorsShape(t, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+// requireErrorsShape makes sure body is either a response
+// or an array of responses carrying errors only.
+func requireErrorsShape(t *testing.T, body []byte) {
+	type response struct {
+		Errors []gqlerr.Error `json:"errors"`
+	}
+	var batch []response
+	if err := json.Unmarshal(body, &batch); err != nil {
+		batch = make([]response, 1)
+		require.NoError(t, json.Unmarshal(body, &batch[0]))
+	}
+	for _, r := range batch {
+		require.NotEmpty(t, r.Errors)
+	}
+}
+
+func TestHandlerMaxBatchCostWithoutCost(t *testing.T) {
+	require.PanicsWithValue(t,
+		"gqlhttp: MaxBatchCost is set but Cost is nil",
+		func() {
+			gqlhttp.Handler(http.NotFoundHandler(), gqlhttp.Config{
+				MaxBatchSize: 2,
+				MaxBatchCost: 3,
+			})
+		},
+	)
+}
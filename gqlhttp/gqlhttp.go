@@ -0,0 +1,447 @@
+// Package gqlhttp parses GraphQL-over-HTTP requests
+// on top of the gqlscan token stream.
+package gqlhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/gqlerr"
+)
+
+// Error codes in the extensions of the errors responded with.
+const (
+	// CodeBadRequest is used when the request isn't
+	// a well-formed GraphQL-over-HTTP request.
+	CodeBadRequest = "BAD_REQUEST"
+
+	// CodeParseFailed is used when the query isn't lexically valid.
+	CodeParseFailed = "GRAPHQL_PARSE_FAILED"
+
+	// CodeLimitExceeded is used when the query exceeds a limit.
+	CodeLimitExceeded = "LIMIT_EXCEEDED"
+
+	// CodeOprResolution is used when the operation can't be selected.
+	CodeOprResolution = "OPERATION_RESOLUTION_FAILURE"
+
+	// CodeBatchRejected is used for the valid requests of a batch
+	// that is rejected because of other requests.
+	CodeBatchRejected = "BATCH_REJECTED"
+)
+
+// Media types.
+const (
+	MediaTypeJSON            = "application/json"
+	MediaTypeGraphQL         = "application/graphql"
+	MediaTypeGraphQLResponse = "application/graphql-response+json"
+)
+
+const (
+	errMsgQueryMissing     = "missing query"
+	errMsgVarsNotObj       = "variables must be a JSON object"
+	errMsgExtensionsNotObj = "extensions must be a JSON object"
+)
+
+// Config configures the middleware.
+// Limits that are zero don't apply unless documented otherwise.
+type Config struct {
+	// MaxBodySize is the maximum size of a request body in bytes.
+	MaxBodySize int64
+
+	// MaxTokens is the maximum number of tokens in the query.
+	MaxTokens int
+
+	// MaxDepth is the maximum selection set nesting depth of the query,
+	// inline fragment selection sets included.
+	MaxDepth int
+
+	// MaxBatchSize is the maximum number of requests in a batch.
+	// Batches are rejected if MaxBatchSize is zero.
+	MaxBatchSize int
+
+	// MaxBatchTokens is the maximum total number of tokens
+	// in the queries of a batch.
+	MaxBatchTokens int
+
+	// MaxBatchCost is the maximum total cost of the requests in a batch
+	// as returned by Cost. Cost must be set if MaxBatchCost is greater
+	// than zero, otherwise Handler panics.
+	MaxBatchCost int
+
+	// Cost returns the cost of a request, see package complexity.
+	// Cost is only called if MaxBatchCost is greater than zero.
+	// If Parallel is true then Cost is called concurrently
+	// and must be safe for concurrent use.
+	Cost func(*Request) (int, error)
+
+	// Parallel makes the queries of a batch scan in parallel.
+	Parallel bool
+}
+
+// Request is a parsed GraphQL-over-HTTP request.
+type Request struct {
+	// Query is the GraphQL document.
+	Query []byte
+
+	// OperationName is the name of the operation to execute
+	// or nil if none was provided.
+	OperationName []byte
+
+	// Variables is the JSON object of variable values
+	// or nil if none was provided.
+	Variables json.RawMessage
+
+	// Extensions is the JSON object of extensions
+	// or nil if none was provided.
+	Extensions json.RawMessage
+
+	// Operation is the operation selected by OperationName.
+	Operation gqlscan.Operation
+}
+
+type ctxKey struct{}
+
+// FromContext returns the request parsed by the middleware
+// or nil if ctx doesn't carry one.
+func FromContext(ctx context.Context) *Request {
+	r, _ := ctx.Value(ctxKey{}).(*Request)
+	return r
+}
+
+// Handler returns a middleware that parses GraphQL-over-HTTP requests
+// and passes the parsed Request to next through the request context,
+// see FromContext. Batches are passed to next as a whole,
+// see BatchFromContext.
+//
+// GET requests carry the parameters "query", "operationName",
+// "variables" and "extensions" in the URL query string, mutations are
+// rejected with 405. POST requests carry them in a JSON object
+// if the media type is application/json, or the query in the body
+// and the other parameters in the URL query string if the media type
+// is application/graphql. A batch is a POST request carrying a JSON
+// array of such objects.
+//
+// Failures are responded with {"errors": [...]} in the media type
+// application/graphql-response+json if the client accepts it,
+// otherwise in application/json. Requests that aren't well-formed are
+// responded with 400. Invalid queries are responded with 400 if the
+// media type is application/graphql-response+json, otherwise with 200.
+//
+// Handler panics if conf.MaxBatchCost is greater than zero
+// but conf.Cost is nil.
+func Handler(next http.Handler, conf Config) http.Handler {
+	if conf.MaxBatchCost > 0 && conf.Cost == nil {
+		panic("gqlhttp: MaxBatchCost is set but Cost is nil")
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rs := responder{w: w, mediaType: responseMediaType(r)}
+		req, batch, ok := conf.parse(rs, r)
+		if !ok {
+			return
+		}
+		if batch != nil {
+			conf.serveBatch(next, rs, r, batch)
+			return
+		}
+		if _, e := conf.prepare(req); e != nil {
+			rs.fail(e)
+			return
+		}
+		if r.Method == http.MethodGet && req.Operation.Token == gqlscan.TokenDefMut {
+			w.Header().Set("Allow", http.MethodPost)
+			rs.respond(http.StatusMethodNotAllowed, &gqlerr.Error{
+				Message:    "mutations are only allowed in POST requests",
+				Extensions: map[string]interface{}{"code": CodeBadRequest},
+			})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(
+			context.WithValue(r.Context(), ctxKey{}, req),
+		))
+	})
+}
+
+// prepare scans the query of req once, selects the operation
+// and returns the number of tokens in the query.
+func (c Config) prepare(req *Request) (int, *gqlerr.Error) {
+	tokens, x, e := c.scan(req.Query)
+	if e != nil {
+		return 0, e
+	}
+	o, err := x.SelectOperation(req.OperationName)
+	if err != nil {
+		return 0, &gqlerr.Error{
+			Message:    err.Error(),
+			Extensions: map[string]interface{}{"code": CodeOprResolution},
+		}
+	}
+	req.Operation = o
+	return tokens, nil
+}
+
+// parse extracts the request parameters from r.
+// Returns the request body instead if it's a batch.
+func (c Config) parse(
+	rs responder, r *http.Request,
+) (req *Request, batch []byte, ok bool) {
+	switch r.Method {
+	case http.MethodGet:
+		req, err := fromQueryString(r, true)
+		if err != nil {
+			rs.fail(errBadRequest(err.Error()))
+			return nil, nil, false
+		}
+		return req, nil, true
+	case http.MethodPost:
+	default:
+		rs.w.Header().Set("Allow", "GET, POST")
+		rs.respond(http.StatusMethodNotAllowed, &gqlerr.Error{
+			Message:    "method not allowed",
+			Extensions: map[string]interface{}{"code": CodeBadRequest},
+		})
+		return nil, nil, false
+	}
+
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil ||
+		(mediaType != MediaTypeJSON && mediaType != MediaTypeGraphQL) ||
+		(params["charset"] != "" &&
+			!strings.EqualFold(params["charset"], "utf-8")) {
+		rs.respond(http.StatusUnsupportedMediaType, &gqlerr.Error{
+			Message:    "unsupported media type",
+			Extensions: map[string]interface{}{"code": CodeBadRequest},
+		})
+		return nil, nil, false
+	}
+
+	body, err := c.readBody(r)
+	if err != nil {
+		if errors.Is(err, errBodyTooLarge) {
+			rs.respond(http.StatusRequestEntityTooLarge, &gqlerr.Error{
+				Message:    errBodyTooLarge.Error(),
+				Extensions: map[string]interface{}{"code": CodeBadRequest},
+			})
+			return nil, nil, false
+		}
+		rs.fail(errBadRequest(err.Error()))
+		return nil, nil, false
+	}
+
+	if mediaType == MediaTypeGraphQL {
+		req, err := fromQueryString(r, false)
+		if err != nil {
+			rs.fail(errBadRequest(err.Error()))
+			return nil, nil, false
+		}
+		req.Query = body
+		return req, nil, true
+	}
+
+	if b := bytes.TrimLeft(body, " \t\n\r"); len(b) > 0 && b[0] == '[' {
+		return nil, body, true
+	}
+	req, _, e := fromJSON(nil, body)
+	if e != nil {
+		rs.fail(e)
+		return nil, nil, false
+	}
+	return req, nil, true
+}
+
+// fromJSON extracts the request parameters from the JSON object
+// in body. buf is used for unescaping and the extended buffer returned.
+func fromJSON(buf, body []byte) (*Request, []byte, *gqlerr.Error) {
+	b, buf, err := ReadJSONBody(buf, body)
+	if err != nil {
+		return nil, buf, errBadRequest(
+			fmt.Sprintf("decoding request body: %s", err),
+		)
+	}
+	if b.Query == nil {
+		return nil, buf, errBadRequest(errMsgQueryMissing)
+	}
+	req := &Request{Query: b.Query, OperationName: b.OperationName}
+	if req.Variables, err = object(b.Variables, errMsgVarsNotObj); err != nil {
+		return nil, buf, errBadRequest(err.Error())
+	}
+	if req.Extensions, err = object(b.Extensions, errMsgExtensionsNotObj); err != nil {
+		return nil, buf, errBadRequest(err.Error())
+	}
+	return req, buf, nil
+}
+
+var errBodyTooLarge = errors.New("request body too large")
+
+// readBody reads the request body up to MaxBodySize.
+func (c Config) readBody(r *http.Request) ([]byte, error) {
+	if c.MaxBodySize < 1 {
+		return io.ReadAll(r.Body)
+	}
+	b, err := io.ReadAll(io.LimitReader(r.Body, c.MaxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > c.MaxBodySize {
+		return nil, errBodyTooLarge
+	}
+	return b, nil
+}
+
+// scan scans query, enforces the limits
+// and returns the number of tokens in query and its index.
+func (c Config) scan(query []byte) (int, gqlscan.Index, *gqlerr.Error) {
+	var (
+		tokens   int
+		x        gqlscan.Index
+		limitErr *gqlerr.Error
+	)
+	err := gqlscan.Scan(query, func(i *gqlscan.Iterator) (stop bool) {
+		tokens++
+		x.Add(i)
+		var msg string
+		switch {
+		case c.MaxTokens > 0 && tokens > c.MaxTokens:
+			msg = fmt.Sprintf("query exceeds the limit of %d tokens", c.MaxTokens)
+		case c.MaxDepth > 0 && i.LevelSelect() > c.MaxDepth:
+			msg = fmt.Sprintf("query exceeds the maximum depth of %d", c.MaxDepth)
+		default:
+			return false
+		}
+		index := i.IndexTail()
+		if index < 0 {
+			index = i.IndexHead()
+		}
+		limitErr = &gqlerr.Error{
+			Message:    msg,
+			Locations:  []gqlerr.Location{gqlerr.LocationOf(query, index)},
+			Extensions: map[string]interface{}{"code": CodeLimitExceeded},
+		}
+		return true
+	})
+	switch {
+	case limitErr != nil:
+		return 0, gqlscan.Index{}, limitErr
+	case err.IsErr():
+		return 0, gqlscan.Index{}, &gqlerr.Error{
+			Message:    err.Error(),
+			Locations:  []gqlerr.Location{gqlerr.LocationOf(query, err.Index)},
+			Extensions: map[string]interface{}{"code": CodeParseFailed},
+		}
+	}
+	return tokens, x, nil
+}
+
+// fromQueryString extracts the request parameters from the URL query
+// string of r. The query is only extracted if withQuery is true.
+func fromQueryString(r *http.Request, withQuery bool) (*Request, error) {
+	q := r.URL.Query()
+	req := new(Request)
+	if withQuery {
+		if !q.Has("query") {
+			return nil, errors.New(errMsgQueryMissing)
+		}
+		req.Query = []byte(q.Get("query"))
+	}
+	if q.Has("operationName") {
+		req.OperationName = []byte(q.Get("operationName"))
+	}
+	var err error
+	if q.Has("variables") {
+		req.Variables, err = object([]byte(q.Get("variables")), errMsgVarsNotObj)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if q.Has("extensions") {
+		req.Extensions, err = object([]byte(q.Get("extensions")), errMsgExtensionsNotObj)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// object returns v if it's a JSON object, nil if it's empty or null
+// and an error with message msg otherwise.
+func object(v []byte, msg string) (json.RawMessage, error) {
+	v = bytes.TrimSpace(v)
+	if len(v) < 1 || string(v) == "null" {
+		return nil, nil
+	}
+	if v[0] != '{' || !json.Valid(v) {
+		return nil, errors.New(msg)
+	}
+	return json.RawMessage(v), nil
+}
+
+// responseMediaType returns application/graphql-response+json
+// if the client accepts it, otherwise application/json.
+func responseMediaType(r *http.Request) string {
+	for _, h := range r.Header.Values("Accept") {
+		for _, a := range strings.Split(h, ",") {
+			t, _, err := mime.ParseMediaType(a)
+			if err == nil && t == MediaTypeGraphQLResponse {
+				return MediaTypeGraphQLResponse
+			}
+		}
+	}
+	return MediaTypeJSON
+}
+
+// responder writes error responses.
+type responder struct {
+	w         http.ResponseWriter
+	mediaType string
+}
+
+// errBadRequest returns an error with the code CodeBadRequest.
+func errBadRequest(msg string) *gqlerr.Error {
+	return &gqlerr.Error{
+		Message:    msg,
+		Extensions: map[string]interface{}{"code": CodeBadRequest},
+	}
+}
+
+// fail responds with e and the status code of e.
+func (rs responder) fail(e *gqlerr.Error) {
+	rs.respond(rs.status(e), e)
+}
+
+// status returns the status code of a response with error e,
+// which is 400 for bad requests and for errors in the query if the media
+// type is application/graphql-response+json, otherwise 200.
+func (rs responder) status(e *gqlerr.Error) int {
+	if e.Extensions["code"] == CodeBadRequest ||
+		rs.mediaType == MediaTypeGraphQLResponse {
+		return http.StatusBadRequest
+	}
+	return http.StatusOK
+}
+
+func (rs responder) respond(status int, errs ...*gqlerr.Error) {
+	rs.respondJSON(status, response{errs})
+}
+
+// response is a response with errors only.
+type response struct {
+	Errors []*gqlerr.Error `json:"errors"`
+}
+
+func (rs responder) respondJSON(status int, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		// Errors only contain marshalable values
+		panic(err)
+	}
+	rs.w.Header().Set("Content-Type", rs.mediaType+"; charset=utf-8")
+	rs.w.WriteHeader(status)
+	_, _ = rs.w.Write(b)
+}
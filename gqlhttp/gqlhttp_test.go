@@ -0,0 +1,327 @@
+package gqlhttp_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/gqlerr"
+	"github.com/graph-guard/gqlscan/gqlhttp"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	for _, td := range []struct {
+		decl   string
+		req    *http.Request
+		expect gqlhttp.Request
+	}{
+		{
+			decl: decl(1),
+			req: get(url.Values{
+				"query":         {`query A {a} query B {b}`},
+				"operationName": {"B"},
+				"variables":     {`{"x":1}`},
+				"extensions":    {`{"e":true}`},
+			}),
+			expect: gqlhttp.Request{
+				Query:         []byte(`query A {a} query B {b}`),
+				OperationName: []byte("B"),
+				Variables:     json.RawMessage(`{"x":1}`),
+				Extensions:    json.RawMessage(`{"e":true}`),
+				Operation: gqlscan.Operation{
+					Token: gqlscan.TokenDefQry,
+					Name:  []byte("B"),
+					Span:  gqlscan.Span{Start: 12, End: 23},
+				},
+			},
+		},
+		{
+			decl: decl(1),
+			req: post(gqlhttp.MediaTypeJSON, nil, `{
+				"query": "mutation M($x: Int) {m(x: $x)}",
+				"variables": {"x": 1},
+				"extensions": null
+			}`),
+			expect: gqlhttp.Request{
+				Query:     []byte(`mutation M($x: Int) {m(x: $x)}`),
+				Variables: json.RawMessage(`{"x": 1}`),
+				Operation: gqlscan.Operation{
+					Token: gqlscan.TokenDefMut,
+					Name:  []byte("M"),
+					Span:  gqlscan.Span{Start: 0, End: 30},
+				},
+			},
+		},
+		{
+			decl: decl(1),
+			req: post(
+				gqlhttp.MediaTypeGraphQL+"; charset=UTF-8",
+				url.Values{"operationName": {"S"}},
+				`subscription S {s}`,
+			),
+			expect: gqlhttp.Request{
+				Query:         []byte(`subscription S {s}`),
+				OperationName: []byte("S"),
+				Operation: gqlscan.Operation{
+					Token: gqlscan.TokenDefSub,
+					Name:  []byte("S"),
+					Span:  gqlscan.Span{Start: 0, End: 18},
+				},
+			},
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			var actual *gqlhttp.Request
+			h := gqlhttp.Handler(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					actual = gqlhttp.FromContext(r.Context())
+					w.WriteHeader(http.StatusTeapot)
+				}),
+				gqlhttp.Config{MaxBodySize: 1024, MaxTokens: 16, MaxDepth: 2},
+			)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, td.req)
+			require.Equal(t, http.StatusTeapot, w.Code)
+			require.NotNil(t, actual)
+			require.Equal(t, td.expect, *actual)
+		})
+	}
+}
+
+func TestHandlerErr(t *testing.T) {
+	conf := gqlhttp.Config{MaxBodySize: 64, MaxTokens: 8, MaxDepth: 2}
+	for _, td := range []struct {
+		decl      string
+		req       *http.Request
+		status    int
+		mediaType string
+		expect    gqlerr.Error
+	}{
+		{
+			decl:      decl(1),
+			req:       httptest.NewRequest(http.MethodPut, "/", nil),
+			status:    http.StatusMethodNotAllowed,
+			mediaType: gqlhttp.MediaTypeJSON,
+			expect:    errBadRequest("method not allowed"),
+		},
+		{
+			decl:      decl(1),
+			req:       get(url.Values{"variables": {`{}`}}),
+			status:    http.StatusBadRequest,
+			mediaType: gqlhttp.MediaTypeJSON,
+			expect:    errBadRequest("missing query"),
+		},
+		{
+			decl:      decl(1),
+			req:       get(url.Values{"query": {`{a}`}, "variables": {`[]`}}),
+			status:    http.StatusBadRequest,
+			mediaType: gqlhttp.MediaTypeJSON,
+			expect:    errBadRequest("variables must be a JSON object"),
+		},
+		{
+			decl:      decl(1),
+			req:       get(url.Values{"query": {`{a}`}, "extensions": {`{`}}),
+			status:    http.StatusBadRequest,
+			mediaType: gqlhttp.MediaTypeJSON,
+			expect:    errBadRequest("extensions must be a JSON object"),
+		},
+		{
+			decl:      decl(1),
+			req:       get(url.Values{"query": {`mutation {a}`}}),
+			status:    http.StatusMethodNotAllowed,
+			mediaType: gqlhttp.MediaTypeJSON,
+			expect: errBadRequest(
+				"mutations are only allowed in POST requests",
+			),
+		},
+		{
+			decl:      decl(1),
+			req:       post("text/plain", nil, `{a}`),
+			status:    http.StatusUnsupportedMediaType,
+			mediaType: gqlhttp.MediaTypeJSON,
+			expect:    errBadRequest("unsupported media type"),
+		},
+		{
+			decl:      decl(1),
+			req:       post(gqlhttp.MediaTypeJSON+"; charset=latin1", nil, `{}`),
+			status:    http.StatusUnsupportedMediaType,
+			mediaType: gqlhttp.MediaTypeJSON,
+			expect:    errBadRequest("unsupported media type"),
+		},
+		{
+			decl: decl(1),
+			req: post(gqlhttp.MediaTypeGraphQL, nil,
+				`{`+strings.Repeat(" ", 64)+`a}`),
+			status:    http.StatusRequestEntityTooLarge,
+			mediaType: gqlhttp.MediaTypeJSON,
+			expect:    errBadRequest("request body too large"),
+		},
+		{
+			decl:      decl(1),
+			req:       post(gqlhttp.MediaTypeJSON, nil, `{"query": ["{a}"]}`),
+			status:    http.StatusBadRequest,
+			mediaType: gqlhttp.MediaTypeJSON,
+			expect: errBadRequest(
+				"decoding request body: query must be a string",
+			),
+		},
+		{
+			decl:      decl(1),
+			req:       post(gqlhttp.MediaTypeJSON, nil, `{"variables": {}}`),
+			status:    http.StatusBadRequest,
+			mediaType: gqlhttp.MediaTypeJSON,
+			expect:    errBadRequest("missing query"),
+		},
+		{
+			decl:      decl(1),
+			req:       post(gqlhttp.MediaTypeJSON, nil, `{"query": `),
+			status:    http.StatusBadRequest,
+			mediaType: gqlhttp.MediaTypeJSON,
+			expect: errBadRequest(
+				"decoding request body: malformed JSON: unexpected end of input",
+			),
+		},
+		{
+			decl:      decl(1),
+			req:       post(gqlhttp.MediaTypeGraphQL, nil, "{\n a(}"),
+			status:    http.StatusOK,
+			mediaType: gqlhttp.MediaTypeJSON,
+			expect: gqlerr.Error{
+				Message:    "error at index 5 ('}'): unexpected token; expected argument name",
+				Locations:  []gqlerr.Location{{Line: 2, Column: 4}},
+				Extensions: map[string]interface{}{"code": "GRAPHQL_PARSE_FAILED"},
+			},
+		},
+		{
+			decl: decl(1),
+			req: accept(
+				post(gqlhttp.MediaTypeGraphQL, nil, "{\n a(}"),
+				"application/json;q=0.9, application/graphql-response+json",
+			),
+			status:    http.StatusBadRequest,
+			mediaType: gqlhttp.MediaTypeGraphQLResponse,
+			expect: gqlerr.Error{
+				Message:    "error at index 5 ('}'): unexpected token; expected argument name",
+				Locations:  []gqlerr.Location{{Line: 2, Column: 4}},
+				Extensions: map[string]interface{}{"code": "GRAPHQL_PARSE_FAILED"},
+			},
+		},
+		{
+			decl:      decl(1),
+			req:       get(url.Values{"query": {`{a b c d e f g}`}}),
+			status:    http.StatusOK,
+			mediaType: gqlhttp.MediaTypeJSON,
+			expect: gqlerr.Error{
+				Message:    "query exceeds the limit of 8 tokens",
+				Locations:  []gqlerr.Location{{Line: 1, Column: 14}},
+				Extensions: map[string]interface{}{"code": "LIMIT_EXCEEDED"},
+			},
+		},
+		{
+			decl:      decl(1),
+			req:       get(url.Values{"query": {"{a {\n b {c}}}"}}),
+			status:    http.StatusOK,
+			mediaType: gqlhttp.MediaTypeJSON,
+			expect: gqlerr.Error{
+				Message:    "query exceeds the maximum depth of 2",
+				Locations:  []gqlerr.Location{{Line: 2, Column: 5}},
+				Extensions: map[string]interface{}{"code": "LIMIT_EXCEEDED"},
+			},
+		},
+		{
+			decl:      decl(1),
+			req:       get(url.Values{"query": {`{a} {b}`}}),
+			status:    http.StatusOK,
+			mediaType: gqlhttp.MediaTypeJSON,
+			expect: gqlerr.Error{
+				Message: "ambiguous operation",
+				Extensions: map[string]interface{}{
+					"code": "OPERATION_RESOLUTION_FAILURE",
+				},
+			},
+		},
+		{
+			decl: decl(1),
+			req: get(url.Values{
+				"query":         {`query A {a}`},
+				"operationName": {"B"},
+			}),
+			status:    http.StatusOK,
+			mediaType: gqlhttp.MediaTypeJSON,
+			expect: gqlerr.Error{
+				Message: `operation not found: "B"`,
+				Extensions: map[string]interface{}{
+					"code": "OPERATION_RESOLUTION_FAILURE",
+				},
+			},
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			h := gqlhttp.Handler(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					t.Fatal("unexpected call to next")
+				}),
+				conf,
+			)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, td.req)
+			require.Equal(t, td.status, w.Code)
+			require.Equal(t,
+				td.mediaType+"; charset=utf-8",
+				w.Header().Get("Content-Type"),
+			)
+
+			body, err := io.ReadAll(w.Body)
+			require.NoError(t, err)
+			var resp struct {
+				Errors []gqlerr.Error `json:"errors"`
+			}
+			require.NoError(t, json.Unmarshal(body, &resp))
+			require.Equal(t, []gqlerr.Error{td.expect}, resp.Errors)
+		})
+	}
+}
+
+func TestFromContextNil(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	require.Nil(t, gqlhttp.FromContext(r.Context()))
+}
+
+func get(q url.Values) *http.Request {
+	return httptest.NewRequest(http.MethodGet, "/?"+q.Encode(), nil)
+}
+
+func post(contentType string, q url.Values, body string) *http.Request {
+	r := httptest.NewRequest(
+		http.MethodPost, "/?"+q.Encode(), strings.NewReader(body),
+	)
+	r.Header.Set("Content-Type", contentType)
+	return r
+}
+
+func accept(r *http.Request, mediaTypes string) *http.Request {
+	r.Header.Set("Accept", mediaTypes)
+	return r
+}
+
+func errBadRequest(msg string) gqlerr.Error {
+	return gqlerr.Error{
+		Message:    msg,
+		Extensions: map[string]interface{}{"code": "BAD_REQUEST"},
+	}
+}
+
+func decl(skipFrames int) string {
+	_, filename, line, _ := runtime.Caller(skipFrames)
+	return fmt.Sprintf("%s:%d", filepath.Base(filename), line)
+}
@@ -0,0 +1,409 @@
+package gqlhttp
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrMalformedJSON is returned when a request body isn't valid JSON.
+var ErrMalformedJSON = errors.New("malformed JSON")
+
+// JSONBody is a GraphQL-over-HTTP JSON request body.
+type JSONBody struct {
+	// Query is the unescaped query string
+	// or nil if it's absent or null.
+	Query []byte
+
+	// OperationName is the unescaped operation name
+	// or nil if it's absent or null.
+	OperationName []byte
+
+	// Variables is the raw variables value
+	// or nil if it's absent or null.
+	Variables []byte
+
+	// Extensions is the raw extensions value
+	// or nil if it's absent or null.
+	Extensions []byte
+}
+
+// ReadJSONBody reads the members "query", "operationName", "variables"
+// and "extensions" of the JSON object in body without decoding
+// any other member. The query and the operation name refer to
+// the same underlying memory as body unless they contain escape
+// sequences, in which case they're unescaped into buf.
+// Variables and Extensions always refer to body.
+// If a member is defined more than once, the last definition applies.
+//
+// Returns the extended buffer and an error wrapping ErrMalformedJSON
+// if body isn't a valid JSON object or an error if the query or the
+// operation name isn't a string.
+func ReadJSONBody(buf, body []byte) (JSONBody, []byte, error) {
+	var b JSONBody
+	r := jsonReader{b: body}
+	r.skipSpace()
+	if !r.is('{') {
+		return JSONBody{}, buf, r.errSyntax()
+	}
+	r.i++
+	r.skipSpace()
+	if r.is('}') {
+		r.i++
+		return b, buf, r.end()
+	}
+	for {
+		key, err := r.key()
+		if err != nil {
+			return JSONBody{}, buf, err
+		}
+		r.skipSpace()
+		switch string(key) {
+		case "query", "operationName":
+			v, err := r.optStr(key)
+			if err != nil {
+				return JSONBody{}, buf, err
+			}
+			if v.escaped {
+				l := len(buf)
+				buf = appendUnescaped(buf, v.raw)
+				v.raw = buf[l:]
+			}
+			if key[0] == 'q' {
+				b.Query = v.raw
+			} else {
+				b.OperationName = v.raw
+			}
+		case "variables", "extensions":
+			start := r.i
+			if err := r.skipValue(); err != nil {
+				return JSONBody{}, buf, err
+			}
+			v := body[start:r.i]
+			if string(v) == "null" {
+				v = nil
+			}
+			if key[0] == 'v' {
+				b.Variables = v
+			} else {
+				b.Extensions = v
+			}
+		default:
+			if err := r.skipValue(); err != nil {
+				return JSONBody{}, buf, err
+			}
+		}
+		r.skipSpace()
+		switch {
+		case r.is(','):
+			r.i++
+		case r.is('}'):
+			r.i++
+			return b, buf, r.end()
+		default:
+			return JSONBody{}, buf, r.errSyntax()
+		}
+	}
+}
+
+// jsonReader reads JSON values from b.
+type jsonReader struct {
+	b []byte
+	i int
+}
+
+// str is a JSON string.
+type str struct {
+	// raw is the string contents without the quotes.
+	raw []byte
+
+	// escaped is true if raw contains escape sequences.
+	escaped bool
+}
+
+func (r *jsonReader) is(c byte) bool {
+	return r.i < len(r.b) && r.b[r.i] == c
+}
+
+func (r *jsonReader) errSyntax() error {
+	if r.i >= len(r.b) {
+		return fmt.Errorf("%w: unexpected end of input", ErrMalformedJSON)
+	}
+	return fmt.Errorf(
+		"%w: unexpected %q at index %d", ErrMalformedJSON, r.b[r.i], r.i,
+	)
+}
+
+func (r *jsonReader) skipSpace() {
+	for ; r.i < len(r.b); r.i++ {
+		switch r.b[r.i] {
+		case ' ', '\t', '\n', '\r':
+		default:
+			return
+		}
+	}
+}
+
+// end makes sure there is nothing but whitespace left.
+func (r *jsonReader) end() error {
+	r.skipSpace()
+	if r.i < len(r.b) {
+		return r.errSyntax()
+	}
+	return nil
+}
+
+// key reads an object member name and the following colon.
+func (r *jsonReader) key() ([]byte, error) {
+	r.skipSpace()
+	k, err := r.str()
+	if err != nil {
+		return nil, err
+	}
+	r.skipSpace()
+	if !r.is(':') {
+		return nil, r.errSyntax()
+	}
+	r.i++
+	// Member names with escape sequences are compared unescaped
+	if k.escaped {
+		return appendUnescaped(nil, k.raw), nil
+	}
+	return k.raw, nil
+}
+
+// optStr reads either a string or null as the value of member key.
+func (r *jsonReader) optStr(key []byte) (str, error) {
+	if r.is('"') {
+		return r.str()
+	}
+	start := r.i
+	if err := r.skipValue(); err != nil {
+		return str{}, err
+	}
+	if string(r.b[start:r.i]) == "null" {
+		return str{}, nil
+	}
+	return str{}, fmt.Errorf("%s must be a string", key)
+}
+
+// str reads a string.
+func (r *jsonReader) str() (str, error) {
+	if !r.is('"') {
+		return str{}, r.errSyntax()
+	}
+	r.i++
+	s := str{}
+	start := r.i
+	for r.i < len(r.b) {
+		switch c := r.b[r.i]; {
+		case c == '"':
+			s.raw = r.b[start:r.i]
+			r.i++
+			return s, nil
+		case c == '\\':
+			s.escaped = true
+			r.i++
+			if r.i >= len(r.b) {
+				return str{}, r.errSyntax()
+			}
+			switch r.b[r.i] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				r.i++
+			case 'u':
+				r.i++
+				for n := 0; n < 4; n, r.i = n+1, r.i+1 {
+					if r.i >= len(r.b) || hexVal(r.b[r.i]) < 0 {
+						return str{}, r.errSyntax()
+					}
+				}
+			default:
+				return str{}, r.errSyntax()
+			}
+		case c < 0x20:
+			return str{}, r.errSyntax()
+		default:
+			r.i++
+		}
+	}
+	return str{}, r.errSyntax()
+}
+
+// skipValue skips a value of any type including nested values.
+func (r *jsonReader) skipValue() error {
+	// stack holds the opening brackets of the enclosing arrays and objects
+	var stack []byte
+	for {
+		r.skipSpace()
+		if r.i >= len(r.b) {
+			return r.errSyntax()
+		}
+		switch c := r.b[r.i]; c {
+		case '{', '[':
+			r.i++
+			r.skipSpace()
+			if r.is(c + 2) { // '{'+2 == '}' and '['+2 == ']'
+				r.i++
+				break
+			}
+			stack = append(stack, c)
+			if c == '{' {
+				if _, err := r.key(); err != nil {
+					return err
+				}
+			}
+			continue
+		case '"':
+			if _, err := r.str(); err != nil {
+				return err
+			}
+		case 't':
+			if err := r.literal("true"); err != nil {
+				return err
+			}
+		case 'f':
+			if err := r.literal("false"); err != nil {
+				return err
+			}
+		case 'n':
+			if err := r.literal("null"); err != nil {
+				return err
+			}
+		default:
+			if err := r.number(); err != nil {
+				return err
+			}
+		}
+
+		// Close the enclosing values until the next value is expected
+		for {
+			if len(stack) < 1 {
+				return nil
+			}
+			r.skipSpace()
+			top := stack[len(stack)-1]
+			if r.is(',') {
+				r.i++
+				if top == '{' {
+					if _, err := r.key(); err != nil {
+						return err
+					}
+				}
+				break
+			}
+			if !r.is(top + 2) {
+				return r.errSyntax()
+			}
+			r.i++
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+func (r *jsonReader) literal(l string) error {
+	if len(r.b)-r.i < len(l) || string(r.b[r.i:r.i+len(l)]) != l {
+		return r.errSyntax()
+	}
+	r.i += len(l)
+	return nil
+}
+
+func (r *jsonReader) number() error {
+	digits := func() int {
+		start := r.i
+		for r.i < len(r.b) && r.b[r.i] >= '0' && r.b[r.i] <= '9' {
+			r.i++
+		}
+		return r.i - start
+	}
+	if r.is('-') {
+		r.i++
+	}
+	if r.is('0') {
+		r.i++
+	} else if digits() < 1 {
+		return r.errSyntax()
+	}
+	if r.is('.') {
+		r.i++
+		if digits() < 1 {
+			return r.errSyntax()
+		}
+	}
+	if r.is('e') || r.is('E') {
+		r.i++
+		if r.is('+') || r.is('-') {
+			r.i++
+		}
+		if digits() < 1 {
+			return r.errSyntax()
+		}
+	}
+	return nil
+}
+
+// appendUnescaped appends the unescaped contents of a valid
+// JSON string s to buf. Invalid surrogates are replaced with U+FFFD.
+func appendUnescaped(buf, s []byte) []byte {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			buf = append(buf, s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'b':
+			buf = append(buf, '\b')
+		case 'f':
+			buf = append(buf, '\f')
+		case 'n':
+			buf = append(buf, '\n')
+		case 'r':
+			buf = append(buf, '\r')
+		case 't':
+			buf = append(buf, '\t')
+		case 'u':
+			r := hex4(s[i+1:])
+			i += 4
+			if utf16.IsSurrogate(r) {
+				r2 := utf8.RuneError
+				if i+6 < len(s) && s[i+1] == '\\' && s[i+2] == 'u' {
+					r2 = hex4(s[i+3:])
+				}
+				if d := utf16.DecodeRune(r, r2); d != utf8.RuneError {
+					r = d
+					i += 6
+				} else {
+					r = utf8.RuneError
+				}
+			}
+			buf = utf8.AppendRune(buf, r)
+		default: // '"', '\\' and '/'
+			buf = append(buf, s[i])
+		}
+	}
+	return buf
+}
+
+// hex4 returns the value of the 4 hex digits at the start of s.
+func hex4(s []byte) rune {
+	var r rune
+	for _, c := range s[:4] {
+		r = r<<4 | rune(hexVal(c))
+	}
+	return r
+}
+
+// hexVal returns the value of hex digit c or -1 if c isn't a hex digit.
+func hexVal(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'f':
+		return int(c-'a') + 10
+	case c >= 'A' && c <= 'F':
+		return int(c-'A') + 10
+	}
+	return -1
+}
@@ -0,0 +1,145 @@
+package gqlhttp_test
+
+import (
+	"testing"
+
+	"github.com/graph-guard/gqlscan/gqlhttp"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadJSONBody(t *testing.T) {
+	for _, td := range []struct {
+		decl   string
+		input  string
+		expect gqlhttp.JSONBody
+		buf    string
+	}{
+		{decl(1), `{}`, gqlhttp.JSONBody{}, ""},
+		{
+			decl(1),
+			` { "query" : "{a}" , "operationName": null } `,
+			gqlhttp.JSONBody{Query: []byte(`{a}`)},
+			"",
+		},
+		{
+			decl(1),
+			`{"query": "", "variables": null, "extensions": null}`,
+			gqlhttp.JSONBody{Query: []byte(``)},
+			"",
+		},
+		{
+			decl(1),
+			`{
+				"x": [{"query": "ignored"}, -1.5e+3, true, false, null, [], {}],
+				"query": "query Q($v: String) {a(s: $v)}",
+				"operationName": "Q",
+				"variables": {"v": "\"[{}]\""},
+				"extensions": {"persistedQuery": {"version": 1}}
+			}`,
+			gqlhttp.JSONBody{
+				Query:         []byte(`query Q($v: String) {a(s: $v)}`),
+				OperationName: []byte(`Q`),
+				Variables:     []byte(`{"v": "\"[{}]\""}`),
+				Extensions:    []byte(`{"persistedQuery": {"version": 1}}`),
+			},
+			"",
+		},
+		{
+			decl(1),
+			`{"query": "{a(s: \"\\u00e4\\n\")}\tü 😀 \ud800 \/"}`,
+			gqlhttp.JSONBody{Query: []byte("{a(s: \"\\u00e4\\n\")}\tü 😀 � /")},
+			"{a(s: \"\\u00e4\\n\")}\tü 😀 � /",
+		},
+		{
+			decl(1),
+			`{"query": "{a}", "query": "{b}", "operationName": "A"}`,
+			gqlhttp.JSONBody{
+				Query:         []byte(`{b}`),
+				OperationName: []byte(`A`),
+			},
+			"",
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			b, buf, err := gqlhttp.ReadJSONBody(nil, []byte(td.input))
+			require.NoError(t, err)
+			require.Equal(t, td.expect, b)
+			require.Equal(t, td.buf, string(buf))
+		})
+	}
+}
+
+func TestReadJSONBodyReuseBuffer(t *testing.T) {
+	buf := make([]byte, 0, 64)
+	b, buf, err := gqlhttp.ReadJSONBody(buf, []byte(`{"query": "{\na}"}`))
+	require.NoError(t, err)
+	require.Equal(t, "{\na}", string(b.Query))
+	require.Equal(t, "{\na}", string(buf))
+
+	b, buf, err = gqlhttp.ReadJSONBody(buf[:0], []byte(`{"query": "{b}"}`))
+	require.NoError(t, err)
+	require.Equal(t, "{b}", string(b.Query))
+	require.Len(t, buf, 0)
+}
+
+func TestReadJSONBodyErr(t *testing.T) {
+	for _, td := range []struct {
+		decl  string
+		input string
+		msg   string
+	}{
+		{decl(1), ``, "malformed JSON: unexpected end of input"},
+		{decl(1), `[]`, "malformed JSON: unexpected '[' at index 0"},
+		{decl(1), `{"query": "{a}"`, "malformed JSON: unexpected end of input"},
+		{decl(1), `{"query": "{a}"} {}`, "malformed JSON: unexpected '{' at index 17"},
+		{decl(1), `{"query": "{a}",}`, "malformed JSON: unexpected '}' at index 16"},
+		{decl(1), `{query: "{a}"}`, "malformed JSON: unexpected 'q' at index 1"},
+		{decl(1), `{"query" "{a}"}`, "malformed JSON: unexpected '\"' at index 9"},
+		{decl(1), `{"query": "{a}` + "\n" + `"}`, "malformed JSON: unexpected '\\n' at index 14"},
+		{decl(1), `{"query": "\x"}`, "malformed JSON: unexpected 'x' at index 12"},
+		{decl(1), `{"query": "\u12"}`, "malformed JSON: unexpected '\"' at index 15"},
+		{decl(1), `{"x": [1 2]}`, "malformed JSON: unexpected '2' at index 9"},
+		{decl(1), `{"x": {"a" 1}}`, "malformed JSON: unexpected '1' at index 11"},
+		{decl(1), `{"x": [1}`, "malformed JSON: unexpected '}' at index 8"},
+		{decl(1), `{"x": 01}`, "malformed JSON: unexpected '1' at index 7"},
+		{decl(1), `{"x": 1.}`, "malformed JSON: unexpected '}' at index 8"},
+		{decl(1), `{"x": -}`, "malformed JSON: unexpected '}' at index 7"},
+		{decl(1), `{"x": 1e}`, "malformed JSON: unexpected '}' at index 8"},
+		{decl(1), `{"x": tru}`, "malformed JSON: unexpected 't' at index 6"},
+		{decl(1), `{"variables": [[[`, "malformed JSON: unexpected end of input"},
+		{decl(1), `{"query": 1}`, "query must be a string"},
+		{decl(1), `{"operationName": {}}`, "operationName must be a string"},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			_, _, err := gqlhttp.ReadJSONBody(nil, []byte(td.input))
+			require.Error(t, err)
+			require.Equal(t, td.msg, err.Error())
+			if td.msg[0] == 'm' {
+				require.ErrorIs(t, err, gqlhttp.ErrMalformedJSON)
+			}
+		})
+	}
+}
+
+func BenchmarkReadJSONBody(b *testing.B) {
+	for _, td := range []struct {
+		decl  string
+		input string
+	}{
+		{decl(1), `{"query": "query Q($v: Int) {a(v: $v) {b c}}", "variables": {"v": 1}}`},
+		{decl(1), `{"query": "query Q($v: String) {\n  a(v: $v) {\n    b\n  }\n}", "variables": {"v": "\"x\""}}`},
+	} {
+		b.Run(td.decl, func(b *testing.B) {
+			in := []byte(td.input)
+			buf := make([]byte, 0, len(in))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				var err error
+				if _, buf, err = gqlhttp.ReadJSONBody(buf[:0], in); err != nil {
+					panic(err)
+				}
+			}
+		})
+	}
+}
@@ -26,8 +26,11 @@ func Scan(str []byte, fn func(*Iterator) (err bool)) Error {
 	i.expect = ExpectDef
 	i.tail, i.head = -1, 0
 	i.str = str
+	i.r = nil
 	i.levelSel = 0
+	i.offset = 0
 	i.errc = 0
+	i.path.on = false
 	defer iteratorPool.Put(i)
 
 	// inDefVal triggers different expectations after values
@@ -133,7 +136,7 @@ DEFINITION:
 		i.expect = ExpectDef
 		goto COMMENT
 	} else if i.str[i.head] == '{' {
-		i.token = TokenDefQry
+		i.token, i.def = TokenDefQry, TokenDefQry
 		/*<callback>*/
 
 		if fn(i) {
@@ -146,7 +149,7 @@ DEFINITION:
 		goto SELECTION_SET
 	} else if i.isHeadKeywordQuery() {
 		// Query
-		i.token = TokenDefQry
+		i.token, i.def = TokenDefQry, TokenDefQry
 		/*<callback>*/
 
 		if fn(i) {
@@ -160,7 +163,7 @@ DEFINITION:
 		goto AFTER_DEF_KEYWORD
 	} else if i.isHeadKeywordMutation() {
 		// Mutation
-		i.token = TokenDefMut
+		i.token, i.def = TokenDefMut, TokenDefMut
 		/*<callback>*/
 
 		if fn(i) {
@@ -174,7 +177,7 @@ DEFINITION:
 		goto AFTER_DEF_KEYWORD
 	} else if i.isHeadKeywordSubscription() {
 		// Subscription
-		i.token = TokenDefSub
+		i.token, i.def = TokenDefSub, TokenDefSub
 		/*<callback>*/
 
 		if fn(i) {
@@ -189,7 +192,7 @@ DEFINITION:
 	} else if i.isHeadKeywordFragment() {
 		// Fragment
 		i.tail = -1
-		i.token = TokenDefFrag
+		i.token, i.def = TokenDefFrag, TokenDefFrag
 		/*<callback>*/
 
 		if fn(i) {
@@ -1442,6 +1445,8744 @@ AFTER_DIR_ARGS:
 			i.head++
 			i.expect = ExpectDir
 			goto DIR_NAME
+		case ')':
+			dirOn = 0
+			goto VAR_LIST_END
+		default:
+			i.expect, dirOn = ExpectAfterVarType, 0
+			goto OPR_VAR
+		}
+	case dirFragRef:
+
+		/*<check_eof>*/
+		if i.head >= len(i.str) {
+			i.errc, i.expect = ErrUnexpEOF, ExpectAfterSelection
+			goto ERROR
+		}
+		/*</check_eof>*/
+
+		switch i.str[i.head] {
+		case '#':
+			goto COMMENT
+		case '@':
+			i.head++
+			i.expect = ExpectDir
+			goto DIR_NAME
+		default:
+			i.expect, dirOn = ExpectAfterSelection, 0
+			goto AFTER_SELECTION
+		}
+	case dirFragInlineOrDef:
+
+		/*<check_eof>*/
+		if i.head >= len(i.str) {
+			i.errc, i.expect = ErrUnexpEOF, ExpectSelSet
+			goto ERROR
+		}
+		/*</check_eof>*/
+
+		switch i.str[i.head] {
+		case '#':
+			goto COMMENT
+		case '@':
+			i.head++
+			i.expect = ExpectDir
+			goto DIR_NAME
+		default:
+			i.expect, dirOn = ExpectSelSet, 0
+			goto SELECTION_SET
+		}
+	default:
+		// This line is only executed if we forgot to handle a dirOn case.
+		panic(fmt.Errorf("unhandled dirOn case: %#v", dirOn))
+	}
+	/*</l_after_dir_args>*/
+
+	/*<l_after_keyword_fragment>*/
+AFTER_KEYWORD_FRAGMENT:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if i.str[i.head] == '#' {
+		goto COMMENT
+	}
+
+	/*<name>*/
+	// Followed by fragname>
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	i.tail = i.head
+	if i.str[i.head] != '_' &&
+		(i.str[i.head] < 'a' || i.str[i.head] > 'z') &&
+		(i.str[i.head] < 'A' || i.str[i.head] > 'Z') {
+		i.errc = ErrUnexpToken
+		goto ERROR
+	}
+	i.head++
+	for {
+		if i.head+7 >= len(i.str) {
+			for ; i.head < len(i.str); i.head++ {
+				if i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z') {
+					continue
+				} else if i.str[i.head] == ' ' ||
+					i.str[i.head] == '\n' ||
+					i.str[i.head] == '\r' ||
+					i.str[i.head] == '\t' ||
+					i.str[i.head] == ',' {
+					break
+				} else if i.str[i.head] < 0x20 {
+					i.errc = ErrUnexpToken
+					goto ERROR
+				}
+				break
+			}
+			break
+		}
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+	}
+
+	// <ExpectFragName after name>
+	if i.head-i.tail == 2 &&
+		i.str[i.tail+1] == 'n' &&
+		i.str[i.tail] == 'o' {
+		i.errc, i.head = ErrIllegalFragName, i.tail
+		goto ERROR
+	}
+	i.token = TokenFragName
+	/*<callback>*/
+
+	if fn(i) {
+		i.errc = ErrCallbackFn
+		goto ERROR
+	}
+
+	/*</callback>*/
+	i.expect = ExpectFragKeywordOn
+	goto FRAG_KEYWORD_ON
+	// </ExpectFragName after name>
+
+	/*</name>*/
+
+	/*</l_after_keyword_fragment>*/
+
+	/*<l_opr_var>*/
+OPR_VAR:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if i.str[i.head] == '#' {
+		goto COMMENT
+	}
+
+	// Variable name
+	if i.str[i.head] != '$' {
+		i.errc = ErrUnexpToken
+		goto ERROR
+	}
+	i.head++
+	i.expect = ExpectVarName
+	goto VAR_NAME
+	/*</l_opr_var>*/
+
+	/*<l_after_var_type>*/
+AFTER_VAR_TYPE:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if i.str[i.head] == '#' {
+		goto COMMENT
+	} else if typeArrLvl != 0 {
+		i.head--
+		i.errc = ErrInvalType
+		i.expect = ExpectVarType
+		goto ERROR
+	} else if i.str[i.head] == '@' {
+		i.head++
+		dirOn, i.expect = dirVar, ExpectDir
+		goto DIR_NAME
+	} else if i.str[i.head] == '=' {
+		i.head++
+
+		/*<skip_irrelevant>*/
+		for {
+			if i.head+7 >= len(i.str) {
+				for i.head < len(i.str) {
+					if i.str[i.head] != ',' &&
+						i.str[i.head] != ' ' &&
+						i.str[i.head] != '\n' &&
+						i.str[i.head] != '\t' &&
+						i.str[i.head] != '\r' {
+						break
+					}
+					i.head++
+				}
+				break
+			}
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+		}
+		/*</skip_irrelevant>*/
+
+		i.expect, inDefVal = ExpectVal, true
+		goto VALUE
+	} else if i.str[i.head] == ')' {
+		goto VAR_LIST_END
+	}
+	i.expect = ExpectAfterVarType
+	goto OPR_VAR
+	/*</l_after_var_type>*/
+
+	/*<l_var_list_end>*/
+VAR_LIST_END:
+	i.tail = -1
+	i.token = TokenVarListEnd
+	/*<callback>*/
+
+	if fn(i) {
+		i.errc = ErrCallbackFn
+		goto ERROR
+	}
+
+	/*</callback>*/
+	i.head++
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	i.expect = ExpectSelSet
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if i.str[i.head] == '#' {
+		dirOn, i.expect = dirOpr, ExpectDirName
+		goto AFTER_DIR_NAME
+	} else if i.str[i.head] == '@' {
+		i.head++
+		dirOn, i.expect = dirOpr, ExpectDir
+		goto DIR_NAME
+	}
+	goto SELECTION_SET
+	/*</l_var_list_end>*/
+
+	/*<l_selection_set>*/
+SELECTION_SET:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	if i.str[i.head] == '#' {
+		goto COMMENT
+	} else if i.str[i.head] != '{' {
+		i.errc = ErrUnexpToken
+		goto ERROR
+	}
+	i.tail = -1
+	i.token = TokenSet
+	/*<callback>*/
+
+	if fn(i) {
+		i.errc = ErrCallbackFn
+		goto ERROR
+	}
+
+	/*</callback>*/
+	i.levelSel++
+	i.head++
+	i.expect = ExpectSel
+	goto SELECTION
+	/*</l_selection_set>*/
+
+	/*<l_after_selection>*/
+AFTER_SELECTION:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if i.str[i.head] == '#' {
+		goto COMMENT
+	} else if i.str[i.head] == '}' {
+		goto SEL_END
+	}
+	i.expect = ExpectSel
+	goto SELECTION
+	/*</l_after_selection>*/
+
+	/*<l_sel_end>*/
+SEL_END:
+	i.tail = -1
+	i.token = TokenSetEnd
+	/*<callback>*/
+
+	if fn(i) {
+		i.errc = ErrCallbackFn
+		goto ERROR
+	}
+
+	/*</callback>*/
+	i.levelSel--
+	i.head++
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	if i.levelSel < 1 {
+		goto DEFINITION_END
+	}
+	goto AFTER_SELECTION
+	/*</l_sel_end>*/
+
+	/*<l_value>*/
+VALUE:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	switch i.str[i.head] {
+	case '#':
+		goto COMMENT
+
+	case '{':
+		// Object begin
+		i.tail = -1
+		// Callback for argument
+		i.token = TokenObj
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+		i.stackPush(TokenObj)
+		i.head++
+
+		/*<skip_irrelevant>*/
+		for {
+			if i.head+7 >= len(i.str) {
+				for i.head < len(i.str) {
+					if i.str[i.head] != ',' &&
+						i.str[i.head] != ' ' &&
+						i.str[i.head] != '\n' &&
+						i.str[i.head] != '\t' &&
+						i.str[i.head] != '\r' {
+						break
+					}
+					i.head++
+				}
+				break
+			}
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+		}
+		/*</skip_irrelevant>*/
+
+		i.expect = ExpectObjFieldName
+
+		/*<name>*/
+		// Followed by objfieldname>
+
+		/*<check_eof>*/
+		if i.head >= len(i.str) {
+			i.errc = ErrUnexpEOF
+			goto ERROR
+		}
+		/*</check_eof>*/
+
+		i.tail = i.head
+		if i.str[i.head] != '_' &&
+			(i.str[i.head] < 'a' || i.str[i.head] > 'z') &&
+			(i.str[i.head] < 'A' || i.str[i.head] > 'Z') {
+			i.errc = ErrUnexpToken
+			goto ERROR
+		}
+		i.head++
+		for {
+			if i.head+7 >= len(i.str) {
+				for ; i.head < len(i.str); i.head++ {
+					if i.str[i.head] == '_' ||
+						(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+						(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+						(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z') {
+						continue
+					} else if i.str[i.head] == ' ' ||
+						i.str[i.head] == '\n' ||
+						i.str[i.head] == '\r' ||
+						i.str[i.head] == '\t' ||
+						i.str[i.head] == ',' {
+						break
+					} else if i.str[i.head] < 0x20 {
+						i.errc = ErrUnexpToken
+						goto ERROR
+					}
+					break
+				}
+				break
+			}
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+		}
+
+		// <ExpectObjFieldName after name>
+		i.token = TokenObjField
+		i.objFields[len(i.objFields)-1] = Span{
+			Start: i.tail,
+			End:   i.head,
+		}
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+
+		/*<skip_irrelevant>*/
+		for {
+			if i.head+7 >= len(i.str) {
+				for i.head < len(i.str) {
+					if i.str[i.head] != ',' &&
+						i.str[i.head] != ' ' &&
+						i.str[i.head] != '\n' &&
+						i.str[i.head] != '\t' &&
+						i.str[i.head] != '\r' {
+						break
+					}
+					i.head++
+				}
+				break
+			}
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+		}
+		/*</skip_irrelevant>*/
+
+		/*<check_eof>*/
+		if i.head >= len(i.str) {
+			i.errc, i.expect = ErrUnexpEOF, ExpectColObjFieldName
+			goto ERROR
+		}
+		/*</check_eof>*/
+
+		if i.str[i.head] != ':' {
+			i.errc = ErrUnexpToken
+			i.expect = ExpectColObjFieldName
+			goto ERROR
+		}
+		i.head++
+
+		/*<skip_irrelevant>*/
+		for {
+			if i.head+7 >= len(i.str) {
+				for i.head < len(i.str) {
+					if i.str[i.head] != ',' &&
+						i.str[i.head] != ' ' &&
+						i.str[i.head] != '\n' &&
+						i.str[i.head] != '\t' &&
+						i.str[i.head] != '\r' {
+						break
+					}
+					i.head++
+				}
+				break
+			}
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+		}
+		/*</skip_irrelevant>*/
+
+		i.expect = ExpectVal
+		goto VALUE
+	// </ExpectObjFieldName after name>
+
+	/*</name>*/
+
+	case '[':
+		i.tail = -1
+		// Callback for argument
+		i.token = TokenArr
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+		i.head++
+
+		/*<skip_irrelevant>*/
+		for {
+			if i.head+7 >= len(i.str) {
+				for i.head < len(i.str) {
+					if i.str[i.head] != ',' &&
+						i.str[i.head] != ' ' &&
+						i.str[i.head] != '\n' &&
+						i.str[i.head] != '\t' &&
+						i.str[i.head] != '\r' {
+						break
+					}
+					i.head++
+				}
+				break
+			}
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+		}
+		/*</skip_irrelevant>*/
+
+		// Lookahead
+
+		/*<check_eof>*/
+		if i.head >= len(i.str) {
+			i.errc, i.expect = ErrUnexpEOF, ExpectVal
+			goto ERROR
+		}
+		/*</check_eof>*/
+
+		if i.str[i.head] == ']' {
+			i.token = TokenArrEnd
+			/*<callback>*/
+
+			if fn(i) {
+				i.errc = ErrCallbackFn
+				goto ERROR
+			}
+
+			/*</callback>*/
+			i.head++
+			i.expect = ExpectAfterValueInner
+			goto AFTER_VALUE_INNER
+		}
+		i.stackPush(TokenArr)
+		i.expect = ExpectAfterValueInner
+		goto AFTER_VALUE_INNER
+
+	case '"':
+
+		/*<str>*/
+		i.head++
+		i.tail = i.head
+
+		if i.head+1 < len(i.str) &&
+			i.str[i.head] == '"' &&
+			i.str[i.head+1] == '"' {
+			i.head += 2
+			i.tail = i.head
+			goto BLOCK_STRING
+		}
+
+		// String value
+		escaped := false
+		if i.head < len(i.str) && i.str[i.head] == '"' {
+			goto AFTER_STR_VAL
+		}
+		for {
+			for !escaped && i.head+7 < len(i.str) {
+				// Fast path
+				if i.str[i.head] == '"' ||
+					i.str[i.head] == '\\' ||
+					i.str[i.head] < 0x20 {
+					break
+				}
+				i.head++
+				if i.str[i.head] == '"' ||
+					i.str[i.head] == '\\' ||
+					i.str[i.head] < 0x20 {
+					break
+				}
+				i.head++
+				if i.str[i.head] == '"' ||
+					i.str[i.head] == '\\' ||
+					i.str[i.head] < 0x20 {
+					break
+				}
+				i.head++
+				if i.str[i.head] == '"' ||
+					i.str[i.head] == '\\' ||
+					i.str[i.head] < 0x20 {
+					break
+				}
+				i.head++
+				if i.str[i.head] == '"' ||
+					i.str[i.head] == '\\' ||
+					i.str[i.head] < 0x20 {
+					break
+				}
+				i.head++
+				if i.str[i.head] == '"' ||
+					i.str[i.head] == '\\' ||
+					i.str[i.head] < 0x20 {
+					break
+				}
+				i.head++
+				if i.str[i.head] == '"' ||
+					i.str[i.head] == '\\' ||
+					i.str[i.head] < 0x20 {
+					break
+				}
+				i.head++
+				if i.str[i.head] == '"' ||
+					i.str[i.head] == '\\' ||
+					i.str[i.head] < 0x20 {
+					break
+				}
+				i.head++
+			}
+			if i.head >= len(i.str) {
+				break
+			}
+			if i.str[i.head] < 0x20 {
+				i.errc = ErrUnexpToken
+				i.expect = ExpectEndOfString
+				goto ERROR
+			}
+			if escaped {
+				switch i.str[i.head] {
+				case '\\':
+					// Backslash
+					i.head++
+				case '/':
+					// Solidus
+					i.head++
+				case '"':
+					// Double-quotes
+					i.head++
+				case 'b':
+					// Backspace
+					i.head++
+				case 'f':
+					// Form-feed
+					i.head++
+				case 'r':
+					// Carriage-return
+					i.head++
+				case 'n':
+					// Line-break
+					i.head++
+				case 't':
+					// Tab
+					i.head++
+				case 'u':
+					// Unicode sequence
+					i.head++
+
+					/*<check_eof>*/
+					if i.head >= len(i.str) {
+						i.errc, i.expect = ErrUnexpEOF, ExpectEscapedUnicodeSequence
+						goto ERROR
+					}
+					/*</check_eof>*/
+
+					if !i.isHeadHexDigit() {
+						i.errc = ErrUnexpToken
+						i.expect = ExpectEscapedUnicodeSequence
+						goto ERROR
+					}
+					i.head++
+
+					/*<check_eof>*/
+					if i.head >= len(i.str) {
+						i.errc, i.expect = ErrUnexpEOF, ExpectEscapedUnicodeSequence
+						goto ERROR
+					}
+					/*</check_eof>*/
+
+					if !i.isHeadHexDigit() {
+						i.errc = ErrUnexpToken
+						i.expect = ExpectEscapedUnicodeSequence
+						goto ERROR
+					}
+					i.head++
+
+					/*<check_eof>*/
+					if i.head >= len(i.str) {
+						i.errc, i.expect = ErrUnexpEOF, ExpectEscapedUnicodeSequence
+						goto ERROR
+					}
+					/*</check_eof>*/
+
+					if !i.isHeadHexDigit() {
+						i.errc = ErrUnexpToken
+						i.expect = ExpectEscapedUnicodeSequence
+						goto ERROR
+					}
+					i.head++
+
+					/*<check_eof>*/
+					if i.head >= len(i.str) {
+						i.errc, i.expect = ErrUnexpEOF, ExpectEscapedUnicodeSequence
+						goto ERROR
+					}
+					/*</check_eof>*/
+
+					if !i.isHeadHexDigit() {
+						i.errc = ErrUnexpToken
+						i.expect = ExpectEscapedUnicodeSequence
+						goto ERROR
+					}
+				default:
+					i.errc = ErrUnexpToken
+					i.expect = ExpectEscapedSequence
+					goto ERROR
+				}
+				escaped = false
+				continue
+			} else if i.str[i.head] == '"' {
+				goto AFTER_STR_VAL
+			} else if i.str[i.head] == '\\' {
+				escaped = true
+			}
+			i.head++
+		}
+		i.errc = ErrUnexpEOF
+		i.expect = ExpectEndOfString
+		goto ERROR
+
+	AFTER_STR_VAL:
+		// Callback for argument
+		i.token = TokenStr
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+		// Advance head index to include the closing double-quotes
+		i.head++
+	/*</str>*/
+
+	case '$':
+		if inDefVal {
+			i.errc, i.expect = ErrUnexpToken, ExpectDefaultVarVal
+			goto ERROR
+		}
+
+		// Variable reference
+		i.head++
+
+		// Variable name
+		i.expect = ExpectVarRefName
+		goto VAR_REF_NAME
+
+	case 'n':
+
+		/*<null>*/
+		if i.head+4 < len(i.str) &&
+			i.str[i.head+3] == 'l' &&
+			i.str[i.head+2] == 'l' &&
+			i.str[i.head+1] == 'u' &&
+			i.str[i.head] == 'n' &&
+			(i.str[i.head+4] == ' ' ||
+				i.str[i.head+4] == '\t' ||
+				i.str[i.head+4] == '\r' ||
+				i.str[i.head+4] == '\n' ||
+				i.str[i.head+4] == ',' ||
+				i.str[i.head+4] == ')' ||
+				i.str[i.head+4] == '}' ||
+				i.str[i.head+4] == '{' ||
+				i.str[i.head+4] == ']' ||
+				i.str[i.head+4] == '[' ||
+				i.str[i.head+4] == '#') {
+			i.tail = -1
+			i.head += len("null")
+
+			// Callback for null value
+			i.token = TokenNull
+			/*<callback>*/
+
+			if fn(i) {
+				i.errc = ErrCallbackFn
+				goto ERROR
+			}
+
+			/*</callback>*/
+		} else {
+			i.expect = ExpectValEnum
+
+			/*<name>*/
+			// Followed by valenum>
+
+			/*<check_eof>*/
+			if i.head >= len(i.str) {
+				i.errc = ErrUnexpEOF
+				goto ERROR
+			}
+			/*</check_eof>*/
+
+			i.tail = i.head
+			if i.str[i.head] != '_' &&
+				(i.str[i.head] < 'a' || i.str[i.head] > 'z') &&
+				(i.str[i.head] < 'A' || i.str[i.head] > 'Z') {
+				i.errc = ErrUnexpToken
+				goto ERROR
+			}
+			i.head++
+			for {
+				if i.head+7 >= len(i.str) {
+					for ; i.head < len(i.str); i.head++ {
+						if i.str[i.head] == '_' ||
+							(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+							(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+							(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z') {
+							continue
+						} else if i.str[i.head] == ' ' ||
+							i.str[i.head] == '\n' ||
+							i.str[i.head] == '\r' ||
+							i.str[i.head] == '\t' ||
+							i.str[i.head] == ',' {
+							break
+						} else if i.str[i.head] < 0x20 {
+							i.errc = ErrUnexpToken
+							goto ERROR
+						}
+						break
+					}
+					break
+				}
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+			}
+
+			// <ExpectValEnum after name>
+			i.token = TokenEnumVal
+			/*<callback>*/
+
+			if fn(i) {
+				i.errc = ErrCallbackFn
+				goto ERROR
+			}
+
+			/*</callback>*/
+			i.expect = ExpectAfterValueInner
+			goto AFTER_VALUE_INNER
+			// </ExpectValEnum after name>
+
+			/*</name>*/
+
+		}
+	/*</null>*/
+
+	case 't':
+
+		/*<true>*/
+		if i.head+4 < len(i.str) &&
+			i.str[i.head+3] == 'e' &&
+			i.str[i.head+2] == 'u' &&
+			i.str[i.head+1] == 'r' &&
+			i.str[i.head] == 't' &&
+			(i.str[i.head+4] == ' ' ||
+				i.str[i.head+4] == '\t' ||
+				i.str[i.head+4] == '\r' ||
+				i.str[i.head+4] == '\n' ||
+				i.str[i.head+4] == ',' ||
+				i.str[i.head+4] == ')' ||
+				i.str[i.head+4] == '}' ||
+				i.str[i.head+4] == '{' ||
+				i.str[i.head+4] == ']' ||
+				i.str[i.head+4] == '[' ||
+				i.str[i.head+4] == '#') {
+			i.tail = -1
+			i.head += len("true")
+
+			// Callback for true value
+			i.token = TokenTrue
+			/*<callback>*/
+
+			if fn(i) {
+				i.errc = ErrCallbackFn
+				goto ERROR
+			}
+
+			/*</callback>*/
+		} else {
+			i.expect = ExpectValEnum
+
+			/*<name>*/
+			// Followed by valenum>
+
+			/*<check_eof>*/
+			if i.head >= len(i.str) {
+				i.errc = ErrUnexpEOF
+				goto ERROR
+			}
+			/*</check_eof>*/
+
+			i.tail = i.head
+			if i.str[i.head] != '_' &&
+				(i.str[i.head] < 'a' || i.str[i.head] > 'z') &&
+				(i.str[i.head] < 'A' || i.str[i.head] > 'Z') {
+				i.errc = ErrUnexpToken
+				goto ERROR
+			}
+			i.head++
+			for {
+				if i.head+7 >= len(i.str) {
+					for ; i.head < len(i.str); i.head++ {
+						if i.str[i.head] == '_' ||
+							(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+							(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+							(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z') {
+							continue
+						} else if i.str[i.head] == ' ' ||
+							i.str[i.head] == '\n' ||
+							i.str[i.head] == '\r' ||
+							i.str[i.head] == '\t' ||
+							i.str[i.head] == ',' {
+							break
+						} else if i.str[i.head] < 0x20 {
+							i.errc = ErrUnexpToken
+							goto ERROR
+						}
+						break
+					}
+					break
+				}
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+			}
+
+			// <ExpectValEnum after name>
+			i.token = TokenEnumVal
+			/*<callback>*/
+
+			if fn(i) {
+				i.errc = ErrCallbackFn
+				goto ERROR
+			}
+
+			/*</callback>*/
+			i.expect = ExpectAfterValueInner
+			goto AFTER_VALUE_INNER
+			// </ExpectValEnum after name>
+
+			/*</name>*/
+
+		}
+	/*</true>*/
+
+	case 'f':
+
+		/*<false>*/
+		if i.head+5 < len(i.str) &&
+			i.str[i.head+4] == 'e' &&
+			i.str[i.head+3] == 's' &&
+			i.str[i.head+2] == 'l' &&
+			i.str[i.head+1] == 'a' &&
+			i.str[i.head] == 'f' &&
+			(i.str[i.head+5] == ' ' ||
+				i.str[i.head+5] == '\t' ||
+				i.str[i.head+5] == '\r' ||
+				i.str[i.head+5] == '\n' ||
+				i.str[i.head+5] == ',' ||
+				i.str[i.head+5] == ')' ||
+				i.str[i.head+5] == '}' ||
+				i.str[i.head+5] == '{' ||
+				i.str[i.head+5] == ']' ||
+				i.str[i.head+5] == '[' ||
+				i.str[i.head+5] == '#') {
+			i.tail = -1
+			i.head += len("false")
+
+			// Callback for false value
+			i.token = TokenFalse
+			/*<callback>*/
+
+			if fn(i) {
+				i.errc = ErrCallbackFn
+				goto ERROR
+			}
+
+			/*</callback>*/
+		} else {
+			i.expect = ExpectValEnum
+
+			/*<name>*/
+			// Followed by valenum>
+
+			/*<check_eof>*/
+			if i.head >= len(i.str) {
+				i.errc = ErrUnexpEOF
+				goto ERROR
+			}
+			/*</check_eof>*/
+
+			i.tail = i.head
+			if i.str[i.head] != '_' &&
+				(i.str[i.head] < 'a' || i.str[i.head] > 'z') &&
+				(i.str[i.head] < 'A' || i.str[i.head] > 'Z') {
+				i.errc = ErrUnexpToken
+				goto ERROR
+			}
+			i.head++
+			for {
+				if i.head+7 >= len(i.str) {
+					for ; i.head < len(i.str); i.head++ {
+						if i.str[i.head] == '_' ||
+							(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+							(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+							(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z') {
+							continue
+						} else if i.str[i.head] == ' ' ||
+							i.str[i.head] == '\n' ||
+							i.str[i.head] == '\r' ||
+							i.str[i.head] == '\t' ||
+							i.str[i.head] == ',' {
+							break
+						} else if i.str[i.head] < 0x20 {
+							i.errc = ErrUnexpToken
+							goto ERROR
+						}
+						break
+					}
+					break
+				}
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+			}
+
+			// <ExpectValEnum after name>
+			i.token = TokenEnumVal
+			/*<callback>*/
+
+			if fn(i) {
+				i.errc = ErrCallbackFn
+				goto ERROR
+			}
+
+			/*</callback>*/
+			i.expect = ExpectAfterValueInner
+			goto AFTER_VALUE_INNER
+			// </ExpectValEnum after name>
+
+			/*</name>*/
+
+		}
+	/*</false>*/
+
+	case '+', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+
+		/*<num>*/
+		// Number
+		i.tail = i.head
+
+		var s int
+
+		switch i.str[i.head] {
+		case '-':
+			// Signed
+			i.head++
+
+			/*<check_eof>*/
+			if i.head >= len(i.str) {
+				i.errc, i.expect = ErrUnexpEOF, ExpectVal
+				goto ERROR
+			}
+		/*</check_eof>*/
+
+		case '0':
+			// Leading zero
+			i.head++
+			if i.head < len(i.str) {
+				if i.str[i.head] == '.' {
+					i.head++
+					goto FRACTION
+				} else if i.str[i.head] == 'e' || i.str[i.head] == 'E' {
+					i.head++
+					goto EXPONENT_SIGN
+				} else if i.isHeadNumEnd() {
+					i.token = TokenInt
+					goto ON_NUM_VAL
+				} else {
+					i.errc = ErrInvalNum
+					i.expect = ExpectVal
+					goto ERROR
+				}
+			}
+		}
+
+		// Integer
+		for s = i.head; i.head < len(i.str); i.head++ {
+			if i.isHeadDigit() {
+				continue
+			} else if i.str[i.head] == '.' {
+				i.head++
+				goto FRACTION
+			} else if i.isHeadNumEnd() {
+				if i.head == s {
+					// Expected at least one digit
+					i.errc = ErrInvalNum
+					i.expect = ExpectVal
+					goto ERROR
+				}
+				// Integer
+				i.token = TokenInt
+				goto ON_NUM_VAL
+			} else if i.str[i.head] == 'e' || i.str[i.head] == 'E' {
+				i.head++
+				goto EXPONENT_SIGN
+			}
+
+			// Unexpected rune
+			i.errc = ErrInvalNum
+			i.expect = ExpectVal
+			goto ERROR
+		}
+
+		if i.head >= len(i.str) {
+			// Integer without exponent
+			i.token = TokenInt
+			goto ON_NUM_VAL
+		}
+		// Continue to fraction
+
+	FRACTION:
+		_ = 0 // Make code coverage count the label above
+		for s = i.head; i.head < len(i.str); i.head++ {
+			if i.isHeadDigit() {
+				continue
+			} else if i.isHeadNumEnd() {
+				if i.head == s {
+					// Expected at least one digit
+					i.errc = ErrInvalNum
+					i.expect = ExpectVal
+					goto ERROR
+				}
+				// Number with fraction
+				i.token = TokenFloat
+				goto ON_NUM_VAL
+			} else if i.str[i.head] == 'e' || i.str[i.head] == 'E' {
+				i.head++
+				goto EXPONENT_SIGN
+			}
+
+			// Unexpected rune
+			i.errc = ErrInvalNum
+			i.expect = ExpectVal
+			goto ERROR
+		}
+		if s == i.head {
+			// Unexpected end of number
+			i.errc = ErrUnexpEOF
+			i.expect = ExpectVal
+			goto ERROR
+		}
+
+		if i.head >= len(i.str) {
+			// Number (with fraction but) without exponent
+			i.token = TokenFloat
+			goto ON_NUM_VAL
+		}
+
+	EXPONENT_SIGN:
+
+		/*<check_eof>*/
+		if i.head >= len(i.str) {
+			i.errc, i.expect = ErrUnexpEOF, ExpectVal
+			goto ERROR
+		}
+		/*</check_eof>*/
+
+		if i.str[i.head] == '-' || i.str[i.head] == '+' {
+			i.head++
+		}
+		for s = i.head; i.head < len(i.str); i.head++ {
+			if i.isHeadDigit() {
+				continue
+			} else if i.isHeadNumEnd() {
+				if i.head == s {
+					// Expected at least one digit
+					i.errc = ErrInvalNum
+					i.expect = ExpectVal
+					goto ERROR
+				}
+				// Number with (fraction and) exponent
+				i.token = TokenFloat
+				goto ON_NUM_VAL
+			}
+			break
+		}
+		// Unexpected rune
+		i.errc = ErrInvalNum
+		i.expect = ExpectVal
+		goto ERROR
+
+	ON_NUM_VAL:
+		// Callback for argument
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+	/*</callback>*/
+	/*</num>*/
+
+	default:
+		// Invalid value
+		i.expect = ExpectValEnum
+
+		/*<name>*/
+		// Followed by valenum>
+
+		/*<check_eof>*/
+		if i.head >= len(i.str) {
+			i.errc = ErrUnexpEOF
+			goto ERROR
+		}
+		/*</check_eof>*/
+
+		i.tail = i.head
+		if i.str[i.head] != '_' &&
+			(i.str[i.head] < 'a' || i.str[i.head] > 'z') &&
+			(i.str[i.head] < 'A' || i.str[i.head] > 'Z') {
+			i.errc = ErrUnexpToken
+			goto ERROR
+		}
+		i.head++
+		for {
+			if i.head+7 >= len(i.str) {
+				for ; i.head < len(i.str); i.head++ {
+					if i.str[i.head] == '_' ||
+						(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+						(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+						(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z') {
+						continue
+					} else if i.str[i.head] == ' ' ||
+						i.str[i.head] == '\n' ||
+						i.str[i.head] == '\r' ||
+						i.str[i.head] == '\t' ||
+						i.str[i.head] == ',' {
+						break
+					} else if i.str[i.head] < 0x20 {
+						i.errc = ErrUnexpToken
+						goto ERROR
+					}
+					break
+				}
+				break
+			}
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+		}
+
+		// <ExpectValEnum after name>
+		i.token = TokenEnumVal
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+		i.expect = ExpectAfterValueInner
+		goto AFTER_VALUE_INNER
+		// </ExpectValEnum after name>
+
+		/*</name>*/
+
+	}
+	i.expect = ExpectAfterValueInner
+	goto AFTER_VALUE_INNER
+	/*</l_value>*/
+
+	/*<l_block_string>*/
+BLOCK_STRING:
+	i.expect = ExpectEndOfBlockString
+	for {
+		for i.head+7 < len(i.str) {
+			if i.str[i.head] == '\\' ||
+				i.str[i.head] == '"' ||
+				(i.str[i.head] < 0x20 &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\r') {
+				break
+			}
+			i.head++
+			if i.str[i.head] == '\\' ||
+				i.str[i.head] == '"' ||
+				(i.str[i.head] < 0x20 &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\r') {
+				break
+			}
+			i.head++
+			if i.str[i.head] == '\\' ||
+				i.str[i.head] == '"' ||
+				(i.str[i.head] < 0x20 &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\r') {
+				break
+			}
+			i.head++
+			if i.str[i.head] == '\\' ||
+				i.str[i.head] == '"' ||
+				(i.str[i.head] < 0x20 &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\r') {
+				break
+			}
+			i.head++
+			if i.str[i.head] == '\\' ||
+				i.str[i.head] == '"' ||
+				(i.str[i.head] < 0x20 &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\r') {
+				break
+			}
+			i.head++
+			if i.str[i.head] == '\\' ||
+				i.str[i.head] == '"' ||
+				(i.str[i.head] < 0x20 &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\r') {
+				break
+			}
+			i.head++
+			if i.str[i.head] == '\\' ||
+				i.str[i.head] == '"' ||
+				(i.str[i.head] < 0x20 &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\r') {
+				break
+			}
+			i.head++
+			if i.str[i.head] == '\\' ||
+				i.str[i.head] == '"' ||
+				(i.str[i.head] < 0x20 &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\r') {
+				break
+			}
+			i.head++
+		}
+
+		/*<check_eof>*/
+		if i.head >= len(i.str) {
+			i.errc = ErrUnexpEOF
+			goto ERROR
+		}
+		/*</check_eof>*/
+
+		if i.str[i.head] == '\\' &&
+			i.head+3 < len(i.str) &&
+			i.str[i.head+3] == '"' &&
+			i.str[i.head+2] == '"' &&
+			i.str[i.head+1] == '"' {
+			i.head += len(`\"""`)
+			continue
+		} else if i.str[i.head] == '"' &&
+			i.head+2 < len(i.str) &&
+			i.str[i.head+2] == '"' &&
+			i.str[i.head+1] == '"' {
+			i.token = TokenStrBlock
+			/*<callback>*/
+
+			if fn(i) {
+				i.errc = ErrCallbackFn
+				goto ERROR
+			}
+
+			/*</callback>*/
+			i.head += len(`"""`)
+			i.expect = ExpectAfterValueInner
+			goto AFTER_VALUE_INNER
+		} else if i.str[i.head] < 0x20 &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\r' {
+			i.errc = ErrUnexpToken
+			goto ERROR
+		}
+		i.head++
+	}
+	/*</l_block_string>*/
+
+	/*<l_after_value_inner>*/
+AFTER_VALUE_INNER:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if i.str[i.head] == '#' {
+		goto COMMENT
+	}
+	if t := i.stackTop(); t == TokenObj {
+		if i.str[i.head] == '}' {
+			i.tail = -1
+			i.stackPop()
+
+			// Callback for end of object
+			i.token = TokenObjEnd
+			/*<callback>*/
+
+			if fn(i) {
+				i.errc = ErrCallbackFn
+				goto ERROR
+			}
+
+			/*</callback>*/
+			i.head++
+
+			/*<skip_irrelevant>*/
+			for {
+				if i.head+7 >= len(i.str) {
+					for i.head < len(i.str) {
+						if i.str[i.head] != ',' &&
+							i.str[i.head] != ' ' &&
+							i.str[i.head] != '\n' &&
+							i.str[i.head] != '\t' &&
+							i.str[i.head] != '\r' {
+							break
+						}
+						i.head++
+					}
+					break
+				}
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			/*</skip_irrelevant>*/
+
+			if i.stackLen() > 0 {
+				i.expect = ExpectAfterValueInner
+				goto AFTER_VALUE_INNER
+			}
+		} else {
+			// Proceed to next field in the object
+			i.expect = ExpectObjFieldName
+
+			/*<name>*/
+			// Followed by objfieldname>
+
+			/*<check_eof>*/
+			if i.head >= len(i.str) {
+				i.errc = ErrUnexpEOF
+				goto ERROR
+			}
+			/*</check_eof>*/
+
+			i.tail = i.head
+			if i.str[i.head] != '_' &&
+				(i.str[i.head] < 'a' || i.str[i.head] > 'z') &&
+				(i.str[i.head] < 'A' || i.str[i.head] > 'Z') {
+				i.errc = ErrUnexpToken
+				goto ERROR
+			}
+			i.head++
+			for {
+				if i.head+7 >= len(i.str) {
+					for ; i.head < len(i.str); i.head++ {
+						if i.str[i.head] == '_' ||
+							(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+							(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+							(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z') {
+							continue
+						} else if i.str[i.head] == ' ' ||
+							i.str[i.head] == '\n' ||
+							i.str[i.head] == '\r' ||
+							i.str[i.head] == '\t' ||
+							i.str[i.head] == ',' {
+							break
+						} else if i.str[i.head] < 0x20 {
+							i.errc = ErrUnexpToken
+							goto ERROR
+						}
+						break
+					}
+					break
+				}
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+			}
+
+			// <ExpectObjFieldName after name>
+			i.token = TokenObjField
+			i.objFields[len(i.objFields)-1] = Span{
+				Start: i.tail,
+				End:   i.head,
+			}
+			/*<callback>*/
+
+			if fn(i) {
+				i.errc = ErrCallbackFn
+				goto ERROR
+			}
+
+			/*</callback>*/
+
+			/*<skip_irrelevant>*/
+			for {
+				if i.head+7 >= len(i.str) {
+					for i.head < len(i.str) {
+						if i.str[i.head] != ',' &&
+							i.str[i.head] != ' ' &&
+							i.str[i.head] != '\n' &&
+							i.str[i.head] != '\t' &&
+							i.str[i.head] != '\r' {
+							break
+						}
+						i.head++
+					}
+					break
+				}
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			/*</skip_irrelevant>*/
+
+			/*<check_eof>*/
+			if i.head >= len(i.str) {
+				i.errc, i.expect = ErrUnexpEOF, ExpectColObjFieldName
+				goto ERROR
+			}
+			/*</check_eof>*/
+
+			if i.str[i.head] != ':' {
+				i.errc = ErrUnexpToken
+				i.expect = ExpectColObjFieldName
+				goto ERROR
+			}
+			i.head++
+
+			/*<skip_irrelevant>*/
+			for {
+				if i.head+7 >= len(i.str) {
+					for i.head < len(i.str) {
+						if i.str[i.head] != ',' &&
+							i.str[i.head] != ' ' &&
+							i.str[i.head] != '\n' &&
+							i.str[i.head] != '\t' &&
+							i.str[i.head] != '\r' {
+							break
+						}
+						i.head++
+					}
+					break
+				}
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			/*</skip_irrelevant>*/
+
+			i.expect = ExpectVal
+			goto VALUE
+			// </ExpectObjFieldName after name>
+
+			/*</name>*/
+
+		}
+	} else if t == TokenArr {
+		if i.str[i.head] == ']' {
+			i.tail = -1
+			i.stackPop()
+
+			// Callback for end of array
+			i.token = TokenArrEnd
+			/*<callback>*/
+
+			if fn(i) {
+				i.errc = ErrCallbackFn
+				goto ERROR
+			}
+
+			/*</callback>*/
+			i.head++
+
+			/*<skip_irrelevant>*/
+			for {
+				if i.head+7 >= len(i.str) {
+					for i.head < len(i.str) {
+						if i.str[i.head] != ',' &&
+							i.str[i.head] != ' ' &&
+							i.str[i.head] != '\n' &&
+							i.str[i.head] != '\t' &&
+							i.str[i.head] != '\r' {
+							break
+						}
+						i.head++
+					}
+					break
+				}
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			/*</skip_irrelevant>*/
+
+			if i.stackLen() > 0 {
+				i.expect = ExpectAfterValueInner
+				goto AFTER_VALUE_INNER
+			}
+		} else {
+			// Proceed to next value in the array
+			goto VALUE
+		}
+	}
+	goto AFTER_VALUE_OUTER
+	/*</l_after_value_inner>*/
+
+	/*<l_after_value_outer>*/
+AFTER_VALUE_OUTER:
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if inDefVal {
+		switch i.str[i.head] {
+		case ')':
+			inDefVal = false
+			goto VAR_LIST_END
+		case '@':
+			inDefVal = false
+			i.head++
+			dirOn, i.expect = dirVar, ExpectDir
+			goto DIR_NAME
+		case '#':
+			goto COMMENT
+		}
+		inDefVal = false
+		i.expect = ExpectVar
+		goto OPR_VAR
+	}
+
+	if i.str[i.head] == ')' {
+		// End of argument list
+		i.tail = -1
+		i.token = TokenArgListEnd
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+		i.head++
+		i.expect = ExpectAfterArgList
+		goto AFTER_ARG_LIST
+	}
+
+	// Proceed to the next argument
+	i.expect = ExpectArgName
+
+	/*<name>*/
+	// Followed by argname>
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	i.tail = i.head
+	if i.str[i.head] != '_' &&
+		(i.str[i.head] < 'a' || i.str[i.head] > 'z') &&
+		(i.str[i.head] < 'A' || i.str[i.head] > 'Z') {
+		i.errc = ErrUnexpToken
+		goto ERROR
+	}
+	i.head++
+	for {
+		if i.head+7 >= len(i.str) {
+			for ; i.head < len(i.str); i.head++ {
+				if i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z') {
+					continue
+				} else if i.str[i.head] == ' ' ||
+					i.str[i.head] == '\n' ||
+					i.str[i.head] == '\r' ||
+					i.str[i.head] == '\t' ||
+					i.str[i.head] == ',' {
+					break
+				} else if i.str[i.head] < 0x20 {
+					i.errc = ErrUnexpToken
+					goto ERROR
+				}
+				break
+			}
+			break
+		}
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+	}
+
+	// <ExpectArgName after name>
+	i.token = TokenArgName
+	/*<callback>*/
+
+	if fn(i) {
+		i.errc = ErrCallbackFn
+		goto ERROR
+	}
+
+	/*</callback>*/
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	i.expect = ExpectColumnAfterArg
+	goto COLUMN_AFTER_ARG_NAME
+	// </ExpectArgName after name>
+
+	/*</name>*/
+
+	/*</l_after_value_outer>*/
+
+	/*<l_after_arg_list>*/
+AFTER_ARG_LIST:
+	if dirOn != 0 {
+		goto AFTER_DIR_ARGS
+	}
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if i.str[i.head] == '#' {
+		goto COMMENT
+	}
+
+	if i.str[i.head] == '{' {
+		i.expect = ExpectSelSet
+		goto SELECTION_SET
+	} else if i.str[i.head] == '}' {
+		i.expect = ExpectAfterSelection
+		goto AFTER_SELECTION
+	} else if i.str[i.head] == '@' {
+		i.head++
+		dirOn, i.expect = dirField, ExpectDir
+		goto DIR_NAME
+	}
+	i.expect = ExpectSel
+	goto SELECTION
+	/*</l_after_arg_list>*/
+
+	/*<l_selection>*/
+SELECTION:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc, i.expect = ErrUnexpEOF, ExpectSel
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if i.str[i.head] == '#' {
+		i.expect = ExpectSel
+		goto COMMENT
+	} else if i.str[i.head] != '.' {
+		// Field selection
+		i.expect = ExpectFieldNameOrAlias
+
+		/*<name>*/
+		// Followed by fieldnameoralias>
+
+		/*<check_eof>*/
+		if i.head >= len(i.str) {
+			i.errc = ErrUnexpEOF
+			goto ERROR
+		}
+		/*</check_eof>*/
+
+		i.tail = i.head
+		if i.str[i.head] != '_' &&
+			(i.str[i.head] < 'a' || i.str[i.head] > 'z') &&
+			(i.str[i.head] < 'A' || i.str[i.head] > 'Z') {
+			i.errc = ErrUnexpToken
+			goto ERROR
+		}
+		i.head++
+		for {
+			if i.head+7 >= len(i.str) {
+				for ; i.head < len(i.str); i.head++ {
+					if i.str[i.head] == '_' ||
+						(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+						(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+						(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z') {
+						continue
+					} else if i.str[i.head] == ' ' ||
+						i.str[i.head] == '\n' ||
+						i.str[i.head] == '\r' ||
+						i.str[i.head] == '\t' ||
+						i.str[i.head] == ',' {
+						break
+					} else if i.str[i.head] < 0x20 {
+						i.errc = ErrUnexpToken
+						goto ERROR
+					}
+					break
+				}
+				break
+			}
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+			if !(i.str[i.head] == '_' ||
+				(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+				(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+				(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+				break
+			}
+			i.head++
+		}
+
+		// <ExpectFieldNameOrAlias after name>
+		head := i.head
+
+		/*<skip_irrelevant>*/
+		for {
+			if i.head+7 >= len(i.str) {
+				for i.head < len(i.str) {
+					if i.str[i.head] != ',' &&
+						i.str[i.head] != ' ' &&
+						i.str[i.head] != '\n' &&
+						i.str[i.head] != '\t' &&
+						i.str[i.head] != '\r' {
+						break
+					}
+					i.head++
+				}
+				break
+			}
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+		}
+		/*</skip_irrelevant>*/
+
+		/*<check_eof>*/
+		if i.head >= len(i.str) {
+			i.errc = ErrUnexpEOF
+			goto ERROR
+		}
+		/*</check_eof>*/
+
+		if i.str[i.head] == ':' {
+			h2 := i.head
+			i.head = head
+			i.token = TokenFieldAlias
+			/*<callback>*/
+
+			if fn(i) {
+				i.errc = ErrCallbackFn
+				goto ERROR
+			}
+
+			/*</callback>*/
+			i.head = h2 + 1
+
+			/*<skip_irrelevant>*/
+			for {
+				if i.head+7 >= len(i.str) {
+					for i.head < len(i.str) {
+						if i.str[i.head] != ',' &&
+							i.str[i.head] != ' ' &&
+							i.str[i.head] != '\n' &&
+							i.str[i.head] != '\t' &&
+							i.str[i.head] != '\r' {
+							break
+						}
+						i.head++
+					}
+					break
+				}
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			/*</skip_irrelevant>*/
+
+			i.expect = ExpectFieldName
+
+			/*<name>*/
+			// Followed by fieldname>
+
+			/*<check_eof>*/
+			if i.head >= len(i.str) {
+				i.errc = ErrUnexpEOF
+				goto ERROR
+			}
+			/*</check_eof>*/
+
+			i.tail = i.head
+			if i.str[i.head] != '_' &&
+				(i.str[i.head] < 'a' || i.str[i.head] > 'z') &&
+				(i.str[i.head] < 'A' || i.str[i.head] > 'Z') {
+				i.errc = ErrUnexpToken
+				goto ERROR
+			}
+			i.head++
+			for {
+				if i.head+7 >= len(i.str) {
+					for ; i.head < len(i.str); i.head++ {
+						if i.str[i.head] == '_' ||
+							(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+							(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+							(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z') {
+							continue
+						} else if i.str[i.head] == ' ' ||
+							i.str[i.head] == '\n' ||
+							i.str[i.head] == '\r' ||
+							i.str[i.head] == '\t' ||
+							i.str[i.head] == ',' {
+							break
+						} else if i.str[i.head] < 0x20 {
+							i.errc = ErrUnexpToken
+							goto ERROR
+						}
+						break
+					}
+					break
+				}
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+				if !(i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+					break
+				}
+				i.head++
+			}
+
+			// <ExpectFieldName after name>
+			i.token = TokenField
+			/*<callback>*/
+
+			if fn(i) {
+				i.errc = ErrCallbackFn
+				goto ERROR
+			}
+
+			/*</callback>*/
+			goto AFTER_FIELD_NAME
+			// </ExpectFieldName after name>
+
+			/*</name>*/
+
+		}
+		i.head = head
+		i.token = TokenField
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+		goto AFTER_FIELD_NAME
+		// </ExpectFieldNameOrAlias after name>
+
+		/*</name>*/
+
+	}
+
+	i.expect = ExpectFrag
+	if i.head+2 >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		if i.head+1 >= len(i.str) {
+			i.head++
+		} else {
+			i.head += 2
+		}
+		goto ERROR
+	} else if i.str[i.head+2] != '.' ||
+		i.str[i.head+1] != '.' {
+		i.errc = ErrUnexpToken
+		if i.str[i.head+1] != '.' {
+			i.head += 1
+		} else if i.str[i.head+2] != '.' {
+			i.head += 2
+		}
+		goto ERROR
+	}
+
+	i.head += len("...")
+	goto SPREAD
+	/*</l_selection>*/
+
+	/*<l_spread>*/
+SPREAD:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	if i.head+1 >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	} else if i.str[i.head] == '#' {
+		goto COMMENT
+	} else if i.str[i.head] == '{' {
+		i.token, i.tail = TokenFragInline, -1
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+		i.expect = ExpectSelSet
+		goto SELECTION_SET
+	} else if i.str[i.head] == '@' {
+		i.token, i.tail = TokenFragInline, -1
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+		i.expect, dirOn = ExpectDirName, dirFragInlineOrDef
+		goto AFTER_DIR_NAME
+	} else if i.str[i.head+1] == 'n' &&
+		i.str[i.head] == 'o' {
+		if i.head+2 >= len(i.str) {
+			i.head = len(i.str)
+			i.errc = ErrUnexpEOF
+			goto ERROR
+		} else if i.str[i.head+2] == ' ' ||
+			i.str[i.head+2] == '\n' ||
+			i.str[i.head+2] == '\r' ||
+			i.str[i.head+2] == '\t' ||
+			i.str[i.head+2] == ',' ||
+			i.str[i.head+2] == '#' {
+			// ... on Type {
+			i.head += len("on")
+			i.expect = ExpectFragInlined
+			goto FRAG_INLINED
+		}
+	}
+	// ...fragmentName
+	i.expect = ExpectSpreadName
+
+	/*<name>*/
+	// Followed by spreadname>
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	i.tail = i.head
+	if i.str[i.head] != '_' &&
+		(i.str[i.head] < 'a' || i.str[i.head] > 'z') &&
+		(i.str[i.head] < 'A' || i.str[i.head] > 'Z') {
+		i.errc = ErrUnexpToken
+		goto ERROR
+	}
+	i.head++
+	for {
+		if i.head+7 >= len(i.str) {
+			for ; i.head < len(i.str); i.head++ {
+				if i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z') {
+					continue
+				} else if i.str[i.head] == ' ' ||
+					i.str[i.head] == '\n' ||
+					i.str[i.head] == '\r' ||
+					i.str[i.head] == '\t' ||
+					i.str[i.head] == ',' {
+					break
+				} else if i.str[i.head] < 0x20 {
+					i.errc = ErrUnexpToken
+					goto ERROR
+				}
+				break
+			}
+			break
+		}
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+	}
+
+	// <ExpectSpreadName after name>
+	i.token = TokenNamedSpread
+	/*<callback>*/
+
+	if fn(i) {
+		i.errc = ErrCallbackFn
+		goto ERROR
+	}
+
+	/*</callback>*/
+	i.expect, dirOn = ExpectDirName, dirFragRef
+	goto AFTER_DIR_NAME
+	// </ExpectSpreadName after name>
+
+	/*</name>*/
+
+	/*</l_spread>*/
+
+	/*<l_after_decl_varname>*/
+AFTER_DECL_VAR_NAME:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if i.str[i.head] == '#' {
+		goto COMMENT
+	} else if i.str[i.head] != ':' {
+		i.errc = ErrUnexpToken
+		goto ERROR
+	}
+	i.head++
+	i.expect = ExpectVarType
+	goto VAR_TYPE
+	/*</l_after_decl_varname>*/
+
+	/*<l_var_type>*/
+VAR_TYPE:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if i.str[i.head] == '#' {
+		goto COMMENT
+	} else if i.str[i.head] == '[' {
+		i.tail = -1
+		i.token = TokenVarTypeArr
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+		i.head++
+		typeArrLvl++
+		goto VAR_TYPE
+	}
+	i.expect = ExpectVarType
+
+	/*<name>*/
+	// Followed by vartype>
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	i.tail = i.head
+	if i.str[i.head] != '_' &&
+		(i.str[i.head] < 'a' || i.str[i.head] > 'z') &&
+		(i.str[i.head] < 'A' || i.str[i.head] > 'Z') {
+		i.errc = ErrUnexpToken
+		goto ERROR
+	}
+	i.head++
+	for {
+		if i.head+7 >= len(i.str) {
+			for ; i.head < len(i.str); i.head++ {
+				if i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z') {
+					continue
+				} else if i.str[i.head] == ' ' ||
+					i.str[i.head] == '\n' ||
+					i.str[i.head] == '\r' ||
+					i.str[i.head] == '\t' ||
+					i.str[i.head] == ',' {
+					break
+				} else if i.str[i.head] < 0x20 {
+					i.errc = ErrUnexpToken
+					goto ERROR
+				}
+				break
+			}
+			break
+		}
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+	}
+
+	// <ExpectVarType after name>
+	i.token = TokenVarTypeName
+	/*<callback>*/
+
+	if fn(i) {
+		i.errc = ErrCallbackFn
+		goto ERROR
+	}
+
+	/*</callback>*/
+	i.expect = ExpectAfterVarTypeName
+	goto AFTER_VAR_TYPE_NAME
+	// </ExpectVarType after name>
+
+	/*</name>*/
+
+	/*</l_var_type>*/
+
+	/*<l_var_name>*/
+VAR_NAME:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if i.str[i.head] == '#' {
+		goto COMMENT
+	}
+
+	/*<name>*/
+	// Followed by varname>
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	i.tail = i.head
+	if i.str[i.head] != '_' &&
+		(i.str[i.head] < 'a' || i.str[i.head] > 'z') &&
+		(i.str[i.head] < 'A' || i.str[i.head] > 'Z') {
+		i.errc = ErrUnexpToken
+		goto ERROR
+	}
+	i.head++
+	for {
+		if i.head+7 >= len(i.str) {
+			for ; i.head < len(i.str); i.head++ {
+				if i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z') {
+					continue
+				} else if i.str[i.head] == ' ' ||
+					i.str[i.head] == '\n' ||
+					i.str[i.head] == '\r' ||
+					i.str[i.head] == '\t' ||
+					i.str[i.head] == ',' {
+					break
+				} else if i.str[i.head] < 0x20 {
+					i.errc = ErrUnexpToken
+					goto ERROR
+				}
+				break
+			}
+			break
+		}
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+	}
+
+	// <ExpectVarName after name>
+	i.token = TokenVarName
+	/*<callback>*/
+
+	if fn(i) {
+		i.errc = ErrCallbackFn
+		goto ERROR
+	}
+
+	/*</callback>*/
+	i.expect = ExpectColumnAfterVar
+	goto AFTER_DECL_VAR_NAME
+	// </ExpectVarName after name>
+
+	/*</name>*/
+
+	/*</l_var_name>*/
+
+	/*<l_var_ref>*/
+VAR_REF_NAME:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if i.str[i.head] == '#' {
+		goto COMMENT
+	}
+
+	/*<name>*/
+	// Followed by varrefname>
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	i.tail = i.head
+	if i.str[i.head] != '_' &&
+		(i.str[i.head] < 'a' || i.str[i.head] > 'z') &&
+		(i.str[i.head] < 'A' || i.str[i.head] > 'Z') {
+		i.errc = ErrUnexpToken
+		goto ERROR
+	}
+	i.head++
+	for {
+		if i.head+7 >= len(i.str) {
+			for ; i.head < len(i.str); i.head++ {
+				if i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z') {
+					continue
+				} else if i.str[i.head] == ' ' ||
+					i.str[i.head] == '\n' ||
+					i.str[i.head] == '\r' ||
+					i.str[i.head] == '\t' ||
+					i.str[i.head] == ',' {
+					break
+				} else if i.str[i.head] < 0x20 {
+					i.errc = ErrUnexpToken
+					goto ERROR
+				}
+				break
+			}
+			break
+		}
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+	}
+
+	// <ExpectVarRefName after name>
+	i.token = TokenVarRef
+	/*<callback>*/
+
+	if fn(i) {
+		i.errc = ErrCallbackFn
+		goto ERROR
+	}
+
+	/*</callback>*/
+	i.expect = ExpectAfterValueInner
+	goto AFTER_VALUE_INNER
+	// </ExpectVarRefName after name>
+
+	/*</name>*/
+
+	/*</l_var_ref>*/
+
+	/*<l_dir_name>*/
+DIR_NAME:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if i.str[i.head] == '#' {
+		goto COMMENT
+	}
+	i.expect = ExpectDirName
+
+	/*<name>*/
+	// Followed by dirname>
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	i.tail = i.head
+	if i.str[i.head] != '_' &&
+		(i.str[i.head] < 'a' || i.str[i.head] > 'z') &&
+		(i.str[i.head] < 'A' || i.str[i.head] > 'Z') {
+		i.errc = ErrUnexpToken
+		goto ERROR
+	}
+	i.head++
+	for {
+		if i.head+7 >= len(i.str) {
+			for ; i.head < len(i.str); i.head++ {
+				if i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z') {
+					continue
+				} else if i.str[i.head] == ' ' ||
+					i.str[i.head] == '\n' ||
+					i.str[i.head] == '\r' ||
+					i.str[i.head] == '\t' ||
+					i.str[i.head] == ',' {
+					break
+				} else if i.str[i.head] < 0x20 {
+					i.errc = ErrUnexpToken
+					goto ERROR
+				}
+				break
+			}
+			break
+		}
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+	}
+
+	// <ExpectDirName after name>
+	i.token, i.dirOn = TokenDirName, dirOn
+	/*<callback>*/
+
+	if fn(i) {
+		i.errc = ErrCallbackFn
+		goto ERROR
+	}
+
+	/*</callback>*/
+	goto AFTER_DIR_NAME
+	// </ExpectDirName after name>
+
+	/*</name>*/
+
+	/*</l_dir_name>*/
+
+	/*<l_collumn_after_arg_name>*/
+COLUMN_AFTER_ARG_NAME:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if i.str[i.head] == '#' {
+		goto COMMENT
+	} else if i.str[i.head] != ':' {
+		i.errc = ErrUnexpToken
+		goto ERROR
+	}
+	i.head++
+	i.stackReset()
+	i.expect = ExpectVal
+	goto VALUE
+	/*</l_collumn_after_arg_name>*/
+
+	/*<l_arg_list>*/
+ARG_LIST:
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if i.str[i.head] == '#' {
+		goto COMMENT
+	}
+
+	/*<name>*/
+	// Followed by argname>
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	i.tail = i.head
+	if i.str[i.head] != '_' &&
+		(i.str[i.head] < 'a' || i.str[i.head] > 'z') &&
+		(i.str[i.head] < 'A' || i.str[i.head] > 'Z') {
+		i.errc = ErrUnexpToken
+		goto ERROR
+	}
+	i.head++
+	for {
+		if i.head+7 >= len(i.str) {
+			for ; i.head < len(i.str); i.head++ {
+				if i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z') {
+					continue
+				} else if i.str[i.head] == ' ' ||
+					i.str[i.head] == '\n' ||
+					i.str[i.head] == '\r' ||
+					i.str[i.head] == '\t' ||
+					i.str[i.head] == ',' {
+					break
+				} else if i.str[i.head] < 0x20 {
+					i.errc = ErrUnexpToken
+					goto ERROR
+				}
+				break
+			}
+			break
+		}
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+	}
+
+	// <ExpectArgName after name>
+	i.token = TokenArgName
+	/*<callback>*/
+
+	if fn(i) {
+		i.errc = ErrCallbackFn
+		goto ERROR
+	}
+
+	/*</callback>*/
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	i.expect = ExpectColumnAfterArg
+	goto COLUMN_AFTER_ARG_NAME
+	// </ExpectArgName after name>
+
+	/*</name>*/
+
+	/*</l_arg_list>*/
+
+	/*<l_after_var_type_name>*/
+AFTER_VAR_TYPE_NAME:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	if i.head < len(i.str) && i.str[i.head] == '!' {
+		i.tail = -1
+		i.token = TokenVarTypeNotNull
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+		i.head++
+	}
+	goto AFTER_VAR_TYPE_NOT_NULL
+	/*</l_after_var_type_name>*/
+
+	/*<l_after_var_type_not_null>*/
+AFTER_VAR_TYPE_NOT_NULL:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if i.str[i.head] == '#' {
+		goto COMMENT
+	} else if i.str[i.head] == ']' {
+		if typeArrLvl < 1 {
+			i.errc, i.expect = ErrUnexpToken, ExpectVar
+			goto ERROR
+		}
+		i.tail = -1
+		i.token = TokenVarTypeArrEnd
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+		i.head++
+		typeArrLvl--
+
+		/*<skip_irrelevant>*/
+		for {
+			if i.head+7 >= len(i.str) {
+				for i.head < len(i.str) {
+					if i.str[i.head] != ',' &&
+						i.str[i.head] != ' ' &&
+						i.str[i.head] != '\n' &&
+						i.str[i.head] != '\t' &&
+						i.str[i.head] != '\r' {
+						break
+					}
+					i.head++
+				}
+				break
+			}
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+		}
+		/*</skip_irrelevant>*/
+
+		if i.head < len(i.str) && i.str[i.head] == '!' {
+			i.tail = -1
+			i.token = TokenVarTypeNotNull
+			/*<callback>*/
+
+			if fn(i) {
+				i.errc = ErrCallbackFn
+				goto ERROR
+			}
+
+			/*</callback>*/
+			i.head++
+		}
+
+		if typeArrLvl > 0 {
+			goto AFTER_VAR_TYPE_NAME
+		}
+	}
+	i.expect = ExpectAfterVarType
+	goto AFTER_VAR_TYPE
+	/*</l_after_var_type_not_null>*/
+
+	/*<l_after_field_name>*/
+AFTER_FIELD_NAME:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	// Lookahead
+	switch i.str[i.head] {
+	case '(':
+		// Argument list
+		i.tail = -1
+		i.token = TokenArgList
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+		i.head++
+
+		/*<skip_irrelevant>*/
+		for {
+			if i.head+7 >= len(i.str) {
+				for i.head < len(i.str) {
+					if i.str[i.head] != ',' &&
+						i.str[i.head] != ' ' &&
+						i.str[i.head] != '\n' &&
+						i.str[i.head] != '\t' &&
+						i.str[i.head] != '\r' {
+						break
+					}
+					i.head++
+				}
+				break
+			}
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+		}
+		/*</skip_irrelevant>*/
+
+		i.expect = ExpectArgName
+		goto ARG_LIST
+	case '{':
+		// Field selector expands without arguments
+		i.expect = ExpectSelSet
+		goto SELECTION_SET
+	case '#':
+		i.expect = ExpectAfterFieldName
+		goto COMMENT
+	case '@':
+		i.head++
+		dirOn, i.expect = dirField, ExpectDir
+		goto DIR_NAME
+	}
+	i.expect = ExpectAfterSelection
+	goto AFTER_SELECTION
+	/*</l_after_field_name>*/
+
+	/*<l_after_opr_name>*/
+AFTER_OPR_NAME:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc, i.expect = ErrUnexpEOF, ExpectSelSet
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	switch i.str[i.head] {
+	case '#':
+		goto COMMENT
+	case '{':
+		i.expect = ExpectSelSet
+		goto SELECTION_SET
+	case '(':
+		// Variable list
+		i.tail = -1
+		i.token = TokenVarList
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+		i.head++
+		i.expect = ExpectVar
+		goto OPR_VAR
+	case '@':
+		i.head++
+		dirOn, i.expect = dirOpr, ExpectDir
+		goto DIR_NAME
+	}
+	i.errc = ErrUnexpToken
+	i.expect = ExpectSelSet
+	goto ERROR
+	/*</l_after_opr_name>*/
+
+	/*<l_frag_keyword_on>*/
+FRAG_KEYWORD_ON:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	if i.head+1 >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	} else if i.str[i.head] == '#' {
+		goto COMMENT
+	} else if i.str[i.head+1] != 'n' ||
+		i.str[i.head] != 'o' {
+		i.errc = ErrUnexpToken
+		goto ERROR
+	}
+	i.head += len("on")
+	i.expect = ExpectFragTypeCond
+	goto FRAG_TYPE_COND
+
+FRAG_TYPE_COND:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if i.str[i.head] == '#' {
+		goto COMMENT
+	}
+
+	/*<name>*/
+	// Followed by fragtypecond>
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	i.tail = i.head
+	if i.str[i.head] != '_' &&
+		(i.str[i.head] < 'a' || i.str[i.head] > 'z') &&
+		(i.str[i.head] < 'A' || i.str[i.head] > 'Z') {
+		i.errc = ErrUnexpToken
+		goto ERROR
+	}
+	i.head++
+	for {
+		if i.head+7 >= len(i.str) {
+			for ; i.head < len(i.str); i.head++ {
+				if i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z') {
+					continue
+				} else if i.str[i.head] == ' ' ||
+					i.str[i.head] == '\n' ||
+					i.str[i.head] == '\r' ||
+					i.str[i.head] == '\t' ||
+					i.str[i.head] == ',' {
+					break
+				} else if i.str[i.head] < 0x20 {
+					i.errc = ErrUnexpToken
+					goto ERROR
+				}
+				break
+			}
+			break
+		}
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+	}
+
+	// <ExpectFragTypeCond after name>
+	i.token = TokenFragTypeCond
+	/*<callback>*/
+
+	if fn(i) {
+		i.errc = ErrCallbackFn
+		goto ERROR
+	}
+
+	/*</callback>*/
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc, i.expect = ErrUnexpEOF, ExpectSelSet
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if i.str[i.head] == '@' {
+		dirOn = dirFragInlineOrDef
+		goto AFTER_DIR_NAME
+	}
+	i.expect = ExpectSelSet
+	goto SELECTION_SET
+	// </ExpectFragTypeCond after name>
+
+	/*</name>*/
+
+	/*</l_frag_keyword_on>*/
+
+	/*<l_frag_inlined>*/
+FRAG_INLINED:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	if i.str[i.head] == '#' {
+		goto COMMENT
+	}
+
+	/*<name>*/
+	// Followed by fraginlined>
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	i.tail = i.head
+	if i.str[i.head] != '_' &&
+		(i.str[i.head] < 'a' || i.str[i.head] > 'z') &&
+		(i.str[i.head] < 'A' || i.str[i.head] > 'Z') {
+		i.errc = ErrUnexpToken
+		goto ERROR
+	}
+	i.head++
+	for {
+		if i.head+7 >= len(i.str) {
+			for ; i.head < len(i.str); i.head++ {
+				if i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z') {
+					continue
+				} else if i.str[i.head] == ' ' ||
+					i.str[i.head] == '\n' ||
+					i.str[i.head] == '\r' ||
+					i.str[i.head] == '\t' ||
+					i.str[i.head] == ',' {
+					break
+				} else if i.str[i.head] < 0x20 {
+					i.errc = ErrUnexpToken
+					goto ERROR
+				}
+				break
+			}
+			break
+		}
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+	}
+
+	// <ExpectFragInlined after name>
+	i.token = TokenFragInline
+	/*<callback>*/
+
+	if fn(i) {
+		i.errc = ErrCallbackFn
+		goto ERROR
+	}
+
+	/*</callback>*/
+	i.expect, dirOn = ExpectDirName, dirFragInlineOrDef
+	goto AFTER_DIR_NAME
+	// </ExpectFragInlined after name>
+
+	/*</name>*/
+
+	/*</l_frag_inlined>*/
+
+	/*<l_comment>*/
+COMMENT:
+	i.head++
+	for {
+		if i.head+7 >= len(i.str) {
+			for ; i.head < len(i.str) && i.str[i.head] != '\n'; i.head++ {
+			}
+			break
+		}
+		if i.str[i.head] != '\n' &&
+			i.str[i.head+1] != '\n' &&
+			i.str[i.head+2] != '\n' &&
+			i.str[i.head+3] != '\n' &&
+			i.str[i.head+4] != '\n' &&
+			i.str[i.head+5] != '\n' &&
+			i.str[i.head+6] != '\n' &&
+			i.str[i.head+7] != '\n' {
+			i.head += 8
+			continue
+		}
+		if i.str[i.head] == '\n' {
+			break
+		}
+		i.head++
+		if i.str[i.head] == '\n' {
+			break
+		}
+		i.head++
+		if i.str[i.head] == '\n' {
+			break
+		}
+		i.head++
+		if i.str[i.head] == '\n' {
+			break
+		}
+		i.head++
+		if i.str[i.head] == '\n' {
+			break
+		}
+		i.head++
+		if i.str[i.head] == '\n' {
+			break
+		}
+		i.head++
+		if i.str[i.head] == '\n' {
+			break
+		}
+		i.head++
+		if i.str[i.head] == '\n' {
+			break
+		}
+	}
+	i.tail = -1
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	switch i.expect {
+	case ExpectOprName:
+		goto AFTER_OPR_NAME
+	case ExpectVarRefName:
+		goto VAR_REF_NAME
+	case ExpectVarName:
+		goto VAR_NAME
+	case ExpectDef:
+		goto DEFINITION
+	case ExpectDir:
+		goto DIR_NAME
+	case ExpectDirName:
+		goto AFTER_DIR_NAME
+	case ExpectSelSet:
+		goto SELECTION_SET
+	case ExpectSel:
+		goto SELECTION
+	case ExpectAfterSelection:
+		goto AFTER_SELECTION
+	case ExpectVar:
+		goto OPR_VAR
+	case ExpectArgName:
+		goto ARG_LIST
+	case ExpectColumnAfterArg:
+		goto COLUMN_AFTER_ARG_NAME
+	case ExpectVal:
+		goto VALUE
+	case ExpectAfterFieldName:
+		goto AFTER_FIELD_NAME
+	case ExpectAfterValueInner:
+		goto AFTER_VALUE_INNER
+	case ExpectAfterValueOuter:
+		goto AFTER_VALUE_OUTER
+	case ExpectAfterArgList:
+		goto AFTER_ARG_LIST
+	case ExpectAfterDefKeyword:
+		goto AFTER_DEF_KEYWORD
+	case ExpectFragName:
+		goto AFTER_KEYWORD_FRAGMENT
+	case ExpectFragKeywordOn:
+		goto FRAG_KEYWORD_ON
+	case ExpectFragInlined:
+		goto FRAG_INLINED
+	case ExpectFragTypeCond:
+		goto FRAG_TYPE_COND
+	case ExpectFrag:
+		goto SPREAD
+	case ExpectColumnAfterVar:
+		goto AFTER_DECL_VAR_NAME
+	case ExpectVarType:
+		goto VAR_TYPE
+	case ExpectAfterVarType:
+		goto AFTER_VAR_TYPE
+	case ExpectAfterVarTypeName:
+		goto AFTER_VAR_TYPE_NAME
+	}
+	/*</l_comment>*/
+
+	/*<l_definition_end>*/
+DEFINITION_END:
+	i.levelSel, i.expect = 0, ExpectDef
+	// Expect end of file
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	if i.head < len(i.str) {
+		goto DEFINITION
+	}
+	return Error{}
+	/*</l_definition_end>*/
+
+	/*<l_error>*/
+ERROR:
+	{
+		var atIndex rune
+		if i.head < len(i.str) {
+			atIndex, _ = utf8.DecodeRune(i.str[i.head:])
+		}
+		return Error{
+			Index:       i.head,
+			AtIndex:     atIndex,
+			Code:        i.errc,
+			Expectation: i.expect,
+		}
+	}
+	/*</l_error>*/
+
+	/*</scan_body>*/
+
+}
+
+// scanReader is Scan reading more of the input from r
+// whenever it reaches the end of the window str.
+func scanReader(str []byte, r *reader, fn func(*Iterator) (err bool)) Error {
+
+	/*<scan_body>*/
+	i := iteratorPool.Get().(*Iterator)
+	i.stackReset()
+	i.expect = ExpectDef
+	i.tail, i.head = -1, 0
+	i.str = str
+	i.r = r
+	i.levelSel = 0
+	i.offset = 0
+	i.errc = 0
+	i.path.on = false
+	defer iteratorPool.Put(i)
+
+	// inDefVal triggers different expectations after values
+	// when the iterator is in a variable default value definition.
+	var inDefVal bool
+	var typeArrLvl int
+	var dirOn dirTarget
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) && !i.fill(i.head) {
+		i.errc, i.expect = ErrUnexpEOF, ExpectDef
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	/*<l_definition>*/
+DEFINITION:
+	// Make the longest keyword fit in the window
+	_ = i.ahead(i.head, len("subscription"))
+	if i.head >= len(i.str) {
+		goto DEFINITION_END
+	} else if i.str[i.head] == '#' {
+		i.expect = ExpectDef
+		goto COMMENT
+	} else if i.str[i.head] == '{' {
+		i.token, i.def = TokenDefQry, TokenDefQry
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+		i.expect = ExpectSelSet
+		goto SELECTION_SET
+	} else if i.isHeadKeywordQuery() {
+		// Query
+		i.token, i.def = TokenDefQry, TokenDefQry
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+		i.head += len("query")
+		i.expect = ExpectAfterDefKeyword
+		goto AFTER_DEF_KEYWORD
+	} else if i.isHeadKeywordMutation() {
+		// Mutation
+		i.token, i.def = TokenDefMut, TokenDefMut
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+		i.head += len("mutation")
+		i.expect = ExpectAfterDefKeyword
+		goto AFTER_DEF_KEYWORD
+	} else if i.isHeadKeywordSubscription() {
+		// Subscription
+		i.token, i.def = TokenDefSub, TokenDefSub
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+		i.head += len("subscription")
+		i.expect = ExpectAfterDefKeyword
+		goto AFTER_DEF_KEYWORD
+	} else if i.isHeadKeywordFragment() {
+		// Fragment
+		i.tail = -1
+		i.token, i.def = TokenDefFrag, TokenDefFrag
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+		i.head += len("fragment")
+		i.expect = ExpectFragName
+		goto AFTER_KEYWORD_FRAGMENT
+	}
+
+	i.errc = ErrUnexpToken
+	i.expect = ExpectDef
+	goto ERROR
+	/*</l_definition>*/
+
+	/*<l_after_def_keyword>*/
+AFTER_DEF_KEYWORD:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) && !i.fill(i.head) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	switch i.str[i.head] {
+	case '#':
+		goto COMMENT
+	case '{':
+		i.expect = ExpectSelSet
+		goto SELECTION_SET
+	case '(':
+		// Variable list
+		i.tail = -1
+		i.token = TokenVarList
+		/*<callback>*/
+
+		if fn(i) {
+			i.errc = ErrCallbackFn
+			goto ERROR
+		}
+
+		/*</callback>*/
+		i.head++
+		i.expect = ExpectVar
+		goto OPR_VAR
+	case '@':
+		i.head++
+		dirOn, i.expect = dirOpr, ExpectDir
+		goto DIR_NAME
+	}
+	i.expect = ExpectOprName
+
+	/*<name>*/
+	// Followed by oprname>
+
+	/*<check_eof>*/
+	if i.head >= len(i.str) && !i.fill(i.head) {
+		i.errc = ErrUnexpEOF
+		goto ERROR
+	}
+	/*</check_eof>*/
+
+	i.tail = i.head
+	if i.str[i.head] != '_' &&
+		(i.str[i.head] < 'a' || i.str[i.head] > 'z') &&
+		(i.str[i.head] < 'A' || i.str[i.head] > 'Z') {
+		i.errc = ErrUnexpToken
+		goto ERROR
+	}
+	i.head++
+	for {
+		if i.head+7 >= len(i.str) {
+			for ; i.head < len(i.str) || i.fill(i.tail); i.head++ {
+				if i.str[i.head] == '_' ||
+					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+					(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z') {
+					continue
+				} else if i.str[i.head] == ' ' ||
+					i.str[i.head] == '\n' ||
+					i.str[i.head] == '\r' ||
+					i.str[i.head] == '\t' ||
+					i.str[i.head] == ',' {
+					break
+				} else if i.str[i.head] < 0x20 {
+					i.errc = ErrUnexpToken
+					goto ERROR
+				}
+				break
+			}
+			break
+		}
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+		if !(i.str[i.head] == '_' ||
+			(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
+			(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
+			(i.str[i.head] >= 'A' && i.str[i.head] <= 'Z')) {
+			break
+		}
+		i.head++
+	}
+
+	// <ExpectOprName after name>
+	i.token = TokenOprName
+	/*<callback>*/
+
+	if fn(i) {
+		i.errc = ErrCallbackFn
+		goto ERROR
+	}
+
+	/*</callback>*/
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	goto AFTER_OPR_NAME
+	// </ExpectOprName after name>
+
+	/*</name>*/
+
+	/*</l_after_def_keyword>*/
+
+	/*<l_after_dir_name>*/
+AFTER_DIR_NAME:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	switch dirOn {
+	case dirField:
+
+		/*<check_eof>*/
+		if i.head >= len(i.str) && !i.fill(i.head) {
+			i.errc, i.expect = ErrUnexpEOF, ExpectAfterFieldName
+			goto ERROR
+		}
+		/*</check_eof>*/
+
+		switch i.str[i.head] {
+		case '#':
+			goto COMMENT
+		case '(':
+			// Directive argument list
+			i.tail = -1
+			i.token = TokenArgList
+			/*<callback>*/
+
+			if fn(i) {
+				i.errc = ErrCallbackFn
+				goto ERROR
+			}
+
+			/*</callback>*/
+			i.head++
+
+			/*<skip_irrelevant>*/
+			for {
+				if i.head+7 >= len(i.str) {
+					for i.head < len(i.str) || i.fill(i.head) {
+						if i.str[i.head] != ',' &&
+							i.str[i.head] != ' ' &&
+							i.str[i.head] != '\n' &&
+							i.str[i.head] != '\t' &&
+							i.str[i.head] != '\r' {
+							break
+						}
+						i.head++
+					}
+					break
+				}
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			/*</skip_irrelevant>*/
+
+			i.expect = ExpectArgName
+			goto ARG_LIST
+		case '@':
+			i.head++
+			i.expect = ExpectDir
+			goto DIR_NAME
+		case '{':
+			// Field selector expands without arguments
+			i.expect = ExpectSelSet
+			goto SELECTION_SET
+		default:
+			i.expect, dirOn = ExpectAfterSelection, 0
+			goto AFTER_SELECTION
+		}
+	case dirOpr:
+
+		/*<check_eof>*/
+		if i.head >= len(i.str) && !i.fill(i.head) {
+			i.errc, i.expect = ErrUnexpEOF, ExpectAfterDefKeyword
+			goto ERROR
+		}
+		/*</check_eof>*/
+
+		switch i.str[i.head] {
+		case '#':
+			goto COMMENT
+		case '(':
+			// Directive argument list
+			i.tail = -1
+			i.token = TokenArgList
+			/*<callback>*/
+
+			if fn(i) {
+				i.errc = ErrCallbackFn
+				goto ERROR
+			}
+
+			/*</callback>*/
+			i.head++
+
+			/*<skip_irrelevant>*/
+			for {
+				if i.head+7 >= len(i.str) {
+					for i.head < len(i.str) || i.fill(i.head) {
+						if i.str[i.head] != ',' &&
+							i.str[i.head] != ' ' &&
+							i.str[i.head] != '\n' &&
+							i.str[i.head] != '\t' &&
+							i.str[i.head] != '\r' {
+							break
+						}
+						i.head++
+					}
+					break
+				}
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			/*</skip_irrelevant>*/
+
+			i.expect = ExpectArgName
+			goto ARG_LIST
+		case '@':
+			i.head++
+			i.expect = ExpectDir
+			goto DIR_NAME
+		default:
+			i.expect, dirOn = ExpectSelSet, 0
+			goto SELECTION_SET
+		}
+	case dirVar:
+
+		/*<check_eof>*/
+		if i.head >= len(i.str) && !i.fill(i.head) {
+			i.errc, i.expect = ErrUnexpEOF, ExpectAfterVarType
+			goto ERROR
+		}
+		/*</check_eof>*/
+
+		switch i.str[i.head] {
+		case '#':
+			goto COMMENT
+		case '(':
+			// Directive argument list
+			i.tail = -1
+			i.token = TokenArgList
+			/*<callback>*/
+
+			if fn(i) {
+				i.errc = ErrCallbackFn
+				goto ERROR
+			}
+
+			/*</callback>*/
+			i.head++
+
+			/*<skip_irrelevant>*/
+			for {
+				if i.head+7 >= len(i.str) {
+					for i.head < len(i.str) || i.fill(i.head) {
+						if i.str[i.head] != ',' &&
+							i.str[i.head] != ' ' &&
+							i.str[i.head] != '\n' &&
+							i.str[i.head] != '\t' &&
+							i.str[i.head] != '\r' {
+							break
+						}
+						i.head++
+					}
+					break
+				}
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			/*</skip_irrelevant>*/
+
+			i.expect = ExpectArgName
+			goto ARG_LIST
+		case '@':
+			i.head++
+			i.expect = ExpectDir
+			goto DIR_NAME
+		case ')':
+			dirOn = 0
+			goto VAR_LIST_END
+		default:
+			i.expect, dirOn = ExpectVar, 0
+			goto OPR_VAR
+		}
+	case dirFragRef:
+
+		/*<check_eof>*/
+		if i.head >= len(i.str) && !i.fill(i.head) {
+			i.errc, i.expect = ErrUnexpEOF, ExpectAfterSelection
+			goto ERROR
+		}
+		/*</check_eof>*/
+
+		switch i.str[i.head] {
+		case '#':
+			goto COMMENT
+		case '(':
+			// Directive argument list
+			i.tail = -1
+			i.token = TokenArgList
+			/*<callback>*/
+
+			if fn(i) {
+				i.errc = ErrCallbackFn
+				goto ERROR
+			}
+
+			/*</callback>*/
+			i.head++
+
+			/*<skip_irrelevant>*/
+			for {
+				if i.head+7 >= len(i.str) {
+					for i.head < len(i.str) || i.fill(i.head) {
+						if i.str[i.head] != ',' &&
+							i.str[i.head] != ' ' &&
+							i.str[i.head] != '\n' &&
+							i.str[i.head] != '\t' &&
+							i.str[i.head] != '\r' {
+							break
+						}
+						i.head++
+					}
+					break
+				}
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			/*</skip_irrelevant>*/
+
+			i.expect = ExpectArgName
+			goto ARG_LIST
+		case '@':
+			i.head++
+			i.expect = ExpectDir
+			goto DIR_NAME
+		default:
+			i.expect, dirOn = ExpectAfterSelection, 0
+			goto AFTER_SELECTION
+		}
+	case dirFragInlineOrDef:
+
+		/*<check_eof>*/
+		if i.head >= len(i.str) && !i.fill(i.head) {
+			i.errc, i.expect = ErrUnexpEOF, ExpectSelSet
+			goto ERROR
+		}
+		/*</check_eof>*/
+
+		switch i.str[i.head] {
+		case '#':
+			goto COMMENT
+		case '(':
+			// Directive argument list
+			i.tail = -1
+			i.token = TokenArgList
+			/*<callback>*/
+
+			if fn(i) {
+				i.errc = ErrCallbackFn
+				goto ERROR
+			}
+
+			/*</callback>*/
+			i.head++
+
+			/*<skip_irrelevant>*/
+			for {
+				if i.head+7 >= len(i.str) {
+					for i.head < len(i.str) || i.fill(i.head) {
+						if i.str[i.head] != ',' &&
+							i.str[i.head] != ' ' &&
+							i.str[i.head] != '\n' &&
+							i.str[i.head] != '\t' &&
+							i.str[i.head] != '\r' {
+							break
+						}
+						i.head++
+					}
+					break
+				}
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			/*</skip_irrelevant>*/
+
+			i.expect = ExpectArgName
+			goto ARG_LIST
+		case '@':
+			i.head++
+			i.expect = ExpectDir
+			goto DIR_NAME
+		default:
+			i.expect, dirOn = ExpectSelSet, 0
+			goto SELECTION_SET
+		}
+	default:
+		// This line is only executed if we forgot to handle a dirOn case.
+		panic(fmt.Errorf("unhandled dirOn case: %#v", dirOn))
+	}
+	/*</l_after_dir_name>*/
+
+	/*<l_after_dir_args>*/
+AFTER_DIR_ARGS:
+
+	/*<skip_irrelevant>*/
+	for {
+		if i.head+7 >= len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
+				if i.str[i.head] != ',' &&
+					i.str[i.head] != ' ' &&
+					i.str[i.head] != '\n' &&
+					i.str[i.head] != '\t' &&
+					i.str[i.head] != '\r' {
+					break
+				}
+				i.head++
+			}
+			break
+		}
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+		if i.str[i.head] != ',' &&
+			i.str[i.head] != ' ' &&
+			i.str[i.head] != '\n' &&
+			i.str[i.head] != '\t' &&
+			i.str[i.head] != '\r' {
+			break
+		}
+		i.head++
+	}
+	/*</skip_irrelevant>*/
+
+	switch dirOn {
+	case dirField:
+
+		/*<check_eof>*/
+		if i.head >= len(i.str) && !i.fill(i.head) {
+			i.errc, i.expect = ErrUnexpEOF, ExpectAfterFieldName
+			goto ERROR
+		}
+		/*</check_eof>*/
+
+		switch i.str[i.head] {
+		case '#':
+			goto COMMENT
+		case '@':
+			i.head++
+			i.expect = ExpectDir
+			goto DIR_NAME
+		case '{':
+			i.expect = ExpectSelSet
+			goto SELECTION_SET
+		default:
+			i.expect, dirOn = ExpectAfterSelection, 0
+			goto AFTER_SELECTION
+		}
+	case dirOpr:
+
+		/*<check_eof>*/
+		if i.head >= len(i.str) && !i.fill(i.head) {
+			i.errc, i.expect = ErrUnexpEOF, ExpectAfterDefKeyword
+			goto ERROR
+		}
+		/*</check_eof>*/
+
+		switch i.str[i.head] {
+		case '#':
+			goto COMMENT
+		case '@':
+			i.head++
+			i.expect = ExpectDir
+			goto DIR_NAME
+		default:
+			i.expect, dirOn = ExpectSelSet, 0
+			goto SELECTION_SET
+		}
+	case dirVar:
+
+		/*<skip_irrelevant>*/
+		for {
+			if i.head+7 >= len(i.str) {
+				for i.head < len(i.str) || i.fill(i.head) {
+					if i.str[i.head] != ',' &&
+						i.str[i.head] != ' ' &&
+						i.str[i.head] != '\n' &&
+						i.str[i.head] != '\t' &&
+						i.str[i.head] != '\r' {
+						break
+					}
+					i.head++
+				}
+				break
+			}
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+			if i.str[i.head] != ',' &&
+				i.str[i.head] != ' ' &&
+				i.str[i.head] != '\n' &&
+				i.str[i.head] != '\t' &&
+				i.str[i.head] != '\r' {
+				break
+			}
+			i.head++
+		}
+		/*</skip_irrelevant>*/
+
+		/*<check_eof>*/
+		if i.head >= len(i.str) && !i.fill(i.head) {
+			i.errc, i.expect = ErrUnexpEOF, ExpectAfterVarType
+			goto ERROR
+		}
+		/*</check_eof>*/
+
+		switch i.str[i.head] {
+		case '#':
+			goto COMMENT
+		case '@':
+			i.head++
+			i.expect = ExpectDir
+			goto DIR_NAME
+		case ')':
+			dirOn = 0
+			goto VAR_LIST_END
 		default:
 			i.expect, dirOn = ExpectAfterVarType, 0
 			goto OPR_VAR
@@ -1449,7 +10190,7 @@ AFTER_DIR_ARGS:
 	case dirFragRef:
 
 		/*<check_eof>*/
-		if i.head >= len(i.str) {
+		if i.head >= len(i.str) && !i.fill(i.head) {
 			i.errc, i.expect = ErrUnexpEOF, ExpectAfterSelection
 			goto ERROR
 		}
@@ -1469,7 +10210,7 @@ AFTER_DIR_ARGS:
 	case dirFragInlineOrDef:
 
 		/*<check_eof>*/
-		if i.head >= len(i.str) {
+		if i.head >= len(i.str) && !i.fill(i.head) {
 			i.errc, i.expect = ErrUnexpEOF, ExpectSelSet
 			goto ERROR
 		}
@@ -1483,7 +10224,7 @@ AFTER_DIR_ARGS:
 			i.expect = ExpectDir
 			goto DIR_NAME
 		default:
-			i.expect = ExpectSelSet
+			i.expect, dirOn = ExpectSelSet, 0
 			goto SELECTION_SET
 		}
 	default:
@@ -1498,7 +10239,7 @@ AFTER_KEYWORD_FRAGMENT:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -1578,7 +10319,7 @@ AFTER_KEYWORD_FRAGMENT:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -1592,7 +10333,7 @@ AFTER_KEYWORD_FRAGMENT:
 	// Followed by fragname>
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -1608,7 +10349,7 @@ AFTER_KEYWORD_FRAGMENT:
 	i.head++
 	for {
 		if i.head+7 >= len(i.str) {
-			for ; i.head < len(i.str); i.head++ {
+			for ; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 				if i.str[i.head] == '_' ||
 					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
 					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
@@ -1716,7 +10457,7 @@ OPR_VAR:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -1796,7 +10537,7 @@ OPR_VAR:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -1822,7 +10563,7 @@ AFTER_VAR_TYPE:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -1902,7 +10643,7 @@ AFTER_VAR_TYPE:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -1925,7 +10666,7 @@ AFTER_VAR_TYPE:
 		/*<skip_irrelevant>*/
 		for {
 			if i.head+7 >= len(i.str) {
-				for i.head < len(i.str) {
+				for i.head < len(i.str) || i.fill(i.head) {
 					if i.str[i.head] != ',' &&
 						i.str[i.head] != ' ' &&
 						i.str[i.head] != '\n' &&
@@ -2030,7 +10771,7 @@ VAR_LIST_END:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -2112,7 +10853,7 @@ VAR_LIST_END:
 	i.expect = ExpectSelSet
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -2135,7 +10876,7 @@ SELECTION_SET:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -2242,7 +10983,7 @@ AFTER_SELECTION:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -2322,7 +11063,7 @@ AFTER_SELECTION:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -2355,7 +11096,7 @@ SEL_END:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -2446,7 +11187,7 @@ VALUE:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -2526,7 +11267,7 @@ VALUE:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -2555,7 +11296,7 @@ VALUE:
 		/*<skip_irrelevant>*/
 		for {
 			if i.head+7 >= len(i.str) {
-				for i.head < len(i.str) {
+				for i.head < len(i.str) || i.fill(i.head) {
 					if i.str[i.head] != ',' &&
 						i.str[i.head] != ' ' &&
 						i.str[i.head] != '\n' &&
@@ -2640,7 +11381,7 @@ VALUE:
 		// Followed by objfieldname>
 
 		/*<check_eof>*/
-		if i.head >= len(i.str) {
+		if i.head >= len(i.str) && !i.fill(i.head) {
 			i.errc = ErrUnexpEOF
 			goto ERROR
 		}
@@ -2656,7 +11397,7 @@ VALUE:
 		i.head++
 		for {
 			if i.head+7 >= len(i.str) {
-				for ; i.head < len(i.str); i.head++ {
+				for ; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 					if i.str[i.head] == '_' ||
 						(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
 						(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
@@ -2736,6 +11477,10 @@ VALUE:
 
 		// <ExpectObjFieldName after name>
 		i.token = TokenObjField
+		i.objFields[len(i.objFields)-1] = Span{
+			Start: i.offset + i.tail,
+			End:   i.offset + i.head,
+		}
 		/*<callback>*/
 
 		if fn(i) {
@@ -2748,7 +11493,7 @@ VALUE:
 		/*<skip_irrelevant>*/
 		for {
 			if i.head+7 >= len(i.str) {
-				for i.head < len(i.str) {
+				for i.head < len(i.str) || i.fill(i.head) {
 					if i.str[i.head] != ',' &&
 						i.str[i.head] != ' ' &&
 						i.str[i.head] != '\n' &&
@@ -2828,7 +11573,7 @@ VALUE:
 		/*</skip_irrelevant>*/
 
 		/*<check_eof>*/
-		if i.head >= len(i.str) {
+		if i.head >= len(i.str) && !i.fill(i.head) {
 			i.errc, i.expect = ErrUnexpEOF, ExpectColObjFieldName
 			goto ERROR
 		}
@@ -2844,7 +11589,7 @@ VALUE:
 		/*<skip_irrelevant>*/
 		for {
 			if i.head+7 >= len(i.str) {
-				for i.head < len(i.str) {
+				for i.head < len(i.str) || i.fill(i.head) {
 					if i.str[i.head] != ',' &&
 						i.str[i.head] != ' ' &&
 						i.str[i.head] != '\n' &&
@@ -2946,7 +11691,7 @@ VALUE:
 		/*<skip_irrelevant>*/
 		for {
 			if i.head+7 >= len(i.str) {
-				for i.head < len(i.str) {
+				for i.head < len(i.str) || i.fill(i.head) {
 					if i.str[i.head] != ',' &&
 						i.str[i.head] != ' ' &&
 						i.str[i.head] != '\n' &&
@@ -3028,7 +11773,7 @@ VALUE:
 		// Lookahead
 
 		/*<check_eof>*/
-		if i.head >= len(i.str) {
+		if i.head >= len(i.str) && !i.fill(i.head) {
 			i.errc, i.expect = ErrUnexpEOF, ExpectVal
 			goto ERROR
 		}
@@ -3058,7 +11803,7 @@ VALUE:
 		i.head++
 		i.tail = i.head
 
-		if i.head+1 < len(i.str) &&
+		if (i.head+1 < len(i.str) || i.ahead(i.tail, 2)) &&
 			i.str[i.head] == '"' &&
 			i.str[i.head+1] == '"' {
 			i.head += 2
@@ -3068,7 +11813,7 @@ VALUE:
 
 		// String value
 		escaped := false
-		if i.head < len(i.str) && i.str[i.head] == '"' {
+		if (i.head < len(i.str) || i.fill(i.tail)) && i.str[i.head] == '"' {
 			goto AFTER_STR_VAL
 		}
 		for {
@@ -3123,7 +11868,7 @@ VALUE:
 				}
 				i.head++
 			}
-			if i.head >= len(i.str) {
+			if i.head >= len(i.str) && !i.fill(i.tail) {
 				break
 			}
 			if i.str[i.head] < 0x20 {
@@ -3162,7 +11907,7 @@ VALUE:
 					i.head++
 
 					/*<check_eof>*/
-					if i.head >= len(i.str) {
+					if i.head >= len(i.str) && !i.fill(i.tail) {
 						i.errc, i.expect = ErrUnexpEOF, ExpectEscapedUnicodeSequence
 						goto ERROR
 					}
@@ -3176,7 +11921,7 @@ VALUE:
 					i.head++
 
 					/*<check_eof>*/
-					if i.head >= len(i.str) {
+					if i.head >= len(i.str) && !i.fill(i.tail) {
 						i.errc, i.expect = ErrUnexpEOF, ExpectEscapedUnicodeSequence
 						goto ERROR
 					}
@@ -3190,7 +11935,7 @@ VALUE:
 					i.head++
 
 					/*<check_eof>*/
-					if i.head >= len(i.str) {
+					if i.head >= len(i.str) && !i.fill(i.tail) {
 						i.errc, i.expect = ErrUnexpEOF, ExpectEscapedUnicodeSequence
 						goto ERROR
 					}
@@ -3204,7 +11949,7 @@ VALUE:
 					i.head++
 
 					/*<check_eof>*/
-					if i.head >= len(i.str) {
+					if i.head >= len(i.str) && !i.fill(i.tail) {
 						i.errc, i.expect = ErrUnexpEOF, ExpectEscapedUnicodeSequence
 						goto ERROR
 					}
@@ -3264,7 +12009,7 @@ VALUE:
 	case 'n':
 
 		/*<null>*/
-		if i.head+4 < len(i.str) &&
+		if (i.head+4 < len(i.str) || i.ahead(i.head, 5)) &&
 			i.str[i.head+3] == 'l' &&
 			i.str[i.head+2] == 'l' &&
 			i.str[i.head+1] == 'u' &&
@@ -3300,7 +12045,7 @@ VALUE:
 			// Followed by valenum>
 
 			/*<check_eof>*/
-			if i.head >= len(i.str) {
+			if i.head >= len(i.str) && !i.fill(i.head) {
 				i.errc = ErrUnexpEOF
 				goto ERROR
 			}
@@ -3316,7 +12061,7 @@ VALUE:
 			i.head++
 			for {
 				if i.head+7 >= len(i.str) {
-					for ; i.head < len(i.str); i.head++ {
+					for ; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 						if i.str[i.head] == '_' ||
 							(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
 							(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
@@ -3416,7 +12161,7 @@ VALUE:
 	case 't':
 
 		/*<true>*/
-		if i.head+4 < len(i.str) &&
+		if (i.head+4 < len(i.str) || i.ahead(i.head, 5)) &&
 			i.str[i.head+3] == 'e' &&
 			i.str[i.head+2] == 'u' &&
 			i.str[i.head+1] == 'r' &&
@@ -3452,7 +12197,7 @@ VALUE:
 			// Followed by valenum>
 
 			/*<check_eof>*/
-			if i.head >= len(i.str) {
+			if i.head >= len(i.str) && !i.fill(i.head) {
 				i.errc = ErrUnexpEOF
 				goto ERROR
 			}
@@ -3468,7 +12213,7 @@ VALUE:
 			i.head++
 			for {
 				if i.head+7 >= len(i.str) {
-					for ; i.head < len(i.str); i.head++ {
+					for ; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 						if i.str[i.head] == '_' ||
 							(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
 							(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
@@ -3568,7 +12313,7 @@ VALUE:
 	case 'f':
 
 		/*<false>*/
-		if i.head+5 < len(i.str) &&
+		if (i.head+5 < len(i.str) || i.ahead(i.head, 6)) &&
 			i.str[i.head+4] == 'e' &&
 			i.str[i.head+3] == 's' &&
 			i.str[i.head+2] == 'l' &&
@@ -3605,7 +12350,7 @@ VALUE:
 			// Followed by valenum>
 
 			/*<check_eof>*/
-			if i.head >= len(i.str) {
+			if i.head >= len(i.str) && !i.fill(i.head) {
 				i.errc = ErrUnexpEOF
 				goto ERROR
 			}
@@ -3621,7 +12366,7 @@ VALUE:
 			i.head++
 			for {
 				if i.head+7 >= len(i.str) {
-					for ; i.head < len(i.str); i.head++ {
+					for ; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 						if i.str[i.head] == '_' ||
 							(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
 							(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
@@ -3724,6 +12469,8 @@ VALUE:
 		// Number
 		i.tail = i.head
 
+		// s is the length of the number before the current part
+		// because the window may move while scanning it.
 		var s int
 
 		switch i.str[i.head] {
@@ -3732,7 +12479,7 @@ VALUE:
 			i.head++
 
 			/*<check_eof>*/
-			if i.head >= len(i.str) {
+			if i.head >= len(i.str) && !i.fill(i.tail) {
 				i.errc, i.expect = ErrUnexpEOF, ExpectVal
 				goto ERROR
 			}
@@ -3741,7 +12488,7 @@ VALUE:
 		case '0':
 			// Leading zero
 			i.head++
-			if len(i.str) > i.head {
+			if i.head < len(i.str) || i.fill(i.tail) {
 				if i.str[i.head] == '.' {
 					i.head++
 					goto FRACTION
@@ -3760,14 +12507,14 @@ VALUE:
 		}
 
 		// Integer
-		for s = i.head; i.head < len(i.str); i.head++ {
+		for s = i.head - i.tail; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 			if i.isHeadDigit() {
 				continue
 			} else if i.str[i.head] == '.' {
 				i.head++
 				goto FRACTION
 			} else if i.isHeadNumEnd() {
-				if i.head == s {
+				if i.head-i.tail == s {
 					// Expected at least one digit
 					i.errc = ErrInvalNum
 					i.expect = ExpectVal
@@ -3796,11 +12543,11 @@ VALUE:
 
 	FRACTION:
 		_ = 0 // Make code coverage count the label above
-		for s = i.head; i.head < len(i.str); i.head++ {
+		for s = i.head - i.tail; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 			if i.isHeadDigit() {
 				continue
 			} else if i.isHeadNumEnd() {
-				if i.head == s {
+				if i.head-i.tail == s {
 					// Expected at least one digit
 					i.errc = ErrInvalNum
 					i.expect = ExpectVal
@@ -3819,7 +12566,7 @@ VALUE:
 			i.expect = ExpectVal
 			goto ERROR
 		}
-		if s == i.head {
+		if s == i.head-i.tail {
 			// Unexpected end of number
 			i.errc = ErrUnexpEOF
 			i.expect = ExpectVal
@@ -3835,7 +12582,7 @@ VALUE:
 	EXPONENT_SIGN:
 
 		/*<check_eof>*/
-		if i.head >= len(i.str) {
+		if i.head >= len(i.str) && !i.fill(i.tail) {
 			i.errc, i.expect = ErrUnexpEOF, ExpectVal
 			goto ERROR
 		}
@@ -3844,11 +12591,11 @@ VALUE:
 		if i.str[i.head] == '-' || i.str[i.head] == '+' {
 			i.head++
 		}
-		for s = i.head; i.head < len(i.str); i.head++ {
+		for s = i.head - i.tail; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 			if i.isHeadDigit() {
 				continue
 			} else if i.isHeadNumEnd() {
-				if i.head == s {
+				if i.head-i.tail == s {
 					// Expected at least one digit
 					i.errc = ErrInvalNum
 					i.expect = ExpectVal
@@ -3885,7 +12632,7 @@ VALUE:
 		// Followed by valenum>
 
 		/*<check_eof>*/
-		if i.head >= len(i.str) {
+		if i.head >= len(i.str) && !i.fill(i.head) {
 			i.errc = ErrUnexpEOF
 			goto ERROR
 		}
@@ -3901,7 +12648,7 @@ VALUE:
 		i.head++
 		for {
 			if i.head+7 >= len(i.str) {
-				for ; i.head < len(i.str); i.head++ {
+				for ; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 					if i.str[i.head] == '_' ||
 						(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
 						(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
@@ -4080,19 +12827,21 @@ BLOCK_STRING:
 		}
 
 		/*<check_eof>*/
-		if i.head >= len(i.str) {
+		if i.head >= len(i.str) && !i.fill(i.tail) {
 			i.errc = ErrUnexpEOF
 			goto ERROR
 		}
 		/*</check_eof>*/
 
 		if i.str[i.head] == '\\' &&
+			(i.head+3 < len(i.str) || i.ahead(i.tail, 4)) &&
 			i.str[i.head+3] == '"' &&
 			i.str[i.head+2] == '"' &&
 			i.str[i.head+1] == '"' {
 			i.head += len(`\"""`)
 			continue
 		} else if i.str[i.head] == '"' &&
+			(i.head+2 < len(i.str) || i.ahead(i.tail, 3)) &&
 			i.str[i.head+2] == '"' &&
 			i.str[i.head+1] == '"' {
 			i.token = TokenStrBlock
@@ -4105,6 +12854,7 @@ BLOCK_STRING:
 
 			/*</callback>*/
 			i.head += len(`"""`)
+			i.expect = ExpectAfterValueInner
 			goto AFTER_VALUE_INNER
 		} else if i.str[i.head] < 0x20 &&
 			i.str[i.head] != '\t' &&
@@ -4123,7 +12873,7 @@ AFTER_VALUE_INNER:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -4203,7 +12953,7 @@ AFTER_VALUE_INNER:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -4232,7 +12982,7 @@ AFTER_VALUE_INNER:
 			/*<skip_irrelevant>*/
 			for {
 				if i.head+7 >= len(i.str) {
-					for i.head < len(i.str) {
+					for i.head < len(i.str) || i.fill(i.head) {
 						if i.str[i.head] != ',' &&
 							i.str[i.head] != ' ' &&
 							i.str[i.head] != '\n' &&
@@ -4323,7 +13073,7 @@ AFTER_VALUE_INNER:
 			// Followed by objfieldname>
 
 			/*<check_eof>*/
-			if i.head >= len(i.str) {
+			if i.head >= len(i.str) && !i.fill(i.head) {
 				i.errc = ErrUnexpEOF
 				goto ERROR
 			}
@@ -4339,7 +13089,7 @@ AFTER_VALUE_INNER:
 			i.head++
 			for {
 				if i.head+7 >= len(i.str) {
-					for ; i.head < len(i.str); i.head++ {
+					for ; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 						if i.str[i.head] == '_' ||
 							(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
 							(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
@@ -4419,6 +13169,10 @@ AFTER_VALUE_INNER:
 
 			// <ExpectObjFieldName after name>
 			i.token = TokenObjField
+			i.objFields[len(i.objFields)-1] = Span{
+				Start: i.offset + i.tail,
+				End:   i.offset + i.head,
+			}
 			/*<callback>*/
 
 			if fn(i) {
@@ -4431,7 +13185,7 @@ AFTER_VALUE_INNER:
 			/*<skip_irrelevant>*/
 			for {
 				if i.head+7 >= len(i.str) {
-					for i.head < len(i.str) {
+					for i.head < len(i.str) || i.fill(i.head) {
 						if i.str[i.head] != ',' &&
 							i.str[i.head] != ' ' &&
 							i.str[i.head] != '\n' &&
@@ -4511,7 +13265,7 @@ AFTER_VALUE_INNER:
 			/*</skip_irrelevant>*/
 
 			/*<check_eof>*/
-			if i.head >= len(i.str) {
+			if i.head >= len(i.str) && !i.fill(i.head) {
 				i.errc, i.expect = ErrUnexpEOF, ExpectColObjFieldName
 				goto ERROR
 			}
@@ -4527,7 +13281,7 @@ AFTER_VALUE_INNER:
 			/*<skip_irrelevant>*/
 			for {
 				if i.head+7 >= len(i.str) {
-					for i.head < len(i.str) {
+					for i.head < len(i.str) || i.fill(i.head) {
 						if i.str[i.head] != ',' &&
 							i.str[i.head] != ' ' &&
 							i.str[i.head] != '\n' &&
@@ -4633,7 +13387,7 @@ AFTER_VALUE_INNER:
 			/*<skip_irrelevant>*/
 			for {
 				if i.head+7 >= len(i.str) {
-					for i.head < len(i.str) {
+					for i.head < len(i.str) || i.fill(i.head) {
 						if i.str[i.head] != ',' &&
 							i.str[i.head] != ' ' &&
 							i.str[i.head] != '\n' &&
@@ -4728,7 +13482,7 @@ AFTER_VALUE_INNER:
 AFTER_VALUE_OUTER:
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -4776,7 +13530,7 @@ AFTER_VALUE_OUTER:
 	// Followed by argname>
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -4792,7 +13546,7 @@ AFTER_VALUE_OUTER:
 	i.head++
 	for {
 		if i.head+7 >= len(i.str) {
-			for ; i.head < len(i.str); i.head++ {
+			for ; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 				if i.str[i.head] == '_' ||
 					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
 					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
@@ -4884,7 +13638,7 @@ AFTER_VALUE_OUTER:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -4980,7 +13734,7 @@ AFTER_ARG_LIST:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -5060,7 +13814,7 @@ AFTER_ARG_LIST:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -5091,7 +13845,7 @@ SELECTION:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -5171,7 +13925,7 @@ SELECTION:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc, i.expect = ErrUnexpEOF, ExpectSel
 		goto ERROR
 	}
@@ -5188,7 +13942,7 @@ SELECTION:
 		// Followed by fieldnameoralias>
 
 		/*<check_eof>*/
-		if i.head >= len(i.str) {
+		if i.head >= len(i.str) && !i.fill(i.head) {
 			i.errc = ErrUnexpEOF
 			goto ERROR
 		}
@@ -5204,7 +13958,7 @@ SELECTION:
 		i.head++
 		for {
 			if i.head+7 >= len(i.str) {
-				for ; i.head < len(i.str); i.head++ {
+				for ; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 					if i.str[i.head] == '_' ||
 						(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
 						(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
@@ -5283,12 +14037,14 @@ SELECTION:
 		}
 
 		// <ExpectFieldNameOrAlias after name>
-		head := i.head
+		// head is relative to the stream because the window
+		// may move while looking for the colon.
+		head := i.offset + i.head
 
 		/*<skip_irrelevant>*/
 		for {
 			if i.head+7 >= len(i.str) {
-				for i.head < len(i.str) {
+				for i.head < len(i.str) || i.fill(i.tail) {
 					if i.str[i.head] != ',' &&
 						i.str[i.head] != ' ' &&
 						i.str[i.head] != '\n' &&
@@ -5368,7 +14124,7 @@ SELECTION:
 		/*</skip_irrelevant>*/
 
 		/*<check_eof>*/
-		if i.head >= len(i.str) {
+		if i.head >= len(i.str) && !i.fill(i.tail) {
 			i.errc = ErrUnexpEOF
 			goto ERROR
 		}
@@ -5376,7 +14132,7 @@ SELECTION:
 
 		if i.str[i.head] == ':' {
 			h2 := i.head
-			i.head = head
+			i.head = head - i.offset
 			i.token = TokenFieldAlias
 			/*<callback>*/
 
@@ -5391,7 +14147,7 @@ SELECTION:
 			/*<skip_irrelevant>*/
 			for {
 				if i.head+7 >= len(i.str) {
-					for i.head < len(i.str) {
+					for i.head < len(i.str) || i.fill(i.head) {
 						if i.str[i.head] != ',' &&
 							i.str[i.head] != ' ' &&
 							i.str[i.head] != '\n' &&
@@ -5476,7 +14232,7 @@ SELECTION:
 			// Followed by fieldname>
 
 			/*<check_eof>*/
-			if i.head >= len(i.str) {
+			if i.head >= len(i.str) && !i.fill(i.head) {
 				i.errc = ErrUnexpEOF
 				goto ERROR
 			}
@@ -5492,7 +14248,7 @@ SELECTION:
 			i.head++
 			for {
 				if i.head+7 >= len(i.str) {
-					for ; i.head < len(i.str); i.head++ {
+					for ; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 						if i.str[i.head] == '_' ||
 							(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
 							(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
@@ -5586,7 +14342,7 @@ SELECTION:
 			/*</name>*/
 
 		}
-		i.head = head
+		i.head = head - i.offset
 		i.token = TokenField
 		/*<callback>*/
 
@@ -5604,7 +14360,7 @@ SELECTION:
 	}
 
 	i.expect = ExpectFrag
-	if i.head+2 >= len(i.str) {
+	if i.head+2 >= len(i.str) && !i.ahead(i.head, 3) {
 		i.errc = ErrUnexpEOF
 		if i.head+1 >= len(i.str) {
 			i.head++
@@ -5633,7 +14389,7 @@ SPREAD:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -5712,7 +14468,7 @@ SPREAD:
 	}
 	/*</skip_irrelevant>*/
 
-	if i.head+1 >= len(i.str) {
+	if i.head+1 >= len(i.str) && !i.ahead(i.head, 2) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	} else if i.str[i.head] == '#' {
@@ -5743,7 +14499,7 @@ SPREAD:
 		goto AFTER_DIR_NAME
 	} else if i.str[i.head+1] == 'n' &&
 		i.str[i.head] == 'o' {
-		if i.head+2 >= len(i.str) {
+		if i.head+2 >= len(i.str) && !i.ahead(i.head, 3) {
 			i.head = len(i.str)
 			i.errc = ErrUnexpEOF
 			goto ERROR
@@ -5766,7 +14522,7 @@ SPREAD:
 	// Followed by spreadname>
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -5782,7 +14538,7 @@ SPREAD:
 	i.head++
 	for {
 		if i.head+7 >= len(i.str) {
-			for ; i.head < len(i.str); i.head++ {
+			for ; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 				if i.str[i.head] == '_' ||
 					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
 					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
@@ -5884,7 +14640,7 @@ AFTER_DECL_VAR_NAME:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -5964,7 +14720,7 @@ AFTER_DECL_VAR_NAME:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -5987,7 +14743,7 @@ VAR_TYPE:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -6067,7 +14823,7 @@ VAR_TYPE:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -6096,7 +14852,7 @@ VAR_TYPE:
 	// Followed by vartype>
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -6112,7 +14868,7 @@ VAR_TYPE:
 	i.head++
 	for {
 		if i.head+7 >= len(i.str) {
-			for ; i.head < len(i.str); i.head++ {
+			for ; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 				if i.str[i.head] == '_' ||
 					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
 					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
@@ -6214,7 +14970,7 @@ VAR_NAME:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -6294,7 +15050,7 @@ VAR_NAME:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -6308,7 +15064,7 @@ VAR_NAME:
 	// Followed by varname>
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -6324,7 +15080,7 @@ VAR_NAME:
 	i.head++
 	for {
 		if i.head+7 >= len(i.str) {
-			for ; i.head < len(i.str); i.head++ {
+			for ; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 				if i.str[i.head] == '_' ||
 					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
 					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
@@ -6426,7 +15182,7 @@ VAR_REF_NAME:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -6506,7 +15262,7 @@ VAR_REF_NAME:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -6520,7 +15276,7 @@ VAR_REF_NAME:
 	// Followed by varrefname>
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -6536,7 +15292,7 @@ VAR_REF_NAME:
 	i.head++
 	for {
 		if i.head+7 >= len(i.str) {
-			for ; i.head < len(i.str); i.head++ {
+			for ; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 				if i.str[i.head] == '_' ||
 					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
 					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
@@ -6638,7 +15394,7 @@ DIR_NAME:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -6718,7 +15474,7 @@ DIR_NAME:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -6733,7 +15489,7 @@ DIR_NAME:
 	// Followed by dirname>
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -6749,7 +15505,7 @@ DIR_NAME:
 	i.head++
 	for {
 		if i.head+7 >= len(i.str) {
-			for ; i.head < len(i.str); i.head++ {
+			for ; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 				if i.str[i.head] == '_' ||
 					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
 					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
@@ -6828,7 +15584,7 @@ DIR_NAME:
 	}
 
 	// <ExpectDirName after name>
-	i.token = TokenDirName
+	i.token, i.dirOn = TokenDirName, dirOn
 	/*<callback>*/
 
 	if fn(i) {
@@ -6850,7 +15606,7 @@ COLUMN_AFTER_ARG_NAME:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -6930,7 +15686,7 @@ COLUMN_AFTER_ARG_NAME:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -6952,7 +15708,7 @@ COLUMN_AFTER_ARG_NAME:
 ARG_LIST:
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -6966,7 +15722,7 @@ ARG_LIST:
 	// Followed by argname>
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -6982,7 +15738,7 @@ ARG_LIST:
 	i.head++
 	for {
 		if i.head+7 >= len(i.str) {
-			for ; i.head < len(i.str); i.head++ {
+			for ; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 				if i.str[i.head] == '_' ||
 					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
 					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
@@ -7074,7 +15830,7 @@ ARG_LIST:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -7167,7 +15923,7 @@ AFTER_VAR_TYPE_NAME:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -7268,7 +16024,7 @@ AFTER_VAR_TYPE_NOT_NULL:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -7348,7 +16104,7 @@ AFTER_VAR_TYPE_NOT_NULL:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -7377,7 +16133,7 @@ AFTER_VAR_TYPE_NOT_NULL:
 		/*<skip_irrelevant>*/
 		for {
 			if i.head+7 >= len(i.str) {
-				for i.head < len(i.str) {
+				for i.head < len(i.str) || i.fill(i.head) {
 					if i.str[i.head] != ',' &&
 						i.str[i.head] != ' ' &&
 						i.str[i.head] != '\n' &&
@@ -7484,7 +16240,7 @@ AFTER_FIELD_NAME:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -7564,7 +16320,7 @@ AFTER_FIELD_NAME:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -7589,7 +16345,7 @@ AFTER_FIELD_NAME:
 		/*<skip_irrelevant>*/
 		for {
 			if i.head+7 >= len(i.str) {
-				for i.head < len(i.str) {
+				for i.head < len(i.str) || i.fill(i.head) {
 					if i.str[i.head] != ',' &&
 						i.str[i.head] != ' ' &&
 						i.str[i.head] != '\n' &&
@@ -7692,7 +16448,7 @@ AFTER_OPR_NAME:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -7772,7 +16528,7 @@ AFTER_OPR_NAME:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc, i.expect = ErrUnexpEOF, ExpectSelSet
 		goto ERROR
 	}
@@ -7815,7 +16571,7 @@ FRAG_KEYWORD_ON:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -7894,7 +16650,7 @@ FRAG_KEYWORD_ON:
 	}
 	/*</skip_irrelevant>*/
 
-	if i.head+1 >= len(i.str) {
+	if i.head+1 >= len(i.str) && !i.ahead(i.head, 2) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	} else if i.str[i.head] == '#' {
@@ -7913,7 +16669,7 @@ FRAG_TYPE_COND:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -7993,7 +16749,7 @@ FRAG_TYPE_COND:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -8007,7 +16763,7 @@ FRAG_TYPE_COND:
 	// Followed by fragtypecond>
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -8023,7 +16779,7 @@ FRAG_TYPE_COND:
 	i.head++
 	for {
 		if i.head+7 >= len(i.str) {
-			for ; i.head < len(i.str); i.head++ {
+			for ; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 				if i.str[i.head] == '_' ||
 					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
 					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
@@ -8115,7 +16871,7 @@ FRAG_TYPE_COND:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -8195,7 +16951,7 @@ FRAG_TYPE_COND:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc, i.expect = ErrUnexpEOF, ExpectSelSet
 		goto ERROR
 	}
@@ -8219,7 +16975,7 @@ FRAG_INLINED:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -8299,7 +17055,7 @@ FRAG_INLINED:
 	/*</skip_irrelevant>*/
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -8313,7 +17069,7 @@ FRAG_INLINED:
 	// Followed by fraginlined>
 
 	/*<check_eof>*/
-	if i.head >= len(i.str) {
+	if i.head >= len(i.str) && !i.fill(i.head) {
 		i.errc = ErrUnexpEOF
 		goto ERROR
 	}
@@ -8329,7 +17085,7 @@ FRAG_INLINED:
 	i.head++
 	for {
 		if i.head+7 >= len(i.str) {
-			for ; i.head < len(i.str); i.head++ {
+			for ; i.head < len(i.str) || i.fill(i.tail); i.head++ {
 				if i.str[i.head] == '_' ||
 					(i.str[i.head] >= '0' && i.str[i.head] <= '9') ||
 					(i.str[i.head] >= 'a' && i.str[i.head] <= 'z') ||
@@ -8430,7 +17186,8 @@ COMMENT:
 	i.head++
 	for {
 		if i.head+7 >= len(i.str) {
-			for ; i.head < len(i.str) && i.str[i.head] != '\n'; i.head++ {
+			for ; (i.head < len(i.str) || i.fill(i.head)) &&
+				i.str[i.head] != '\n'; i.head++ {
 			}
 			break
 		}
@@ -8482,7 +17239,7 @@ COMMENT:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -8627,7 +17384,7 @@ DEFINITION_END:
 	/*<skip_irrelevant>*/
 	for {
 		if i.head+7 >= len(i.str) {
-			for i.head < len(i.str) {
+			for i.head < len(i.str) || i.fill(i.head) {
 				if i.str[i.head] != ',' &&
 					i.str[i.head] != ' ' &&
 					i.str[i.head] != '\n' &&
@@ -8716,11 +17473,12 @@ DEFINITION_END:
 ERROR:
 	{
 		var atIndex rune
-		if i.head < len(i.str) {
+		if i.head+utf8.UTFMax <= len(i.str) || i.ahead(i.head, utf8.UTFMax) ||
+			i.head < len(i.str) {
 			atIndex, _ = utf8.DecodeRune(i.str[i.head:])
 		}
 		return Error{
-			Index:       i.head,
+			Index:       i.offset + i.head,
 			AtIndex:     atIndex,
 			Code:        i.errc,
 			Expectation: i.expect,
@@ -8747,8 +17505,11 @@ func ScanAll(str []byte, fn func(*Iterator)) Error {
 	i.expect = ExpectDef
 	i.tail, i.head = -1, 0
 	i.str = str
+	i.r = nil
 	i.levelSel = 0
+	i.offset = 0
 	i.errc = 0
+	i.path.on = false
 	defer iteratorPool.Put(i)
 
 	// inDefVal triggers different expectations after values
@@ -8854,7 +17615,7 @@ DEFINITION:
 		i.expect = ExpectDef
 		goto COMMENT
 	} else if i.str[i.head] == '{' {
-		i.token = TokenDefQry
+		i.token, i.def = TokenDefQry, TokenDefQry
 		/*<callback>*/
 
 		fn(i)
@@ -8864,7 +17625,7 @@ DEFINITION:
 		goto SELECTION_SET
 	} else if i.isHeadKeywordQuery() {
 		// Query
-		i.token = TokenDefQry
+		i.token, i.def = TokenDefQry, TokenDefQry
 		/*<callback>*/
 
 		fn(i)
@@ -8875,7 +17636,7 @@ DEFINITION:
 		goto AFTER_DEF_KEYWORD
 	} else if i.isHeadKeywordMutation() {
 		// Mutation
-		i.token = TokenDefMut
+		i.token, i.def = TokenDefMut, TokenDefMut
 		/*<callback>*/
 
 		fn(i)
@@ -8886,7 +17647,7 @@ DEFINITION:
 		goto AFTER_DEF_KEYWORD
 	} else if i.isHeadKeywordSubscription() {
 		// Subscription
-		i.token = TokenDefSub
+		i.token, i.def = TokenDefSub, TokenDefSub
 		/*<callback>*/
 
 		fn(i)
@@ -8898,7 +17659,7 @@ DEFINITION:
 	} else if i.isHeadKeywordFragment() {
 		// Fragment
 		i.tail = -1
-		i.token = TokenDefFrag
+		i.token, i.def = TokenDefFrag, TokenDefFrag
 		/*<callback>*/
 
 		fn(i)
@@ -10127,6 +18888,9 @@ AFTER_DIR_ARGS:
 			i.head++
 			i.expect = ExpectDir
 			goto DIR_NAME
+		case ')':
+			dirOn = 0
+			goto VAR_LIST_END
 		default:
 			i.expect, dirOn = ExpectAfterVarType, 0
 			goto OPR_VAR
@@ -10168,7 +18932,7 @@ AFTER_DIR_ARGS:
 			i.expect = ExpectDir
 			goto DIR_NAME
 		default:
-			i.expect = ExpectSelSet
+			i.expect, dirOn = ExpectSelSet, 0
 			goto SELECTION_SET
 		}
 	default:
@@ -11406,6 +20170,10 @@ VALUE:
 
 		// <ExpectObjFieldName after name>
 		i.token = TokenObjField
+		i.objFields[len(i.objFields)-1] = Span{
+			Start: i.tail,
+			End:   i.head,
+		}
 		/*<callback>*/
 
 		fn(i)
@@ -12381,7 +21149,7 @@ VALUE:
 		case '0':
 			// Leading zero
 			i.head++
-			if len(i.str) > i.head {
+			if i.head < len(i.str) {
 				if i.str[i.head] == '.' {
 					i.head++
 					goto FRACTION
@@ -12721,12 +21489,14 @@ BLOCK_STRING:
 		/*</check_eof>*/
 
 		if i.str[i.head] == '\\' &&
+			i.head+3 < len(i.str) &&
 			i.str[i.head+3] == '"' &&
 			i.str[i.head+2] == '"' &&
 			i.str[i.head+1] == '"' {
 			i.head += len(`\"""`)
 			continue
 		} else if i.str[i.head] == '"' &&
+			i.head+2 < len(i.str) &&
 			i.str[i.head+2] == '"' &&
 			i.str[i.head+1] == '"' {
 			i.token = TokenStrBlock
@@ -12736,6 +21506,7 @@ BLOCK_STRING:
 
 			/*</callback>*/
 			i.head += len(`"""`)
+			i.expect = ExpectAfterValueInner
 			goto AFTER_VALUE_INNER
 		} else if i.str[i.head] < 0x20 &&
 			i.str[i.head] != '\t' &&
@@ -13047,6 +21818,10 @@ AFTER_VALUE_INNER:
 
 			// <ExpectObjFieldName after name>
 			i.token = TokenObjField
+			i.objFields[len(i.objFields)-1] = Span{
+				Start: i.tail,
+				End:   i.head,
+			}
 			/*<callback>*/
 
 			fn(i)
@@ -15414,7 +24189,7 @@ DIR_NAME:
 	}
 
 	// <ExpectDirName after name>
-	i.token = TokenDirName
+	i.token, i.dirOn = TokenDirName, dirOn
 	/*<callback>*/
 
 	fn(i)
@@ -17300,22 +26075,44 @@ type Iterator struct {
 	// and is reset for every argument.
 	stack []Token
 
+	// objFields holds the span of the name of the current field
+	// of each object on the stack relative to the stream.
+	objFields []Span
+
 	expect Expect
 	token  Token
 
 	// str holds the original source
+	// or the current window when scanning a reader.
 	str []byte
 
+	// r is the input when scanning a reader, otherwise nil.
+	r *reader
+
 	// tail and head represent the iterator tail and head indexes
 	tail, head int
 	levelSel   int
 
+	// offset is added to the indexes returned by IndexHead and IndexTail
+	// and is the position of str in the stream when scanning a reader.
+	offset int
+
 	// errc holds the recent error code
 	errc ErrorCode
+
+	// def is the token of the current definition.
+	def Token
+
+	// dirOn is the target of the current directive.
+	dirOn dirTarget
+
+	// path is only maintained by ScanPath and ScanAllPath.
+	path pathTracker
 }
 
 func (i *Iterator) stackReset() {
 	i.stack = i.stack[:0]
+	i.objFields = i.objFields[:0]
 }
 
 func (i *Iterator) stackLen() int {
@@ -17325,6 +26122,7 @@ func (i *Iterator) stackLen() int {
 // stackPush pushes a new token onto the stack.
 func (i *Iterator) stackPush(t Token) {
 	i.stack = append(i.stack, t)
+	i.objFields = append(i.objFields, Span{})
 }
 
 // stackPop pops the top element of the stack returning it.
@@ -17332,6 +26130,7 @@ func (i *Iterator) stackPush(t Token) {
 func (i *Iterator) stackPop() {
 	if l := len(i.stack); l > 0 {
 		i.stack = i.stack[:l-1]
+		i.objFields = i.objFields[:l-1]
 	}
 }
 
@@ -17346,7 +26145,8 @@ func (i *Iterator) stackTop() Token {
 var iteratorPool = sync.Pool{
 	New: func() interface{} {
 		return &Iterator{
-			stack: make([]Token, 64),
+			stack:     make([]Token, 64),
+			objFields: make([]Span, 64),
 		}
 	},
 }
@@ -17356,15 +26156,54 @@ func (i *Iterator) LevelSelect() int {
 	return i.levelSel
 }
 
+// ValueDepth returns the number of arrays and objects
+// enclosing the current token inside of a value.
+// Arrays and objects don't enclose their own start and end tokens.
+func (i *Iterator) ValueDepth() int {
+	return len(i.stack)
+}
+
+// ValueStackAt returns either TokenArr or TokenObj for the array or
+// object enclosing the current token at depth n, where 0 is the outermost
+// and ValueDepth()-1 the innermost one.
+// Returns 0 if n is out of range.
+func (i *Iterator) ValueStackAt(n int) Token {
+	if n < 0 || n >= len(i.stack) {
+		return 0
+	}
+	return i.stack[n]
+}
+
+// ValueObjField returns the name of the field of the innermost object
+// enclosing the current token that is currently being assigned.
+// Returns nil if the current token isn't enclosed by an object
+// or, when scanning a reader, if the name is no longer in the window.
+func (i *Iterator) ValueObjField() []byte {
+	for n := len(i.stack) - 1; n >= 0; n-- {
+		if i.stack[n] == TokenObj {
+			f := i.objFields[n]
+			if f.Start < i.offset {
+				// The name is no longer in the window
+				return nil
+			}
+			return i.str[f.Start-i.offset : f.End-i.offset]
+		}
+	}
+	return nil
+}
+
 // IndexHead returns the current head index.
 func (i *Iterator) IndexHead() int {
-	return i.head
+	return i.offset + i.head
 }
 
 // IndexTail returns the current tail index.
 // Returns -1 if the current token doesn't reflect a dynamic value.
 func (i *Iterator) IndexTail() int {
-	return i.tail
+	if i.tail < 0 {
+		return -1
+	}
+	return i.offset + i.tail
 }
 
 // Token returns the current token type.
@@ -17372,6 +26211,36 @@ func (i *Iterator) Token() Token {
 	return i.token
 }
 
+// DirectiveLocation returns the location of the current directive
+// if the current token is TokenDirName, otherwise returns 0.
+func (i *Iterator) DirectiveLocation() DirectiveLocation {
+	if i.token != TokenDirName {
+		return 0
+	}
+	switch i.dirOn {
+	case dirOpr:
+		switch i.def {
+		case TokenDefMut:
+			return DirLocMutation
+		case TokenDefSub:
+			return DirLocSubscription
+		}
+		return DirLocQuery
+	case dirVar:
+		return DirLocVarDef
+	case dirField:
+		return DirLocField
+	case dirFragRef:
+		return DirLocFragSpread
+	case dirFragInlineOrDef:
+		if i.levelSel < 1 {
+			return DirLocFragDef
+		}
+		return DirLocFragInline
+	}
+	return 0
+}
+
 // Value returns the raw value of the current token.
 // For TokenStrBlock it's the raw uninterpreted body of the string,
 // use ScanInterpreted for the interpreted value of the block string.
@@ -17929,6 +26798,47 @@ func (e Error) Error() string {
 	return b.String()
 }
 
+// DirectiveLocation is the location of a directive
+// as defined by ExecutableDirectiveLocation in the GraphQL specification.
+type DirectiveLocation int
+
+// Executable directive locations
+const (
+	_ DirectiveLocation = iota
+	DirLocQuery
+	DirLocMutation
+	DirLocSubscription
+	DirLocField
+	DirLocFragDef
+	DirLocFragSpread
+	DirLocFragInline
+	DirLocVarDef
+)
+
+// String returns the name of the location
+// as defined by the GraphQL specification.
+func (l DirectiveLocation) String() string {
+	switch l {
+	case DirLocQuery:
+		return "QUERY"
+	case DirLocMutation:
+		return "MUTATION"
+	case DirLocSubscription:
+		return "SUBSCRIPTION"
+	case DirLocField:
+		return "FIELD"
+	case DirLocFragDef:
+		return "FRAGMENT_DEFINITION"
+	case DirLocFragSpread:
+		return "FRAGMENT_SPREAD"
+	case DirLocFragInline:
+		return "INLINE_FRAGMENT"
+	case DirLocVarDef:
+		return "VARIABLE_DEFINITION"
+	}
+	return ""
+}
+
 type dirTarget int
 
 const (
@@ -1805,6 +1805,94 @@ var testdata = []TestInput{
 		Token(gqlscan.TokenField, "f"),
 		Token(gqlscan.TokenSetEnd),
 	),
+	Input(`query ($v: String @d (a:0)) {f}`,
+		Token(gqlscan.TokenDefQry),
+		Token(gqlscan.TokenVarList),
+		Token(gqlscan.TokenVarName, "v"),
+		Token(gqlscan.TokenVarTypeName, "String"),
+		Token(gqlscan.TokenDirName, "d"),
+		Token(gqlscan.TokenArgList),
+		Token(gqlscan.TokenArgName, "a"),
+		Token(gqlscan.TokenInt, "0"),
+		Token(gqlscan.TokenArgListEnd),
+		Token(gqlscan.TokenVarListEnd),
+		Token(gqlscan.TokenSet),
+		Token(gqlscan.TokenField, "f"),
+		Token(gqlscan.TokenSetEnd),
+	),
+	Input(`{...on T @d(a:0) {f(a:1)}}`,
+		Token(gqlscan.TokenDefQry),
+		Token(gqlscan.TokenSet),
+		Token(gqlscan.TokenFragInline, "T"),
+		Token(gqlscan.TokenDirName, "d"),
+		Token(gqlscan.TokenArgList),
+		Token(gqlscan.TokenArgName, "a"),
+		Token(gqlscan.TokenInt, "0"),
+		Token(gqlscan.TokenArgListEnd),
+		Token(gqlscan.TokenSet),
+		Token(gqlscan.TokenField, "f"),
+		Token(gqlscan.TokenArgList),
+		Token(gqlscan.TokenArgName, "a"),
+		Token(gqlscan.TokenInt, "1"),
+		Token(gqlscan.TokenArgListEnd),
+		Token(gqlscan.TokenSetEnd),
+		Token(gqlscan.TokenSetEnd),
+	),
+	Input( // Field directives following inline fragment directive arguments.
+		`{...on T @d(a:0) {f(a:1) @e(b:2) g ...on U @d(a:0) {h(a:1)}}}`,
+		Token(gqlscan.TokenDefQry),
+		Token(gqlscan.TokenSet),
+		Token(gqlscan.TokenFragInline, "T"),
+		Token(gqlscan.TokenDirName, "d"),
+		Token(gqlscan.TokenArgList),
+		Token(gqlscan.TokenArgName, "a"),
+		Token(gqlscan.TokenInt, "0"),
+		Token(gqlscan.TokenArgListEnd),
+		Token(gqlscan.TokenSet),
+		Token(gqlscan.TokenField, "f"),
+		Token(gqlscan.TokenArgList),
+		Token(gqlscan.TokenArgName, "a"),
+		Token(gqlscan.TokenInt, "1"),
+		Token(gqlscan.TokenArgListEnd),
+		Token(gqlscan.TokenDirName, "e"),
+		Token(gqlscan.TokenArgList),
+		Token(gqlscan.TokenArgName, "b"),
+		Token(gqlscan.TokenInt, "2"),
+		Token(gqlscan.TokenArgListEnd),
+		Token(gqlscan.TokenField, "g"),
+		Token(gqlscan.TokenFragInline, "U"),
+		Token(gqlscan.TokenDirName, "d"),
+		Token(gqlscan.TokenArgList),
+		Token(gqlscan.TokenArgName, "a"),
+		Token(gqlscan.TokenInt, "0"),
+		Token(gqlscan.TokenArgListEnd),
+		Token(gqlscan.TokenSet),
+		Token(gqlscan.TokenField, "h"),
+		Token(gqlscan.TokenArgList),
+		Token(gqlscan.TokenArgName, "a"),
+		Token(gqlscan.TokenInt, "1"),
+		Token(gqlscan.TokenArgListEnd),
+		Token(gqlscan.TokenSetEnd),
+		Token(gqlscan.TokenSetEnd),
+		Token(gqlscan.TokenSetEnd),
+	),
+	Input(`fragment F on T @d(a:0) {f(a:1)}`,
+		Token(gqlscan.TokenDefFrag),
+		Token(gqlscan.TokenFragName, "F"),
+		Token(gqlscan.TokenFragTypeCond, "T"),
+		Token(gqlscan.TokenDirName, "d"),
+		Token(gqlscan.TokenArgList),
+		Token(gqlscan.TokenArgName, "a"),
+		Token(gqlscan.TokenInt, "0"),
+		Token(gqlscan.TokenArgListEnd),
+		Token(gqlscan.TokenSet),
+		Token(gqlscan.TokenField, "f"),
+		Token(gqlscan.TokenArgList),
+		Token(gqlscan.TokenArgName, "a"),
+		Token(gqlscan.TokenInt, "1"),
+		Token(gqlscan.TokenArgListEnd),
+		Token(gqlscan.TokenSetEnd),
+	),
 	Input(`{
 		a (a: 0) @d1 @d2 (a:$v) @d3 {
 			aa (a: 0) @d1 @d2 (a:$v) @d3
@@ -2356,6 +2444,22 @@ var testdata = []TestInput{
 		Token(gqlscan.TokenArgListEnd),
 		Token(gqlscan.TokenSetEnd),
 	),
+	Input( // Variable directive arguments ending the variable list.
+		`query ($v: String @d(a:0)) {f}`,
+		Token(gqlscan.TokenDefQry),
+		Token(gqlscan.TokenVarList),
+		Token(gqlscan.TokenVarName, "v"),
+		Token(gqlscan.TokenVarTypeName, "String"),
+		Token(gqlscan.TokenDirName, "d"),
+		Token(gqlscan.TokenArgList),
+		Token(gqlscan.TokenArgName, "a"),
+		Token(gqlscan.TokenInt, "0"),
+		Token(gqlscan.TokenArgListEnd),
+		Token(gqlscan.TokenVarListEnd),
+		Token(gqlscan.TokenSet),
+		Token(gqlscan.TokenField, "f"),
+		Token(gqlscan.TokenSetEnd),
+	),
 }
 
 //go:embed testdata/t_s_2695b.txt
@@ -3069,6 +3173,31 @@ var testdataErr = []TestInputErr{
 		"error at index 9: unexpected end of file; "+
 			"expected end of block string",
 	),
+	InputErr( // Unexpected EOF after backslash.
+		`{f(a:"""\`,
+		"error at index 9: unexpected end of file; "+
+			"expected end of block string",
+	),
+	InputErr( // Unexpected EOF after quotes.
+		`{f(a:"""ab""`,
+		"error at index 12: unexpected end of file; "+
+			"expected end of block string",
+	),
+	InputErr( // Unexpected EOF after block string.
+		`{f(a:"""x"""`,
+		"error at index 12: unexpected end of file; "+
+			"expected argument list closure or argument",
+	),
+	InputErr( // Unexpected EOF after block string in object.
+		`{f(a:{b:"""x"""`,
+		"error at index 15: unexpected end of file; "+
+			"expected argument list closure or argument",
+	),
+	InputErr( // Comment after block string in object.
+		"query($v: I = {a: \"\"\"x\"\"\"#\n query B {x}",
+		"error at index 34 ('B'): unexpected token; "+
+			"expected column after object field name",
+	),
 	InputErr( // Control character in string.
 		`{f(a:"0123456`+string(rune(0x00))+`")}`,
 		"error at index 13 (0x0): unexpected token; "+
@@ -3824,6 +3953,131 @@ func TestZeroValueToString(t *testing.T) {
 
 	var token gqlscan.Token
 	require.Zero(t, token.String())
+
+	var loc gqlscan.DirectiveLocation
+	require.Zero(t, loc.String())
+}
+
+func TestValueStack(t *testing.T) {
+	const input = `query($v: [I] = [{a: 1}]) {
+		f(a: {b: [1 {c: [[]] d: {e: null}}], f: 2}, g: 3) @d(h: [{i: 4}])
+	}`
+	var actual []string
+	err := gqlscan.ScanAll([]byte(input), func(i *gqlscan.Iterator) {
+		var b strings.Builder
+		b.WriteString(i.Token().String())
+		for n := 0; n < i.ValueDepth(); n++ {
+			switch i.ValueStackAt(n) {
+			case gqlscan.TokenArr:
+				b.WriteString(" [")
+			case gqlscan.TokenObj:
+				b.WriteString(" {")
+			}
+		}
+		if f := i.ValueObjField(); f != nil {
+			b.WriteString(" " + string(f))
+		}
+		require.Zero(t, i.ValueStackAt(-1))
+		require.Zero(t, i.ValueStackAt(i.ValueDepth()))
+		actual = append(actual, b.String())
+	})
+	require.False(t, err.IsErr(), err.Error())
+	require.Equal(t, []string{
+		"query definition",
+		"variable list",
+		"variable name",
+		"variable array type",
+		"variable type name",
+		"variable array type end",
+		"array",
+		"object [",
+		"object field [ { a",
+		"integer [ { a",
+		"object end [",
+		"array end",
+		"variable list end",
+		"selection set",
+		"field",
+		"argument list",
+		"argument name",
+		"object",
+		"object field { b",
+		"array { b",
+		"integer { [ b",
+		"object { [ b",
+		"object field { [ { c",
+		"array { [ { c",
+		"array { [ { [ c",
+		"array end { [ { [ c",
+		"array end { [ { c",
+		"object field { [ { d",
+		"object { [ { d",
+		"object field { [ { { e",
+		"null { [ { { e",
+		"object end { [ { d",
+		"object end { [ b",
+		"array end { b",
+		"object field { f",
+		"integer { f",
+		"object end",
+		"argument name",
+		"integer",
+		"argument list end",
+		"directive name",
+		"argument list",
+		"argument name",
+		"array",
+		"object [",
+		"object field [ { i",
+		"integer [ { i",
+		"object end [",
+		"array end",
+		"argument list end",
+		"selection set end",
+	}, actual)
+}
+
+func TestDirectiveLocation(t *testing.T) {
+	const input = `query Q($v: Int = 1 @v1 @v2, $w: [Int] @v3) @q1 @q2(a: 1) {
+		a @f1 {
+			b @f2(x: 1) @f3
+			... @i1 { c }
+			... on T @i2 { d ...F @s1 }
+		}
+		...F @s2(x: [1])
+	}
+	mutation @m { a }
+	subscription S @s { a }
+	fragment F on T @fd { a }
+	{ a @f4 }`
+	var actual []string
+	err := gqlscan.ScanAll([]byte(input), func(i *gqlscan.Iterator) {
+		if i.Token() != gqlscan.TokenDirName {
+			require.Zero(t, i.DirectiveLocation())
+			return
+		}
+		actual = append(actual, string(i.Value())+" "+
+			i.DirectiveLocation().String())
+	})
+	require.False(t, err.IsErr(), err.Error())
+	require.Equal(t, []string{
+		"v1 VARIABLE_DEFINITION",
+		"v2 VARIABLE_DEFINITION",
+		"v3 VARIABLE_DEFINITION",
+		"q1 QUERY",
+		"q2 QUERY",
+		"f1 FIELD",
+		"f2 FIELD",
+		"f3 FIELD",
+		"i1 INLINE_FRAGMENT",
+		"i2 INLINE_FRAGMENT",
+		"s1 FRAGMENT_SPREAD",
+		"s2 FRAGMENT_SPREAD",
+		"m MUTATION",
+		"s SUBSCRIPTION",
+		"fd FRAGMENT_DEFINITION",
+		"f4 FIELD",
+	}, actual)
 }
 
 type ExpectBlockStr struct {
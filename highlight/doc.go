@@ -0,0 +1,7 @@
+// Package highlight provides syntax highlighting of GraphQL executable
+// documents built on top of the gqlscan token stream.
+//
+// Documents are split into segments that are rendered as ANSI-colored
+// text, HTML or encoded as LSP semantic tokens.
+// Comments, keywords and punctuation are highlighted too.
+package highlight
@@ -0,0 +1,154 @@
+package highlight
+
+import (
+	"strings"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/internal/names"
+)
+
+// Kind is the kind of a segment.
+type Kind uint8
+
+// Segment kinds
+const (
+	_ Kind = iota
+	KindToken
+	KindKeyword
+	KindPunct
+	KindComment
+
+	// KindInvalid is the part of the document starting at
+	// the first syntax error.
+	KindInvalid
+)
+
+// Segment is a highlighted part of a document.
+// Whitespace and commas between segments aren't highlighted.
+type Segment struct {
+	gqlscan.Span
+	Kind Kind
+
+	// Token is the token of the segment if Kind is KindToken.
+	// Strings and block strings include their quotes, variable names
+	// and references include the dollar sign and directive names
+	// include the at sign.
+	Token gqlscan.Token
+}
+
+// Class returns the CSS class of the segment, which is either "gql-"
+// followed by the dash-separated Token name (e.g. "gql-field",
+// "gql-argument-name") or one of "gql-keyword", "gql-punctuation",
+// "gql-comment" and "gql-invalid".
+func (s Segment) Class() string {
+	switch s.Kind {
+	case KindToken:
+		return "gql-" + strings.ReplaceAll(s.Token.String(), " ", "-")
+	case KindKeyword:
+		return "gql-keyword"
+	case KindPunct:
+		return "gql-punctuation"
+	case KindComment:
+		return "gql-comment"
+	}
+	return "gql-invalid"
+}
+
+// Segments splits src into segments in order of appearance.
+// If src isn't lexically valid then the last segment is of KindInvalid
+// and spans the rest of the document starting at the first syntax error.
+func Segments(src []byte) []Segment {
+	var (
+		s []Segment
+
+		// end is the end of the last segment.
+		end int
+	)
+	err := gqlscan.ScanAll(src, func(i *gqlscan.Iterator) {
+		t := i.Token()
+		start, head := i.IndexTail(), i.IndexHead()
+		switch t {
+		case gqlscan.TokenTrue:
+			start = head - len("true")
+		case gqlscan.TokenFalse:
+			start = head - len("false")
+		case gqlscan.TokenNull:
+			start = head - len("null")
+		case gqlscan.TokenStr:
+			start, head = start-len(`"`), head+len(`"`)
+		case gqlscan.TokenStrBlock:
+			start, head = start-len(`"""`), head+len(`"""`)
+		case gqlscan.TokenVarName, gqlscan.TokenVarRef:
+			if src[start-1] == '$' {
+				start--
+			}
+		case gqlscan.TokenDirName:
+			if src[start-1] == '@' {
+				start--
+			}
+		}
+		if start < 0 {
+			// Keywords and punctuation are in the gaps
+			return
+		}
+		s, _ = appendGap(s, src, end, start)
+		s = append(s, Segment{
+			Span: gqlscan.Span{Start: start, End: head},
+			Kind: KindToken, Token: t,
+		})
+		end = head
+	})
+	if !err.IsErr() {
+		s, _ = appendGap(s, src, end, len(src))
+		return s
+	}
+	// The part of the document preceding the error is valid
+	// but may contain a partially scanned token, which is invalid.
+	s, end = appendGap(s, src, end, err.Index)
+	return append(s, Segment{
+		Span: gqlscan.Span{Start: end, End: len(src)},
+		Kind: KindInvalid,
+	})
+}
+
+// appendGap appends the segments of src[start:end] which may only
+// contain keywords, punctuation, comments, whitespace and commas.
+// Returns the index where it stopped, which is end unless
+// something else was encountered.
+func appendGap(s []Segment, src []byte, start, end int) ([]Segment, int) {
+	add := func(k Kind, e int) {
+		s = append(s, Segment{Span: gqlscan.Span{Start: start, End: e}, Kind: k})
+		start = e
+	}
+	for start < end {
+		switch c := src[start]; c {
+		case ' ', '\t', '\n', '\r', ',':
+			start++
+		case '#':
+			e := start + 1
+			for e < end && src[e] != '\n' && src[e] != '\r' {
+				e++
+			}
+			add(KindComment, e)
+		case '{', '}', '(', ')', '[', ']', ':', '=', '!', '$', '@', '|', '&':
+			add(KindPunct, start+1)
+		case '.':
+			if start+3 > end || string(src[start:start+3]) != "..." {
+				return s, start
+			}
+			add(KindPunct, start+3)
+		default:
+			e := start
+			for e < end && names.IsNameByte(src[e]) {
+				e++
+			}
+			switch string(src[start:e]) {
+			case "query", "mutation", "subscription", "fragment", "on":
+				add(KindKeyword, e)
+			default:
+				return s, start
+			}
+		}
+	}
+	return s, end
+}
@@ -0,0 +1,243 @@
+package highlight_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/graph-guard/gqlscan/highlight"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSegments(t *testing.T) {
+	for _, td := range []struct {
+		decl   string
+		input  string
+		expect []string
+	}{
+		{
+			decl(1),
+			"query Q($v: [Int!] = [1]) @d {\n" +
+				"  # comment\n" +
+				"  x: a(s: \"s\", b: \"\"\"b\"\"\", e: E, n: null, f: 1.5, v: $v)\n" +
+				"  ... on T @skip(if: true) { ...F }\n" +
+				"}",
+			[]string{
+				"gql-keyword query",
+				"gql-operation-name Q",
+				"gql-punctuation (",
+				"gql-variable-name $v",
+				"gql-punctuation :",
+				"gql-punctuation [",
+				"gql-variable-type-name Int",
+				"gql-punctuation !",
+				"gql-punctuation ]",
+				"gql-punctuation =",
+				"gql-punctuation [",
+				"gql-integer 1",
+				"gql-punctuation ]",
+				"gql-punctuation )",
+				"gql-directive-name @d",
+				"gql-punctuation {",
+				"gql-comment # comment",
+				"gql-field-alias x",
+				"gql-punctuation :",
+				"gql-field a",
+				"gql-punctuation (",
+				"gql-argument-name s",
+				"gql-punctuation :",
+				`gql-string "s"`,
+				"gql-argument-name b",
+				"gql-punctuation :",
+				`gql-block-string """b"""`,
+				"gql-argument-name e",
+				"gql-punctuation :",
+				"gql-enum-value E",
+				"gql-argument-name n",
+				"gql-punctuation :",
+				"gql-null null",
+				"gql-argument-name f",
+				"gql-punctuation :",
+				"gql-float 1.5",
+				"gql-argument-name v",
+				"gql-punctuation :",
+				"gql-variable-reference $v",
+				"gql-punctuation )",
+				"gql-punctuation ...",
+				"gql-keyword on",
+				"gql-fragment-inline T",
+				"gql-directive-name @skip",
+				"gql-punctuation (",
+				"gql-argument-name if",
+				"gql-punctuation :",
+				"gql-true true",
+				"gql-punctuation )",
+				"gql-punctuation {",
+				"gql-punctuation ...",
+				"gql-named-spread F",
+				"gql-punctuation }",
+				"gql-punctuation }",
+			},
+		},
+		{
+			decl(1),
+			"fragment F on T {a(x: false)} mutation {b}",
+			[]string{
+				"gql-keyword fragment",
+				"gql-fragment-name F",
+				"gql-keyword on",
+				"gql-fragment-type-condition T",
+				"gql-punctuation {",
+				"gql-field a",
+				"gql-punctuation (",
+				"gql-argument-name x",
+				"gql-punctuation :",
+				"gql-false false",
+				"gql-punctuation )",
+				"gql-punctuation }",
+				"gql-keyword mutation",
+				"gql-punctuation {",
+				"gql-field b",
+				"gql-punctuation }",
+			},
+		},
+		{
+			decl(1),
+			`{a(x: {o: "abc`,
+			[]string{
+				"gql-punctuation {",
+				"gql-field a",
+				"gql-punctuation (",
+				"gql-argument-name x",
+				"gql-punctuation :",
+				"gql-punctuation {",
+				"gql-object-field o",
+				"gql-punctuation :",
+				`gql-invalid "abc`,
+			},
+		},
+		{
+			decl(1),
+			"{a(x: ] b) c}",
+			[]string{
+				"gql-punctuation {",
+				"gql-field a",
+				"gql-punctuation (",
+				"gql-argument-name x",
+				"gql-punctuation :",
+				"gql-invalid ] b) c}",
+			},
+		},
+		{
+			decl(1),
+			"subscription # c",
+			[]string{
+				"gql-keyword subscription",
+				"gql-comment # c",
+				"gql-invalid ",
+			},
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			src := []byte(td.input)
+			var actual []string
+			for _, s := range highlight.Segments(src) {
+				actual = append(actual, s.Class()+" "+string(s.In(src)))
+			}
+			require.Equal(t, td.expect, actual)
+		})
+	}
+}
+
+func TestSegmentsCover(t *testing.T) {
+	for _, f := range []string{
+		"complex.graphql", "small.graphql", "longstr_blk.graphql",
+	} {
+		t.Run(f, func(t *testing.T) {
+			src, err := os.ReadFile(filepath.Join("..", "cmd", "bench", "inputs", f))
+			require.NoError(t, err)
+			end := 0
+			for _, s := range highlight.Segments(src) {
+				require.NotEqual(t, highlight.KindInvalid, s.Kind)
+				require.GreaterOrEqual(t, s.Start, end)
+				require.Empty(t, bytes.Trim(src[end:s.Start], " \t\r\n,"))
+				end = s.End
+			}
+			require.Empty(t, bytes.Trim(src[end:], " \t\r\n,"))
+		})
+	}
+}
+
+func TestANSI(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, highlight.ANSI(&b, []byte("{a # c\n b: c}"), nil))
+	require.Equal(t, "\x1b[37m{\x1b[0m\x1b[34ma\x1b[0m \x1b[90m# c\x1b[0m\n"+
+		" \x1b[36mb\x1b[0m\x1b[37m:\x1b[0m \x1b[34mc\x1b[0m\x1b[37m}\x1b[0m",
+		b.String())
+
+	b.Reset()
+	require.NoError(t, highlight.ANSI(&b, []byte("{a, b}"), map[string]string{
+		"gql-field": "1",
+	}))
+	require.Equal(t, "{\x1b[1ma\x1b[0m, \x1b[1mb\x1b[0m}", b.String())
+}
+
+func TestHTML(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, highlight.HTML(&b, []byte("{a(s: \"<&>\")}\n")))
+	require.Equal(t, `<span class="gql-punctuation">{</span>`+
+		`<span class="gql-field">a</span>`+
+		`<span class="gql-punctuation">(</span>`+
+		`<span class="gql-argument-name">s</span>`+
+		`<span class="gql-punctuation">:</span> `+
+		`<span class="gql-string">&#34;&lt;&amp;&gt;&#34;</span>`+
+		`<span class="gql-punctuation">)</span>`+
+		`<span class="gql-punctuation">}</span>`+"\n", b.String())
+}
+
+func TestSemanticTokens(t *testing.T) {
+	src := []byte("query Q($v: Int) {\r\n" +
+		"  a(s: \"😀\", b: \"\"\"x\r\n" +
+		"😀 y\"\"\") @d\n" +
+		"  # 😀\n" +
+		"  b(v: $v)\n" +
+		"}")
+	require.Equal(t, []uint32{
+		0, 0, 5, 0, 0, // query
+		0, 6, 1, 3, 1, // Q
+		0, 1, 1, 1, 0, // (
+		0, 1, 2, 8, 1, // $v
+		0, 2, 1, 1, 0, // :
+		0, 2, 3, 4, 0, // Int
+		0, 3, 1, 1, 0, // )
+		0, 2, 1, 1, 0, // {
+		1, 2, 1, 5, 0, // a
+		0, 1, 1, 1, 0, // (
+		0, 1, 1, 6, 0, // s
+		0, 1, 1, 1, 0, // :
+		0, 2, 4, 9, 0, // "😀"
+		0, 6, 1, 6, 0, // b
+		0, 1, 1, 1, 0, // :
+		0, 2, 4, 9, 0, // """x
+		1, 0, 7, 9, 0, // 😀 y"""
+		0, 7, 1, 1, 0, // )
+		0, 2, 2, 7, 0, // @d
+		1, 2, 4, 2, 0, // # 😀
+		1, 2, 1, 5, 0, // b
+		0, 1, 1, 1, 0, // (
+		0, 1, 1, 6, 0, // v
+		0, 1, 1, 1, 0, // :
+		0, 2, 2, 8, 0, // $v
+		0, 2, 1, 1, 0, // )
+		1, 0, 1, 1, 0, // }
+	}, highlight.SemanticTokens(src))
+}
+
+func decl(skipFrames int) string {
+	_, filename, line, _ := runtime.Caller(skipFrames)
+	return fmt.Sprintf("%s:%d", filepath.Base(filename), line)
+}
@@ -0,0 +1,95 @@
+package highlight
+
+import (
+	"html"
+	"io"
+)
+
+// DefaultANSIColors maps segment classes to the SGR parameters
+// used by ANSI if colors is nil.
+var DefaultANSIColors = map[string]string{
+	"gql-keyword":                 "35",
+	"gql-punctuation":             "37",
+	"gql-comment":                 "90",
+	"gql-invalid":                 "4;31",
+	"gql-operation-name":          "1;33",
+	"gql-fragment-name":           "1;33",
+	"gql-named-spread":            "33",
+	"gql-fragment-type-condition": "96",
+	"gql-fragment-inline":         "96",
+	"gql-variable-type-name":      "96",
+	"gql-field-alias":             "36",
+	"gql-field":                   "34",
+	"gql-argument-name":           "36",
+	"gql-object-field":            "36",
+	"gql-directive-name":          "93",
+	"gql-variable-name":           "91",
+	"gql-variable-reference":      "91",
+	"gql-string":                  "32",
+	"gql-block-string":            "32",
+	"gql-integer":                 "33",
+	"gql-float":                   "33",
+	"gql-true":                    "95",
+	"gql-false":                   "95",
+	"gql-null":                    "95",
+	"gql-enum-value":              "95",
+}
+
+// ANSI writes src to w with its segments colored by ANSI escape sequences.
+// colors maps segment classes (see Segment.Class) to SGR parameters,
+// segments of classes that aren't in colors aren't colored.
+// DefaultANSIColors are used if colors is nil.
+func ANSI(w io.Writer, src []byte, colors map[string]string) error {
+	if colors == nil {
+		colors = DefaultANSIColors
+	}
+	return render(w, src, func(w io.Writer, s Segment) error {
+		sgr, ok := colors[s.Class()]
+		if !ok {
+			_, err := w.Write(s.In(src))
+			return err
+		}
+		if _, err := io.WriteString(w, "\x1b["+sgr+"m"); err != nil {
+			return err
+		}
+		if _, err := w.Write(s.In(src)); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\x1b[0m")
+		return err
+	})
+}
+
+// HTML writes src to w as HTML with its segments wrapped in span elements
+// with the segment class (see Segment.Class) as CSS class.
+// Wrap the output in a pre element to preserve whitespace.
+func HTML(w io.Writer, src []byte) error {
+	return render(w, src, func(w io.Writer, s Segment) error {
+		_, err := io.WriteString(w, `<span class="`+s.Class()+`">`+
+			html.EscapeString(string(s.In(src)))+`</span>`)
+		return err
+	})
+}
+
+// render writes src to w calling fn for every non-empty segment
+// and writing the bytes between segments as is.
+func render(
+	w io.Writer, src []byte, fn func(io.Writer, Segment) error,
+) error {
+	end := 0
+	for _, s := range Segments(src) {
+		if s.Start == s.End {
+			continue
+		}
+		// Only whitespace and commas are between segments
+		if _, err := w.Write(src[end:s.Start]); err != nil {
+			return err
+		}
+		if err := fn(w, s); err != nil {
+			return err
+		}
+		end = s.End
+	}
+	_, err := w.Write(src[end:])
+	return err
+}
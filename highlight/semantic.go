@@ -0,0 +1,148 @@
+package highlight
+
+import (
+	"unicode/utf8"
+
+	"github.com/graph-guard/gqlscan"
+)
+
+// SemanticTokenTypes is the legend of the token types
+// of the semantic tokens returned by SemanticTokens.
+var SemanticTokenTypes = []string{
+	"keyword",
+	"operator",
+	"comment",
+	"function",
+	"type",
+	"property",
+	"parameter",
+	"decorator",
+	"variable",
+	"string",
+	"number",
+	"enumMember",
+}
+
+// SemanticTokenModifiers is the legend of the token modifiers
+// of the semantic tokens returned by SemanticTokens.
+var SemanticTokenModifiers = []string{
+	"declaration",
+}
+
+// semanticType returns the index of the semantic token type
+// and the modifier bits of s.
+// Returns ok=false if s isn't a semantic token.
+func semanticType(s Segment) (typ, modifiers uint32, ok bool) {
+	switch s.Kind {
+	case KindKeyword:
+		return 0, 0, true
+	case KindPunct:
+		return 1, 0, true
+	case KindComment:
+		return 2, 0, true
+	case KindToken:
+	default:
+		return 0, 0, false
+	}
+	const declaration = 1
+	switch s.Token {
+	case gqlscan.TokenOprName, gqlscan.TokenFragName:
+		return 3, declaration, true
+	case gqlscan.TokenNamedSpread:
+		return 3, 0, true
+	case gqlscan.TokenFragTypeCond, gqlscan.TokenFragInline,
+		gqlscan.TokenVarTypeName:
+		return 4, 0, true
+	case gqlscan.TokenFieldAlias, gqlscan.TokenField,
+		gqlscan.TokenObjField:
+		return 5, 0, true
+	case gqlscan.TokenArgName:
+		return 6, 0, true
+	case gqlscan.TokenDirName:
+		return 7, 0, true
+	case gqlscan.TokenVarName:
+		return 8, declaration, true
+	case gqlscan.TokenVarRef:
+		return 8, 0, true
+	case gqlscan.TokenStr, gqlscan.TokenStrBlock:
+		return 9, 0, true
+	case gqlscan.TokenInt, gqlscan.TokenFloat:
+		return 10, 0, true
+	case gqlscan.TokenTrue, gqlscan.TokenFalse, gqlscan.TokenNull,
+		gqlscan.TokenEnumVal:
+		return 11, 0, true
+	}
+	return 0, 0, false
+}
+
+// SemanticTokens returns the semantic tokens of src in the integer
+// encoding of the Language Server Protocol, where every token is
+// represented by 5 integers: the line relative to the previous token,
+// the start character relative to the previous token if on the same
+// line, the length, the index of the token type in SemanticTokenTypes
+// and the bit set of modifiers in SemanticTokenModifiers.
+//
+// Characters are counted in UTF-16 code units and segments spanning
+// multiple lines are split into one token per line.
+// The invalid part of a document isn't highlighted.
+func SemanticTokens(src []byte) []uint32 {
+	var (
+		data []uint32
+
+		// i is at line and char.
+		i, line, char int
+
+		prevLine, prevChar int
+	)
+	// advance moves i to the next line terminator before end
+	// or to end and returns the number of UTF-16 code units passed.
+	advance := func(end int) (n int) {
+		for i < end && src[i] != '\n' && src[i] != '\r' {
+			r, w := utf8.DecodeRune(src[i:])
+			if i += w; r >= 0x10000 {
+				n += 2
+			} else {
+				n++
+			}
+		}
+		return n
+	}
+	// newline moves i past the line terminator at i.
+	newline := func() {
+		if src[i] == '\r' && i+1 < len(src) && src[i+1] == '\n' {
+			i++
+		}
+		i++
+		line, char = line+1, 0
+	}
+
+	for _, s := range Segments(src) {
+		typ, mod, ok := semanticType(s)
+		if !ok {
+			continue
+		}
+		for i < s.Start {
+			if char += advance(s.Start); i < s.Start {
+				newline()
+			}
+		}
+		for i < s.End {
+			n := advance(s.End)
+			if n > 0 {
+				deltaChar := char
+				if line == prevLine {
+					deltaChar -= prevChar
+				}
+				data = append(data,
+					uint32(line-prevLine), uint32(deltaChar),
+					uint32(n), typ, mod,
+				)
+				prevLine, prevChar = line, char
+			}
+			if char += n; i < s.End {
+				newline()
+			}
+		}
+	}
+	return data
+}
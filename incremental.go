@@ -0,0 +1,160 @@
+package gqlscan
+
+import "sort"
+
+// TokenRecord is a recorded token.
+type TokenRecord struct {
+	Token Token
+
+	// Head and Tail are the indexes returned by
+	// Iterator.IndexHead and Iterator.IndexTail.
+	Head, Tail int
+
+	// LevelSelect is the level returned by Iterator.LevelSelect.
+	LevelSelect int
+}
+
+// Tokenize returns the tokens of src up to the first error
+// and the error.
+func Tokenize(src []byte) ([]TokenRecord, Error) {
+	var tokens []TokenRecord
+	err := ScanAll(src, func(i *Iterator) {
+		tokens = append(tokens, record(i))
+	})
+	return tokens, err
+}
+
+func record(i *Iterator) TokenRecord {
+	return TokenRecord{
+		Token:       i.Token(),
+		Head:        i.IndexHead(),
+		Tail:        i.IndexTail(),
+		LevelSelect: i.LevelSelect(),
+	}
+}
+
+// Edit is a change of a document replacing Deleted bytes
+// at Offset with Inserted.
+type Edit struct {
+	Offset, Deleted int
+	Inserted        []byte
+}
+
+// Change is the change of a token stream caused by an Edit.
+type Change struct {
+	// Start and End are the range [Start, End) of the tokens
+	// of the old token stream replaced with Tokens.
+	Start, End int
+
+	// Tokens are the new tokens.
+	Tokens []TokenRecord
+
+	// Delta is the shift of the indexes of the tokens following End.
+	Delta int
+
+	// Err is the error of the new document.
+	Err Error
+}
+
+// Rescan returns the change of the token stream of a document caused
+// by edit e, where old and oldErr are the tokens and the error returned
+// by Tokenize for the document before the edit and src is
+// the document after the edit.
+//
+// Rescan restarts scanning at the start of the definition the edit
+// is in and stops at the first definition following the edit
+// whose tokens are the same as before the edit, thus the cost of
+// a rescan doesn't depend on the size of the document.
+func Rescan(old []TokenRecord, oldErr Error, src []byte, e Edit) Change {
+	c := Change{Delta: len(e.Inserted) - e.Deleted}
+
+	// Restart at the last definition starting at or before the edit
+	restart := 0
+	for n := len(old) - 1; n >= 0; n-- {
+		if isDef(old[n].Token) && old[n].Head <= e.Offset {
+			c.Start, restart = n, old[n].Head
+			break
+		}
+	}
+
+	editEnd := e.Offset + len(e.Inserted)
+	// j is the index of the next old token that may resynchronize
+	// the token streams, only tokens following the edit can.
+	j := sort.Search(len(old), func(n int) bool {
+		return old[n].Head >= e.Offset+e.Deleted
+	})
+	c.End = len(old)
+	synced := false
+	err := Scan(src[restart:], func(i *Iterator) (err bool) {
+		t := shift(record(i), restart)
+		if isDef(t.Token) && t.Head >= editEnd {
+			// Definitions are scanned independently of one another,
+			// all following tokens are the same if the definition is.
+			o := shift(t, -c.Delta)
+			for ; j < len(old) && old[j].Head <= o.Head; j++ {
+				if old[j] == o {
+					c.End, synced = j, true
+					return true
+				}
+			}
+		}
+		c.Tokens = append(c.Tokens, t)
+		return false
+	})
+	switch {
+	case synced:
+		if c.Err = oldErr; c.Err.IsErr() {
+			c.Err.Index += c.Delta
+		}
+	case err.IsErr():
+		err.Index += restart
+		c.Err = err
+	}
+
+	// Trim the tokens that didn't change
+	for len(c.Tokens) > 0 && c.Start < c.End &&
+		c.Tokens[0] == old[c.Start] && c.Tokens[0].Head < e.Offset {
+		c.Tokens, c.Start = c.Tokens[1:], c.Start+1
+	}
+	for len(c.Tokens) > 0 && c.Start < c.End {
+		t := c.Tokens[len(c.Tokens)-1]
+		start := t.Tail
+		if start < 0 {
+			start = t.Head
+		}
+		if start < editEnd || shift(t, -c.Delta) != old[c.End-1] {
+			break
+		}
+		c.Tokens, c.End = c.Tokens[:len(c.Tokens)-1], c.End-1
+	}
+	return c
+}
+
+// Apply returns the token stream after the change
+// given the token stream old before the change.
+func (c Change) Apply(old []TokenRecord) []TokenRecord {
+	tokens := make([]TokenRecord, 0, len(old)-(c.End-c.Start)+len(c.Tokens))
+	tokens = append(tokens, old[:c.Start]...)
+	tokens = append(tokens, c.Tokens...)
+	for _, t := range old[c.End:] {
+		tokens = append(tokens, shift(t, c.Delta))
+	}
+	return tokens
+}
+
+// shift returns t with its indexes shifted by delta.
+func shift(t TokenRecord, delta int) TokenRecord {
+	t.Head += delta
+	if t.Tail > -1 {
+		t.Tail += delta
+	}
+	return t
+}
+
+func isDef(t Token) bool {
+	switch t {
+	case TokenDefQry, TokenDefMut, TokenDefSub, TokenDefFrag:
+		return true
+	}
+	return false
+}
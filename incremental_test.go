@@ -0,0 +1,113 @@
+package gqlscan_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graph-guard/gqlscan"
+
+	"github.com/stretchr/testify/require"
+)
+
+func edit(src string, e gqlscan.Edit) string {
+	return src[:e.Offset] + string(e.Inserted) + src[e.Offset+e.Deleted:]
+}
+
+func TestRescan(t *testing.T) {
+	for _, td := range []struct {
+		decl   string
+		input  string
+		edit   gqlscan.Edit
+		expect gqlscan.Change
+	}{
+		{
+			decl(1), "{a} query Q {bar} {c}",
+			gqlscan.Edit{Offset: 13, Deleted: 3, Inserted: []byte("x")},
+			gqlscan.Change{
+				Start: 7, End: 8, Delta: -2,
+				Tokens: []gqlscan.TokenRecord{{
+					Token: gqlscan.TokenField, Head: 14, Tail: 13, LevelSelect: 1,
+				}},
+			},
+		},
+		{
+			decl(1), "{a} {b}",
+			gqlscan.Edit{Offset: 3, Inserted: []byte(" ")},
+			gqlscan.Change{
+				Start: 4, End: 4, Delta: 1, Tokens: []gqlscan.TokenRecord{},
+			},
+		},
+		{
+			decl(1), "{a} {b}",
+			gqlscan.Edit{Offset: 2, Inserted: []byte(" c")},
+			gqlscan.Change{
+				// Field a is rescanned since it ends at the edit
+				Start: 2, End: 3, Delta: 2,
+				Tokens: []gqlscan.TokenRecord{{
+					Token: gqlscan.TokenField, Head: 2, Tail: 1, LevelSelect: 1,
+				}, {
+					Token: gqlscan.TokenField, Head: 4, Tail: 3, LevelSelect: 1,
+				}},
+			},
+		},
+		{
+			decl(1), "{a} {b}",
+			gqlscan.Edit{Offset: 2, Deleted: 1},
+			gqlscan.Change{
+				Start: 2, End: 8, Delta: -1,
+				Tokens: []gqlscan.TokenRecord{{
+					Token: gqlscan.TokenField, Head: 2, Tail: 1, LevelSelect: 1,
+				}, {
+					Token: gqlscan.TokenSet, Head: 3, Tail: -1, LevelSelect: 1,
+				}, {
+					Token: gqlscan.TokenField, Head: 5, Tail: 4, LevelSelect: 2,
+				}, {
+					Token: gqlscan.TokenSetEnd, Head: 5, Tail: -1, LevelSelect: 2,
+				}},
+				Err: gqlscan.Error{
+					Index:       6,
+					Code:        gqlscan.ErrUnexpEOF,
+					Expectation: gqlscan.ExpectAfterSelection,
+				},
+			},
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			old, oldErr := gqlscan.Tokenize([]byte(td.input))
+			require.False(t, oldErr.IsErr(), oldErr.Error())
+			c := gqlscan.Rescan(old, oldErr, []byte(edit(td.input, td.edit)), td.edit)
+			require.Equal(t, td.expect, c)
+		})
+	}
+}
+
+func TestRescanApply(t *testing.T) {
+	var b strings.Builder
+	for _, td := range testdata {
+		b.WriteString(td.input)
+		b.WriteString("\n")
+	}
+	input := b.String()
+	old, oldErr := gqlscan.Tokenize([]byte(input))
+	require.False(t, oldErr.IsErr(), oldErr.Error())
+
+	inserts := []string{"", "x", "}", "{", `"`, "#", " query {a} ", "\n"}
+	for offset := 0; offset < len(input); offset += 13 {
+		for deleted := 0; deleted < 3 && offset+deleted <= len(input); deleted++ {
+			for _, ins := range inserts {
+				e := gqlscan.Edit{
+					Offset: offset, Deleted: deleted, Inserted: []byte(ins),
+				}
+				src := []byte(edit(input, e))
+				expect, expectErr := gqlscan.Tokenize(src)
+				c := gqlscan.Rescan(old, oldErr, src, e)
+				if actual := c.Apply(old); len(expect) > 0 {
+					require.Equal(t, expect, actual, "edit: %#v", e)
+				} else {
+					require.Empty(t, actual, "edit: %#v", e)
+				}
+				require.Equal(t, expectErr, c.Err, "edit: %#v", e)
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package gqlscan
+
+// Definition is an executable definition recorded by MakeIndex.
+type Definition struct {
+	// Token is either TokenDefQry, TokenDefMut, TokenDefSub
+	// or TokenDefFrag.
+	Token Token
+
+	// Name is the operation or fragment name.
+	// Nil for anonymous operations.
+	Name []byte
+
+	// TypeCond is the fragment type condition.
+	// Nil for operations.
+	TypeCond []byte
+
+	// Span is the span of the whole definition.
+	Span Span
+
+	// Vars are the names of the declared variables.
+	// Nil for fragments.
+	Vars [][]byte
+
+	// Spreads are the names of the fragments spread in the definition
+	// in order of appearance (including duplicates).
+	Spreads [][]byte
+}
+
+// IsOpr returns true if the definition is an operation,
+// otherwise returns false.
+func (d *Definition) IsOpr() bool {
+	return d.Token != TokenDefFrag
+}
+
+// Index lists all executable definitions of a document
+// in order of appearance.
+type Index struct {
+	Defs []Definition
+}
+
+// MakeIndex scans src and lists all its executable definitions
+// without building a syntax tree.
+//
+// All byte slices in the returned index refer to the same underlying
+// memory as src.
+func MakeIndex(src []byte) (Index, Error) {
+	var x Index
+	err := ScanAll(src, x.Add)
+	if err.IsErr() {
+		return Index{}, err
+	}
+	return x, err
+}
+
+// Add records the current token of i in the index.
+// It allows building an index during a scan that also serves
+// other purposes. Add must be called for every token of the scan
+// starting at the beginning of the document.
+func (x *Index) Add(i *Iterator) {
+	switch i.Token() {
+	case TokenDefQry, TokenDefMut, TokenDefSub, TokenDefFrag:
+		x.Defs = append(x.Defs, Definition{
+			Token: i.Token(),
+			Span:  Span{Start: i.IndexHead()},
+		})
+		return
+	}
+	d := &x.Defs[len(x.Defs)-1]
+	switch i.Token() {
+	case TokenOprName, TokenFragName:
+		d.Name = i.Value()
+	case TokenFragTypeCond:
+		d.TypeCond = i.Value()
+	case TokenVarName:
+		d.Vars = append(d.Vars, i.Value())
+	case TokenNamedSpread:
+		d.Spreads = append(d.Spreads, i.Value())
+	case TokenSetEnd:
+		if i.LevelSelect() == 1 {
+			d.Span.End = i.IndexHead() + 1
+		}
+	}
+}
+
+// Oprs calls fn for every operation definition
+// until fn returns true.
+func (x Index) Oprs(fn func(*Definition) (stop bool)) {
+	for d := range x.Defs {
+		if x.Defs[d].IsOpr() && fn(&x.Defs[d]) {
+			return
+		}
+	}
+}
+
+// Opr returns the first operation definition with the given name.
+// Returns the first anonymous operation if name is empty.
+// Returns nil if no such operation is defined.
+func (x Index) Opr(name []byte) *Definition {
+	for d := range x.Defs {
+		if x.Defs[d].IsOpr() && string(x.Defs[d].Name) == string(name) {
+			return &x.Defs[d]
+		}
+	}
+	return nil
+}
+
+// Frag returns the first fragment definition with the given name.
+// Returns nil if no such fragment is defined.
+func (x Index) Frag(name []byte) *Definition {
+	for d := range x.Defs {
+		if !x.Defs[d].IsOpr() && string(x.Defs[d].Name) == string(name) {
+			return &x.Defs[d]
+		}
+	}
+	return nil
+}
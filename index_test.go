@@ -0,0 +1,122 @@
+package gqlscan_test
+
+import (
+	"testing"
+
+	"github.com/graph-guard/gqlscan"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeIndex(t *testing.T) {
+	const doc = `
+	query Q($a: Int, $b: [String!]!) @d { a(a: $a) { ...F } }
+	{ b }
+	# comment
+	fragment F on User @d(x: "}") { c { ...G ... on T { ...G } } }
+	subscription S { s }
+	mutation M($c: In = {x: 1}) { m }`
+
+	type D struct {
+		Token    gqlscan.Token
+		Name     string
+		TypeCond string
+		Def      string
+		Vars     []string
+		Spreads  []string
+	}
+
+	x, err := gqlscan.MakeIndex([]byte(doc))
+	require.False(t, err.IsErr(), err.Error())
+
+	actual := make([]D, len(x.Defs))
+	for i, d := range x.Defs {
+		actual[i] = D{
+			Token:    d.Token,
+			Name:     string(d.Name),
+			TypeCond: string(d.TypeCond),
+			Def:      string(d.Span.In([]byte(doc))),
+		}
+		for _, v := range d.Vars {
+			actual[i].Vars = append(actual[i].Vars, string(v))
+		}
+		for _, s := range d.Spreads {
+			actual[i].Spreads = append(actual[i].Spreads, string(s))
+		}
+	}
+
+	require.Equal(t, []D{
+		{
+			Token:   gqlscan.TokenDefQry,
+			Name:    "Q",
+			Def:     `query Q($a: Int, $b: [String!]!) @d { a(a: $a) { ...F } }`,
+			Vars:    []string{"a", "b"},
+			Spreads: []string{"F"},
+		},
+		{
+			Token: gqlscan.TokenDefQry,
+			Def:   `{ b }`,
+		},
+		{
+			Token:    gqlscan.TokenDefFrag,
+			Name:     "F",
+			TypeCond: "User",
+			Def:      `fragment F on User @d(x: "}") { c { ...G ... on T { ...G } } }`,
+			Spreads:  []string{"G", "G"},
+		},
+		{
+			Token: gqlscan.TokenDefSub,
+			Name:  "S",
+			Def:   `subscription S { s }`,
+		},
+		{
+			Token: gqlscan.TokenDefMut,
+			Name:  "M",
+			Def:   `mutation M($c: In = {x: 1}) { m }`,
+			Vars:  []string{"c"},
+		},
+	}, actual)
+
+	require.Equal(t, &x.Defs[0], x.Opr([]byte("Q")))
+	require.Equal(t, &x.Defs[1], x.Opr(nil))
+	require.Nil(t, x.Opr([]byte("F")))
+	require.Equal(t, &x.Defs[2], x.Frag([]byte("F")))
+	require.Nil(t, x.Frag([]byte("Q")))
+
+	var oprs []gqlscan.Token
+	x.Oprs(func(d *gqlscan.Definition) (stop bool) {
+		oprs = append(oprs, d.Token)
+		return d.Token == gqlscan.TokenDefSub
+	})
+	require.Equal(t, []gqlscan.Token{
+		gqlscan.TokenDefQry, gqlscan.TokenDefQry, gqlscan.TokenDefSub,
+	}, oprs)
+}
+
+func TestMakeIndexErr(t *testing.T) {
+	x, err := gqlscan.MakeIndex([]byte(`{a} fragment F on T {`))
+	require.True(t, err.IsErr())
+	require.Equal(t, gqlscan.ErrUnexpEOF, err.Code)
+	require.Nil(t, x.Defs)
+}
+
+func TestIndexAdd(t *testing.T) {
+	src := []byte(`query A { ...F } fragment F on T { a } mutation B { b }`)
+	expect, err := gqlscan.MakeIndex(src)
+	require.False(t, err.IsErr())
+
+	var x gqlscan.Index
+	err = gqlscan.Scan(src, func(i *gqlscan.Iterator) (stop bool) {
+		x.Add(i)
+		return false
+	})
+	require.False(t, err.IsErr())
+	require.Equal(t, expect, x)
+
+	o, serr := x.SelectOperation([]byte("A"))
+	require.NoError(t, serr)
+	require.Equal(t,
+		"query A { ...F }\nfragment F on T { a }",
+		string(o.AppendDoc(nil, src)),
+	)
+}
@@ -0,0 +1,329 @@
+// Package ast builds a syntax tree of executable documents
+// from the gqlscan token stream for the packages that need one.
+package ast
+
+import (
+	"fmt"
+
+	"github.com/graph-guard/gqlscan"
+)
+
+// Document is a syntax tree of an executable document.
+type Document struct {
+	Defs []*Definition
+
+	// index is recorded while parsing for selecting operations.
+	index gqlscan.Index
+}
+
+// Definition is an operation or fragment definition.
+type Definition struct {
+	// Token is either TokenDefQry, TokenDefMut, TokenDefSub
+	// or TokenDefFrag.
+	Token gqlscan.Token
+
+	// Index is the index of the first character of the definition.
+	Index int
+
+	// Name is the operation or fragment name,
+	// Name.Val is nil for anonymous operations.
+	Name     Name
+	TypeCond Name
+	Vars     []*VarDef
+	Dirs     []*Directive
+	Sels     []*Selection
+}
+
+// Name is a name and its index in the source document.
+// The index of variable and directive names is the index
+// of the preceding '$' or '@'.
+type Name struct {
+	Val   []byte
+	Index int
+}
+
+// VarDef is a variable definition.
+type VarDef struct {
+	Name Name
+	Type *TypeRef
+	Def  *Value
+	Dirs []*Directive
+}
+
+// TypeRef is a type reference.
+type TypeRef struct {
+	// Name is the named type, nil for list types.
+	Name []byte
+
+	// Elem is the element type of a list type.
+	Elem    *TypeRef
+	NonNull bool
+}
+
+// String returns the type reference in GraphQL notation.
+func (t *TypeRef) String() string {
+	var s string
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	} else {
+		s = string(t.Name)
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Selection is either a field, an inline fragment or a named spread.
+type Selection struct {
+	// Token is either TokenField, TokenFragInline or TokenNamedSpread.
+	Token gqlscan.Token
+
+	// Name is the field name, the type condition of an inline fragment
+	// or the fragment name of a named spread.
+	// Name.Val is nil for inline fragments without a type condition
+	// in which case Name.Index is the index of the selection.
+	Name  Name
+	Alias Name
+	Args  []*Argument
+	Dirs  []*Directive
+	Sels  []*Selection
+}
+
+// ResponseName returns the alias or the name of a field.
+func (s *Selection) ResponseName() []byte {
+	if s.Alias.Val != nil {
+		return s.Alias.Val
+	}
+	return s.Name.Val
+}
+
+// Index returns the index of the first character of the selection
+// or of the type condition of an inline fragment.
+func (s *Selection) Index() int {
+	if s.Alias.Val != nil {
+		return s.Alias.Index
+	}
+	return s.Name.Index
+}
+
+// Directive is a directive.
+type Directive struct {
+	Name Name
+	Args []*Argument
+}
+
+// Argument is an argument or an input object field.
+type Argument struct {
+	Name Name
+	Val  *Value
+}
+
+// Value is an input value.
+type Value struct {
+	// Token is the token of a scalar value, a variable reference,
+	// TokenArr or TokenObj.
+	Token gqlscan.Token
+
+	// Index is the index of the first character of the value.
+	Index int
+
+	// Raw is the value of scalar values and the name
+	// of variable references.
+	Raw []byte
+
+	// Items are the items of a list value.
+	Items []*Value
+
+	// Fields are the fields of an input object value.
+	Fields []*Argument
+}
+
+// Parse scans src into a document.
+func Parse(src []byte) (*Document, error) {
+	d := new(Document)
+	var (
+		def *Definition
+
+		// sets holds the selection lists of the open selection sets.
+		sets []*[]*Selection
+		sel  *Selection
+
+		// dirs is the directive list of the current directive target.
+		dirs *[]*Directive
+
+		// argsOf is the argument list of the last field or directive,
+		// args is the argument list currently being scanned.
+		argsOf, args *[]*Argument
+
+		// vals holds the open list and object values.
+		vals []*Value
+
+		// argName is the name of the current argument or object field.
+		argName Name
+
+		// typeStack holds the open list types of a variable type.
+		typeStack []*TypeRef
+	)
+
+	// setValue assigns v to the current argument, object field,
+	// list item or variable default value.
+	setValue := func(v *Value) {
+		switch {
+		case len(vals) > 0 && vals[len(vals)-1].Token == gqlscan.TokenArr:
+			p := vals[len(vals)-1]
+			p.Items = append(p.Items, v)
+		case len(vals) > 0:
+			p := vals[len(vals)-1]
+			p.Fields = append(p.Fields, &Argument{Name: argName, Val: v})
+		case args != nil:
+			*args = append(*args, &Argument{Name: argName, Val: v})
+		default:
+			def.Vars[len(def.Vars)-1].Def = v
+		}
+	}
+
+	// setType assigns t to the current variable or list type.
+	setType := func(t *TypeRef) {
+		if len(typeStack) > 0 {
+			typeStack[len(typeStack)-1].Elem = t
+			return
+		}
+		def.Vars[len(def.Vars)-1].Type = t
+	}
+
+	err := gqlscan.ScanAll(src, func(i *gqlscan.Iterator) {
+		d.index.Add(i)
+		switch i.Token() {
+		case gqlscan.TokenDefQry,
+			gqlscan.TokenDefMut,
+			gqlscan.TokenDefSub,
+			gqlscan.TokenDefFrag:
+			def = &Definition{Token: i.Token(), Index: i.IndexHead()}
+			d.Defs = append(d.Defs, def)
+			sel, sets, dirs = nil, sets[:0], &def.Dirs
+		case gqlscan.TokenOprName, gqlscan.TokenFragName:
+			def.Name = Name{Val: i.Value(), Index: i.IndexTail()}
+		case gqlscan.TokenFragTypeCond:
+			def.TypeCond = Name{Val: i.Value(), Index: i.IndexTail()}
+		case gqlscan.TokenVarName:
+			v := &VarDef{Name: Name{Val: i.Value(), Index: i.IndexTail() - 1}}
+			def.Vars = append(def.Vars, v)
+			dirs = &v.Dirs
+		case gqlscan.TokenVarTypeArr:
+			t := &TypeRef{}
+			setType(t)
+			typeStack = append(typeStack, t)
+		case gqlscan.TokenVarTypeArrEnd:
+			typeStack = typeStack[:len(typeStack)-1]
+		case gqlscan.TokenVarTypeName:
+			setType(&TypeRef{Name: i.Value()})
+		case gqlscan.TokenVarTypeNotNull:
+			v := def.Vars[len(def.Vars)-1]
+			t := v.Type
+			if len(typeStack) > 0 {
+				t = typeStack[len(typeStack)-1].Elem
+			}
+			t.NonNull = true
+		case gqlscan.TokenVarListEnd:
+			dirs = &def.Dirs
+		case gqlscan.TokenDirName:
+			dir := &Directive{Name: Name{Val: i.Value(), Index: i.IndexTail() - 1}}
+			*dirs = append(*dirs, dir)
+			argsOf = &dir.Args
+		case gqlscan.TokenSet:
+			if len(sets) == 0 {
+				sets = append(sets, &def.Sels)
+				break
+			}
+			sets = append(sets, &sel.Sels)
+		case gqlscan.TokenSetEnd:
+			sets = sets[:len(sets)-1]
+		case gqlscan.TokenFieldAlias:
+			sel = &Selection{
+				Token: gqlscan.TokenField,
+				Alias: Name{Val: i.Value(), Index: i.IndexTail()},
+			}
+		case gqlscan.TokenField,
+			gqlscan.TokenFragInline,
+			gqlscan.TokenNamedSpread:
+			if i.Token() != gqlscan.TokenField || sel == nil ||
+				sel.Alias.Val == nil || sel.Name.Val != nil {
+				sel = &Selection{Token: i.Token()}
+			}
+			sel.Name = Name{Val: i.Value(), Index: i.IndexTail()}
+			if i.Value() == nil {
+				sel.Name.Index = i.IndexHead()
+			}
+			s := sets[len(sets)-1]
+			*s = append(*s, sel)
+			dirs, argsOf = &sel.Dirs, &sel.Args
+		case gqlscan.TokenArgList:
+			args = argsOf
+		case gqlscan.TokenArgListEnd:
+			args = nil
+		case gqlscan.TokenArgName, gqlscan.TokenObjField:
+			argName = Name{Val: i.Value(), Index: i.IndexTail()}
+		case gqlscan.TokenArr, gqlscan.TokenObj:
+			v := &Value{Token: i.Token(), Index: ValueStart(i)}
+			setValue(v)
+			vals = append(vals, v)
+		case gqlscan.TokenArrEnd, gqlscan.TokenObjEnd:
+			vals = vals[:len(vals)-1]
+		case gqlscan.TokenStr,
+			gqlscan.TokenStrBlock,
+			gqlscan.TokenInt,
+			gqlscan.TokenFloat,
+			gqlscan.TokenTrue,
+			gqlscan.TokenFalse,
+			gqlscan.TokenNull,
+			gqlscan.TokenEnumVal,
+			gqlscan.TokenVarRef:
+			setValue(&Value{
+				Token: i.Token(),
+				Index: ValueStart(i),
+				Raw:   i.Value(),
+			})
+		}
+	})
+	if err.IsErr() {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Opr returns the operation called name or the only operation
+// if name is empty as selected by gqlscan.Index.SelectOperation.
+func (d *Document) Opr(name []byte) (*Definition, error) {
+	o, err := d.index.SelectOperation(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, def := range d.Defs {
+		if def.Index == o.Span.Start {
+			return def, nil
+		}
+	}
+	panic("selected operation not in document")
+}
+
+// Frag returns the fragment called name.
+// Returns gqlscan.ErrFragUndefined or gqlscan.ErrFragAmbiguous
+// (wrapped) if there is no such fragment or more than one.
+func (d *Document) Frag(name []byte) (*Definition, error) {
+	var frag *Definition
+	for _, def := range d.Defs {
+		if def.Token != gqlscan.TokenDefFrag ||
+			string(def.Name.Val) != string(name) {
+			continue
+		}
+		if frag != nil {
+			return nil, fmt.Errorf("%w: %q", gqlscan.ErrFragAmbiguous, name)
+		}
+		frag = def
+	}
+	if frag == nil {
+		return nil, fmt.Errorf("%w: %q", gqlscan.ErrFragUndefined, name)
+	}
+	return frag, nil
+}
@@ -0,0 +1,58 @@
+package ast
+
+import "github.com/graph-guard/gqlscan"
+
+// ValueStart returns the index of the first character
+// of the value or variable reference at i.
+func ValueStart(i *gqlscan.Iterator) int {
+	switch i.Token() {
+	case gqlscan.TokenArr, gqlscan.TokenObj:
+		return i.IndexHead()
+	case gqlscan.TokenStr, gqlscan.TokenVarRef:
+		return i.IndexTail() - 1
+	case gqlscan.TokenStrBlock:
+		return i.IndexTail() - len(`"""`)
+	case gqlscan.TokenTrue:
+		return i.IndexHead() - len("true")
+	case gqlscan.TokenFalse:
+		return i.IndexHead() - len("false")
+	case gqlscan.TokenNull:
+		return i.IndexHead() - len("null")
+	}
+	return i.IndexTail()
+}
+
+// TokenEnd returns the index following the last character
+// of the token at i.
+func TokenEnd(i *gqlscan.Iterator) int {
+	switch i.Token() {
+	case gqlscan.TokenDefQry,
+		gqlscan.TokenDefMut,
+		gqlscan.TokenDefSub,
+		gqlscan.TokenDefFrag,
+		gqlscan.TokenOprName,
+		gqlscan.TokenDirName,
+		gqlscan.TokenFragTypeCond,
+		gqlscan.TokenFragName,
+		gqlscan.TokenFragInline,
+		gqlscan.TokenNamedSpread,
+		gqlscan.TokenFieldAlias,
+		gqlscan.TokenField,
+		gqlscan.TokenArgName,
+		gqlscan.TokenEnumVal,
+		gqlscan.TokenInt,
+		gqlscan.TokenFloat,
+		gqlscan.TokenTrue,
+		gqlscan.TokenFalse,
+		gqlscan.TokenNull,
+		gqlscan.TokenVarName,
+		gqlscan.TokenVarTypeName,
+		gqlscan.TokenVarRef,
+		gqlscan.TokenObjField:
+		return i.IndexHead()
+	case gqlscan.TokenStrBlock:
+		return i.IndexHead() + len(`"""`)
+	}
+	// Punctuators and strings end at the head
+	return i.IndexHead() + 1
+}
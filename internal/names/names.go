@@ -0,0 +1,23 @@
+// Package names checks GraphQL names.
+package names
+
+// IsNameByte returns true if b may be part of a name.
+func IsNameByte(b byte) bool {
+	return b == '_' ||
+		(b >= 'a' && b <= 'z') ||
+		(b >= 'A' && b <= 'Z') ||
+		(b >= '0' && b <= '9')
+}
+
+// IsName returns true if s is a valid name.
+func IsName(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !IsNameByte(s[i]) {
+			return false
+		}
+	}
+	return true
+}
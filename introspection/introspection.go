@@ -0,0 +1,110 @@
+// Package introspection detects GraphQL introspection queries
+// on top of the gqlscan token stream.
+package introspection
+
+import (
+	"fmt"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/gqlerr"
+)
+
+// CodeDisabled is the error code in the extensions of errors
+// returned by Policy.Check.
+const CodeDisabled = "INTROSPECTION_DISABLED"
+
+// Field is a selected introspection field.
+type Field struct {
+	// Name is either "__schema", "__type" or "__typename".
+	Name []byte
+
+	// Index is the index of the field name in the source document.
+	Index int
+}
+
+// Find returns all introspection fields selected by the operation
+// called oprName in src, or the only operation if oprName is empty,
+// including the fields selected through fragments in order
+// of appearance in src. Aliases don't hide introspection fields.
+// __typename is only considered an introspection field if typename is true.
+//
+// Returns a gqlscan.Error if src isn't lexically valid and
+// gqlscan.ErrOprNotFound, gqlscan.ErrOprAmbiguous,
+// gqlscan.ErrFragUndefined or gqlscan.ErrFragAmbiguous (possibly wrapped)
+// if the operation can't be selected.
+func Find(src, oprName []byte, typename bool) ([]Field, error) {
+	var (
+		x     gqlscan.Index
+		found []Field
+		defs  []int // Index of the definition of each found field in x.Defs
+	)
+	serr := gqlscan.ScanAll(src, func(i *gqlscan.Iterator) {
+		x.Add(i)
+		if i.Token() != gqlscan.TokenField {
+			return
+		}
+		switch string(i.Value()) {
+		case "__typename":
+			if !typename {
+				return
+			}
+		case "__schema", "__type":
+		default:
+			return
+		}
+		found = append(found, Field{Name: i.Value(), Index: i.IndexTail()})
+		defs = append(defs, len(x.Defs)-1)
+	})
+	if serr.IsErr() {
+		return nil, serr
+	}
+	o, err := x.SelectOperation(oprName)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := map[int]struct{}{o.Span.Start: {}}
+	for _, s := range o.Frags {
+		selected[s.Start] = struct{}{}
+	}
+	var fields []Field
+	for n, f := range found {
+		if _, ok := selected[x.Defs[defs[n]].Span.Start]; ok {
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
+// Policy is an introspection policy.
+type Policy struct {
+	// Allow allows introspection.
+	Allow bool
+
+	// Typename makes __typename an introspection field.
+	Typename bool
+}
+
+// Check returns nil if the policy allows the operation called oprName
+// in src, or the only operation if oprName is empty. Otherwise returns
+// a *gqlerr.Error pointing to the first introspection field with the
+// extension code CodeDisabled.
+//
+// Returns the errors returned by Find if the operation can't be
+// selected regardless of the policy.
+func (p Policy) Check(src, oprName []byte) error {
+	fields, err := Find(src, oprName, p.Typename)
+	if err != nil {
+		return err
+	}
+	if p.Allow || len(fields) < 1 {
+		return nil
+	}
+	return &gqlerr.Error{
+		Message: fmt.Sprintf(
+			"introspection is disabled, field %q is not allowed", fields[0].Name,
+		),
+		Locations:  []gqlerr.Location{gqlerr.LocationOf(src, fields[0].Index)},
+		Extensions: map[string]interface{}{"code": CodeDisabled},
+	}
+}
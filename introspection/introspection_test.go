@@ -0,0 +1,167 @@
+package introspection_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/gqlerr"
+	"github.com/graph-guard/gqlscan/introspection"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFind(t *testing.T) {
+	for _, td := range []struct {
+		decl     string
+		input    string
+		oprName  string
+		typename bool
+		expect   []introspection.Field
+	}{
+		{
+			decl(1),
+			`{a {b}}`, "", true,
+			nil,
+		},
+		{
+			decl(1),
+			`{__typename a {__typename}}`, "", false,
+			nil,
+		},
+		{
+			decl(1),
+			`{__typename a {__typename}}`, "", true,
+			[]introspection.Field{
+				{Name: []byte("__typename"), Index: 1},
+				{Name: []byte("__typename"), Index: 15},
+			},
+		},
+		{
+			// Aliases, comments and strings
+			decl(1),
+			"{ s: __schema { types { name } } # __type\n" +
+				` a(x: "__type") { t: __type(name: "A") { name } } }`,
+			"", false,
+			[]introspection.Field{
+				{Name: []byte("__schema"), Index: 5},
+				{Name: []byte("__type"), Index: 63},
+			},
+		},
+		{
+			// Fragments
+			decl(1),
+			`fragment F on Query { x: __schema { ...G } }
+			query A { a } query B { ...F }
+			fragment G on __Schema { types { __typename } }
+			fragment H on Query { __type(name: "X") { name } }`,
+			"B", true,
+			[]introspection.Field{
+				{Name: []byte("__schema"), Index: 25},
+				{Name: []byte("__typename"), Index: 115},
+			},
+		},
+		{
+			decl(1),
+			`query A { __schema { types { name } } } query B { a }`, "B", true,
+			nil,
+		},
+		{
+			// Fragment defined before the operation
+			decl(1),
+			`fragment F on Query { __schema { a } }
+			{ __type(name: "X") { a } ...F }`,
+			"", true,
+			[]introspection.Field{
+				{Name: []byte("__schema"), Index: 22},
+				{Name: []byte("__type"), Index: 44},
+			},
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			f, err := introspection.Find(
+				[]byte(td.input), []byte(td.oprName), td.typename,
+			)
+			require.NoError(t, err)
+			require.Equal(t, td.expect, f)
+		})
+	}
+}
+
+func TestFindErr(t *testing.T) {
+	_, err := introspection.Find([]byte(`{...F}`), nil, false)
+	require.ErrorIs(t, err, gqlscan.ErrFragUndefined)
+
+	_, err = introspection.Find([]byte(`{__schema`), nil, false)
+	var e gqlscan.Error
+	require.ErrorAs(t, err, &e)
+	require.Equal(t, gqlscan.ErrUnexpEOF, e.Code)
+}
+
+func TestPolicyCheck(t *testing.T) {
+	const src = "query A {\n  a { __typename }\n  ...F\n}\n" +
+		"fragment F on Query { __type(name: \"T\") { name } }"
+
+	for _, td := range []struct {
+		decl   string
+		policy introspection.Policy
+		input  string
+		expect error
+	}{
+		{
+			decl(1),
+			introspection.Policy{Allow: true, Typename: true}, src,
+			nil,
+		},
+		{
+			decl(1),
+			introspection.Policy{}, `{a {__typename}}`,
+			nil,
+		},
+		{
+			decl(1),
+			introspection.Policy{}, src,
+			&gqlerr.Error{
+				Message: `introspection is disabled, ` +
+					`field "__type" is not allowed`,
+				Locations: []gqlerr.Location{{Line: 5, Column: 23}},
+				Extensions: map[string]interface{}{
+					"code": introspection.CodeDisabled,
+				},
+			},
+		},
+		{
+			decl(1),
+			introspection.Policy{Typename: true}, src,
+			&gqlerr.Error{
+				Message: `introspection is disabled, ` +
+					`field "__typename" is not allowed`,
+				Locations: []gqlerr.Location{{Line: 2, Column: 7}},
+				Extensions: map[string]interface{}{
+					"code": introspection.CodeDisabled,
+				},
+			},
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			err := td.policy.Check([]byte(td.input), nil)
+			if td.expect == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.Equal(t, td.expect, err)
+		})
+	}
+
+	t.Run("selection error", func(t *testing.T) {
+		err := introspection.Policy{Allow: true}.Check([]byte(`{a}`), []byte("B"))
+		require.ErrorIs(t, err, gqlscan.ErrOprNotFound)
+	})
+}
+
+func decl(skipFrames int) string {
+	_, filename, line, _ := runtime.Caller(skipFrames)
+	return fmt.Sprintf("%s:%d", filepath.Base(filename), line)
+}
@@ -0,0 +1,212 @@
+package gqlscan
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	// ErrOprNotFound is returned when the requested operation
+	// isn't defined in the document.
+	ErrOprNotFound = errors.New("operation not found")
+
+	// ErrOprAmbiguous is returned when the operation name is empty
+	// but the document defines more than one operation, or when
+	// the operation name is defined more than once.
+	ErrOprAmbiguous = errors.New("ambiguous operation")
+
+	// ErrFragUndefined is returned when a reachable named spread
+	// refers to a fragment that isn't defined in the document.
+	ErrFragUndefined = errors.New("undefined fragment")
+
+	// ErrFragAmbiguous is returned when a reachable fragment
+	// is defined more than once.
+	ErrFragAmbiguous = errors.New("ambiguous fragment")
+)
+
+// Span is a byte range [Start, End) in the source document.
+type Span struct {
+	Start, End int
+}
+
+// In returns the part of src that the span refers to.
+func (s Span) In(src []byte) []byte {
+	return src[s.Start:s.End]
+}
+
+// Operation is an operation selected by SelectOperation.
+type Operation struct {
+	// Token is either TokenDefQry, TokenDefMut or TokenDefSub.
+	Token Token
+
+	// Name is the operation name or nil if the operation is anonymous.
+	Name []byte
+
+	// Span is the span of the operation definition.
+	Span Span
+
+	// Frags are the spans of all fragment definitions transitively
+	// reachable from the operation in the order of their appearance
+	// in the document.
+	Frags []Span
+}
+
+// AppendDoc appends the operation and all its reachable fragment
+// definitions from src to buf separated by line-breaks
+// and returns the extended buffer.
+func (o *Operation) AppendDoc(buf, src []byte) []byte {
+	buf = append(buf, o.Span.In(src)...)
+	for _, f := range o.Frags {
+		buf = append(buf, '\n')
+		buf = append(buf, f.In(src)...)
+	}
+	return buf
+}
+
+// SelectOperation scans src once and returns the operation named
+// oprName together with the spans of all fragments it transitively
+// spreads. If oprName is empty then src must define exactly one operation.
+//
+// Returns an Error if src isn't lexically valid,
+// ErrOprNotFound, ErrOprAmbiguous, ErrFragUndefined or ErrFragAmbiguous
+// (possibly wrapped) if the operation can't be selected.
+//
+// The name of the returned operation refers to the same underlying memory
+// as src.
+func SelectOperation(src []byte, oprName []byte) (Operation, error) {
+	x, serr := MakeIndex(src)
+	if serr.IsErr() {
+		return Operation{}, serr
+	}
+	return x.SelectOperation(oprName)
+}
+
+// SelectOperation is like the SelectOperation function but selects
+// the operation from an index built beforehand instead of scanning
+// the document again.
+//
+// Returns ErrOprNotFound, ErrOprAmbiguous, ErrFragUndefined or
+// ErrFragAmbiguous (possibly wrapped) if the operation can't be selected.
+func (x Index) SelectOperation(oprName []byte) (Operation, error) {
+	var opr *Definition
+	for d := range x.Defs {
+		if !x.Defs[d].IsOpr() {
+			continue
+		}
+		if len(oprName) > 0 && string(x.Defs[d].Name) != string(oprName) {
+			continue
+		}
+		if opr != nil {
+			return Operation{}, ErrOprAmbiguous
+		}
+		opr = &x.Defs[d]
+	}
+	if opr == nil {
+		if len(oprName) > 0 {
+			return Operation{}, fmt.Errorf("%w: %q", ErrOprNotFound, oprName)
+		}
+		return Operation{}, ErrOprNotFound
+	}
+
+	o := Operation{
+		Token: opr.Token,
+		Name:  opr.Name,
+		Span:  opr.Span,
+	}
+
+	// Walk the spread graph breadth-first
+	visited := map[string]struct{}{}
+	queue := append([][]byte(nil), opr.Spreads...)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if _, ok := visited[string(name)]; ok {
+			continue
+		}
+		visited[string(name)] = struct{}{}
+
+		var frag *Definition
+		for d := range x.Defs {
+			if x.Defs[d].IsOpr() || string(x.Defs[d].Name) != string(name) {
+				continue
+			}
+			if frag != nil {
+				return Operation{}, fmt.Errorf("%w: %q", ErrFragAmbiguous, name)
+			}
+			frag = &x.Defs[d]
+		}
+		if frag == nil {
+			return Operation{}, fmt.Errorf("%w: %q", ErrFragUndefined, name)
+		}
+		o.Frags = append(o.Frags, frag.Span)
+		queue = append(queue, frag.Spreads...)
+	}
+	sort.Slice(o.Frags, func(i, j int) bool {
+		return o.Frags[i].Start < o.Frags[j].Start
+	})
+	return o, nil
+}
+
+// PeekOperation returns the type and the name of the operation named
+// oprName in src, or of the only operation if oprName is empty,
+// scanning src only as far as necessary.
+// If oprName isn't empty then the scan stops at the name of the first
+// operation called oprName, duplicate operation names following it
+// aren't detected. If oprName is empty then the scan stops at the second
+// operation definition or at the end of src.
+//
+// The returned type is either TokenDefQry, TokenDefMut or TokenDefSub.
+// Returns an Error if src isn't lexically valid up to the point where
+// the scan stops and ErrOprNotFound or ErrOprAmbiguous (possibly wrapped)
+// if the operation can't be selected.
+//
+// The returned name refers to the same underlying memory as src.
+func PeekOperation(src, oprName []byte) (Token, []byte, error) {
+	var (
+		t, oprToken Token
+		name        []byte
+		oprs        int
+		selErr      error
+		stopped     bool
+	)
+	err := Scan(src, func(i *Iterator) (stop bool) {
+		switch i.Token() {
+		case TokenDefQry, TokenDefMut, TokenDefSub:
+			t = i.Token()
+			if len(oprName) > 0 {
+				break
+			}
+			if oprs++; oprs > 1 {
+				selErr, stopped = ErrOprAmbiguous, true
+				return true
+			}
+			oprToken = t
+		case TokenDefFrag:
+			t = 0
+		case TokenOprName:
+			if len(oprName) < 1 {
+				if oprs == 1 && name == nil {
+					name = i.Value()
+				}
+				break
+			}
+			if string(i.Value()) == string(oprName) {
+				oprToken, name, stopped = t, i.Value(), true
+				return true
+			}
+		}
+		return false
+	})
+	switch {
+	case !stopped && err.IsErr():
+		return 0, nil, err
+	case selErr != nil:
+		return 0, nil, selErr
+	case oprToken != 0:
+		return oprToken, name, nil
+	case len(oprName) > 0:
+		return 0, nil, fmt.Errorf("%w: %q", ErrOprNotFound, oprName)
+	}
+	return 0, nil, ErrOprNotFound
+}
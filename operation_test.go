@@ -0,0 +1,221 @@
+package gqlscan_test
+
+import (
+	"testing"
+
+	"github.com/graph-guard/gqlscan"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectOperation(t *testing.T) {
+	const doc = `# leading comment
+	query A { ...F1 }
+	fragment F1 on Query { a ...F2 ... on Query { ...F3 } }
+	mutation B($v: Int) { b(v: $v) }
+	fragment Unused on Query { x }
+	fragment F3 on Query { c ...F1 }
+	fragment F2 on Query { d }
+	subscription C { c }`
+
+	for _, td := range []struct {
+		decl    string
+		oprName string
+		expect  gqlscan.Token
+		name    string
+		doc     string
+	}{
+		{
+			decl:    decl(1),
+			oprName: "A",
+			expect:  gqlscan.TokenDefQry,
+			name:    "A",
+			doc: "query A { ...F1 }\n" +
+				"fragment F1 on Query { a ...F2 ... on Query { ...F3 } }\n" +
+				"fragment F3 on Query { c ...F1 }\n" +
+				"fragment F2 on Query { d }",
+		},
+		{
+			decl:    decl(1),
+			oprName: "B",
+			expect:  gqlscan.TokenDefMut,
+			name:    "B",
+			doc:     "mutation B($v: Int) { b(v: $v) }",
+		},
+		{
+			decl:    decl(1),
+			oprName: "C",
+			expect:  gqlscan.TokenDefSub,
+			name:    "C",
+			doc:     "subscription C { c }",
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			o, err := gqlscan.SelectOperation([]byte(doc), []byte(td.oprName))
+			require.NoError(t, err)
+			require.Equal(t, td.expect, o.Token)
+			require.Equal(t, td.name, string(o.Name))
+			require.Equal(t, td.doc, string(o.AppendDoc(nil, []byte(doc))))
+		})
+	}
+}
+
+func TestSelectOperationAnonymous(t *testing.T) {
+	for _, td := range []struct {
+		decl  string
+		input string
+		token gqlscan.Token
+		name  string
+		doc   string
+	}{
+		{decl(1), `{a}`, gqlscan.TokenDefQry, "", `{a}`},
+		{decl(1), ` query {a} `, gqlscan.TokenDefQry, "", `query {a}`},
+		{decl(1), `mutation M {a}`, gqlscan.TokenDefMut, "M", `mutation M {a}`},
+		{
+			decl(1),
+			`fragment F on T {b} {...F}`,
+			gqlscan.TokenDefQry, "",
+			"{...F}\nfragment F on T {b}",
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			o, err := gqlscan.SelectOperation([]byte(td.input), nil)
+			require.NoError(t, err)
+			require.Equal(t, td.token, o.Token)
+			require.Equal(t, td.name, string(o.Name))
+			require.Equal(t, td.doc, string(o.AppendDoc(nil, []byte(td.input))))
+		})
+	}
+}
+
+func TestSelectOperationErr(t *testing.T) {
+	for _, td := range []struct {
+		decl    string
+		input   string
+		oprName string
+		expect  error
+		msg     string
+	}{
+		{
+			decl(1), `{a} {b}`, "",
+			gqlscan.ErrOprAmbiguous, "ambiguous operation",
+		},
+		{
+			decl(1), `query A {a} query A {b}`, "A",
+			gqlscan.ErrOprAmbiguous, "ambiguous operation",
+		},
+		{
+			decl(1), `query A {a}`, "B",
+			gqlscan.ErrOprNotFound, `operation not found: "B"`,
+		},
+		{
+			decl(1), `fragment F on T {a}`, "",
+			gqlscan.ErrOprNotFound, "operation not found",
+		},
+		{
+			decl(1), `{...F}`, "",
+			gqlscan.ErrFragUndefined, `undefined fragment: "F"`,
+		},
+		{
+			decl(1), `{...F} fragment F on T {a} fragment F on T {b}`, "",
+			gqlscan.ErrFragAmbiguous, `ambiguous fragment: "F"`,
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			_, err := gqlscan.SelectOperation(
+				[]byte(td.input), []byte(td.oprName),
+			)
+			require.ErrorIs(t, err, td.expect)
+			require.Equal(t, td.msg, err.Error())
+		})
+	}
+
+	t.Run("scan error", func(t *testing.T) {
+		_, err := gqlscan.SelectOperation([]byte(`{a`), nil)
+		var e gqlscan.Error
+		require.ErrorAs(t, err, &e)
+		require.Equal(t, gqlscan.ErrUnexpEOF, e.Code)
+	})
+}
+
+func TestPeekOperation(t *testing.T) {
+	for _, td := range []struct {
+		decl    string
+		input   string
+		oprName string
+		token   gqlscan.Token
+		name    string
+	}{
+		{decl(1), `{a}`, "", gqlscan.TokenDefQry, ""},
+		{decl(1), `fragment F on T {b} mutation M {...F}`, "", gqlscan.TokenDefMut, "M"},
+		{decl(1), `query A {a} subscription B {b}`, "B", gqlscan.TokenDefSub, "B"},
+		{decl(1), `fragment A on T {a} query A {a}`, "A", gqlscan.TokenDefQry, "A"},
+		{
+			// The scan stops before reaching the malformed second operation.
+			decl(1), `query A {a} query B {`, "A", gqlscan.TokenDefQry, "A",
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			tk, name, err := gqlscan.PeekOperation(
+				[]byte(td.input), []byte(td.oprName),
+			)
+			require.NoError(t, err)
+			require.Equal(t, td.token, tk)
+			require.Equal(t, td.name, string(name))
+		})
+	}
+}
+
+func TestPeekOperationErr(t *testing.T) {
+	for _, td := range []struct {
+		decl    string
+		input   string
+		oprName string
+		expect  error
+		msg     string
+	}{
+		{
+			decl(1), `{a} {b`, "",
+			gqlscan.ErrOprAmbiguous, "ambiguous operation",
+		},
+		{
+			decl(1), `query A {a}`, "B",
+			gqlscan.ErrOprNotFound, `operation not found: "B"`,
+		},
+		{
+			decl(1), `fragment F on T {a}`, "",
+			gqlscan.ErrOprNotFound, "operation not found",
+		},
+		{
+			decl(1), `fragment B on T {a}`, "B",
+			gqlscan.ErrOprNotFound, `operation not found: "B"`,
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			_, _, err := gqlscan.PeekOperation(
+				[]byte(td.input), []byte(td.oprName),
+			)
+			require.ErrorIs(t, err, td.expect)
+			require.Equal(t, td.msg, err.Error())
+		})
+	}
+
+	for _, td := range []struct {
+		decl    string
+		input   string
+		oprName string
+		code    gqlscan.ErrorCode
+	}{
+		{decl(1), `{a`, "", gqlscan.ErrUnexpEOF},
+		{decl(1), `query A {a(}} query B {b}`, "B", gqlscan.ErrUnexpToken},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			_, _, err := gqlscan.PeekOperation(
+				[]byte(td.input), []byte(td.oprName),
+			)
+			var e gqlscan.Error
+			require.ErrorAs(t, err, &e)
+			require.Equal(t, td.code, e.Code)
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package gqlscan
+
+// PathKind is the kind of a path element.
+type PathKind int
+
+// Path element kinds
+const (
+	_ PathKind = iota
+
+	// PathField is a field, its span is the alias if the field
+	// has one and the field name otherwise.
+	PathField
+
+	// PathFragInline is an inline fragment, its span is the type
+	// condition, which is empty for inline fragments without one.
+	PathFragInline
+
+	// PathDir is a directive, its span is the directive name.
+	PathDir
+
+	// PathArg is an argument, its span is the argument name.
+	PathArg
+
+	// PathObjField is an object field, its span is the field name.
+	PathObjField
+
+	// PathVar is a variable definition, its span is the variable name.
+	PathVar
+)
+
+func (k PathKind) String() string {
+	switch k {
+	case PathField:
+		return "field"
+	case PathFragInline:
+		return "inline fragment"
+	case PathDir:
+		return "directive"
+	case PathArg:
+		return "argument"
+	case PathObjField:
+		return "object field"
+	case PathVar:
+		return "variable"
+	}
+	return ""
+}
+
+// PathElement is an element of the path returned by Iterator.Path.
+type PathElement struct {
+	Kind PathKind
+	Span
+}
+
+// ScanPath is like Scan but additionally maintains the path
+// returned by Iterator.Path.
+func ScanPath(str []byte, fn func(*Iterator) (err bool)) Error {
+	return Scan(str, func(i *Iterator) (err bool) {
+		i.path.update(i)
+		return fn(i)
+	})
+}
+
+// ScanAllPath is like ScanAll but additionally maintains the path
+// returned by Iterator.Path.
+func ScanAllPath(str []byte, fn func(*Iterator)) Error {
+	return Scan(str, func(i *Iterator) (err bool) {
+		i.path.update(i)
+		fn(i)
+		return false
+	})
+}
+
+// Path returns the path of the current token, which consists of
+// the fields, the inline fragments and, inside of directives and values,
+// the directive, argument and object field names leading to it
+// and including it.
+// For example, the path of the last field "name" in
+// `{ viewer { repos(first: 1) { edges { n: node { name } } } } }`
+// is "viewer", "repos", "edges", "n", "name" and the path of
+// argument "first" is "viewer", "repos", "first".
+// The path of a variable definition starts with the variable.
+//
+// Path returns nil unless the document is scanned with ScanPath
+// or ScanAllPath.
+//
+// WARNING: The returned slice is overwritten by the next token,
+// copy it if you need it later!
+func (i *Iterator) Path() []PathElement {
+	if !i.path.on {
+		return nil
+	}
+	return i.path.elems
+}
+
+// pathTracker maintains the path of the current token.
+type pathTracker struct {
+	on    bool
+	elems []PathElement
+
+	// sets are the path lengths at the beginning of each
+	// open selection set.
+	sets []int
+
+	// objs are the path lengths at the beginning of each open object.
+	objs []int
+
+	// args and dir are the path lengths at the beginning of
+	// the current argument list and directive, -1 if there is none.
+	args, dir int
+
+	// alias is true if the last token is a field alias.
+	alias bool
+}
+
+func (p *pathTracker) truncate(n int) {
+	p.elems = p.elems[:n]
+}
+
+func (p *pathTracker) push(k PathKind, s Span) {
+	p.elems = append(p.elems, PathElement{Kind: k, Span: s})
+}
+
+// endDir removes the directive from the path if there is one.
+func (p *pathTracker) endDir() {
+	if p.dir > -1 {
+		p.truncate(p.dir)
+		p.dir = -1
+	}
+}
+
+// selection removes everything up to the current selection set
+// from the path.
+func (p *pathTracker) selection() {
+	p.endDir()
+	if len(p.sets) > 0 {
+		p.truncate(p.sets[len(p.sets)-1])
+	}
+}
+
+// update updates the path for the current token of i.
+func (p *pathTracker) update(i *Iterator) {
+	value := func() Span {
+		return Span{Start: i.IndexTail(), End: i.IndexHead()}
+	}
+	alias := p.alias
+	p.alias = false
+
+	switch i.Token() {
+	case TokenDefQry, TokenDefMut, TokenDefSub, TokenDefFrag:
+		p.on = true
+		p.truncate(0)
+		p.sets, p.objs = p.sets[:0], p.objs[:0]
+		p.args, p.dir = -1, -1
+	case TokenVarName:
+		p.endDir()
+		p.truncate(0)
+		p.push(PathVar, value())
+	case TokenVarListEnd:
+		p.endDir()
+		p.truncate(0)
+	case TokenDirName:
+		p.endDir()
+		p.dir = len(p.elems)
+		p.push(PathDir, value())
+	case TokenArgList:
+		p.args = len(p.elems)
+	case TokenArgName:
+		p.truncate(p.args)
+		p.push(PathArg, value())
+	case TokenArgListEnd:
+		p.truncate(p.args)
+		p.args = -1
+	case TokenObj:
+		p.objs = append(p.objs, len(p.elems))
+	case TokenObjField:
+		p.truncate(p.objs[len(p.objs)-1])
+		p.push(PathObjField, value())
+	case TokenObjEnd:
+		p.truncate(p.objs[len(p.objs)-1])
+		p.objs = p.objs[:len(p.objs)-1]
+	case TokenSet:
+		p.endDir()
+		p.sets = append(p.sets, len(p.elems))
+	case TokenSetEnd:
+		p.endDir()
+		p.truncate(p.sets[len(p.sets)-1])
+		p.sets = p.sets[:len(p.sets)-1]
+	case TokenFieldAlias:
+		p.selection()
+		p.push(PathField, value())
+		p.alias = true
+	case TokenField:
+		if !alias {
+			p.selection()
+			p.push(PathField, value())
+		}
+	case TokenFragInline:
+		p.selection()
+		s := value()
+		if s.Start < 0 {
+			s.Start = s.End
+		}
+		p.push(PathFragInline, s)
+	case TokenNamedSpread:
+		p.selection()
+	}
+}
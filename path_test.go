@@ -0,0 +1,201 @@
+package gqlscan_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graph-guard/gqlscan"
+
+	"github.com/stretchr/testify/require"
+)
+
+func formatPath(src []byte, p []gqlscan.PathElement) string {
+	var b strings.Builder
+	for n, e := range p {
+		if n > 0 {
+			b.WriteByte('.')
+		}
+		switch e.Kind {
+		case gqlscan.PathFragInline:
+			b.WriteString("...")
+		case gqlscan.PathDir:
+			b.WriteByte('@')
+		case gqlscan.PathVar:
+			b.WriteByte('$')
+		}
+		b.Write(e.In(src))
+	}
+	return b.String()
+}
+
+func TestPath(t *testing.T) {
+	for _, td := range []struct {
+		decl   string
+		input  string
+		expect []string
+	}{
+		{
+			decl(1),
+			`{ viewer { repos(first: 1) { edges { n: node { name } } } } }`,
+			[]string{
+				"query definition: ",
+				"selection set: ",
+				"field: viewer",
+				"selection set: viewer",
+				"field: viewer.repos",
+				"argument list: viewer.repos",
+				"argument name: viewer.repos.first",
+				"integer: viewer.repos.first",
+				"argument list end: viewer.repos",
+				"selection set: viewer.repos",
+				"field: viewer.repos.edges",
+				"selection set: viewer.repos.edges",
+				"field alias: viewer.repos.edges.n",
+				"field: viewer.repos.edges.n",
+				"selection set: viewer.repos.edges.n",
+				"field: viewer.repos.edges.n.name",
+				"selection set end: viewer.repos.edges.n",
+				"selection set end: viewer.repos.edges",
+				"selection set end: viewer.repos",
+				"selection set end: viewer",
+				"selection set end: ",
+			},
+		},
+		{
+			decl(1),
+			`query Q($v: In = {a: [{b: 1}], c: 2} @d(x: {y: 3}), $w: Int) @q {
+				a(o: {p: {q: $v}, r: [{s: 1} {t: 2}]}) @f1 @f2(x: 1)
+				... on T @i { b }
+				... { c }
+				...F @s(x: 1)
+				d
+			}`,
+			[]string{
+				"query definition: ",
+				"operation name: ",
+				"variable list: ",
+				"variable name: $v",
+				"variable type name: $v",
+				"object: $v",
+				"object field: $v.a",
+				"array: $v.a",
+				"object: $v.a",
+				"object field: $v.a.b",
+				"integer: $v.a.b",
+				"object end: $v.a",
+				"array end: $v.a",
+				"object field: $v.c",
+				"integer: $v.c",
+				"object end: $v",
+				"directive name: $v.@d",
+				"argument list: $v.@d",
+				"argument name: $v.@d.x",
+				"object: $v.@d.x",
+				"object field: $v.@d.x.y",
+				"integer: $v.@d.x.y",
+				"object end: $v.@d.x",
+				"argument list end: $v.@d",
+				"variable name: $w",
+				"variable type name: $w",
+				"variable list end: ",
+				"directive name: @q",
+				"selection set: ",
+				"field: a",
+				"argument list: a",
+				"argument name: a.o",
+				"object: a.o",
+				"object field: a.o.p",
+				"object: a.o.p",
+				"object field: a.o.p.q",
+				"variable reference: a.o.p.q",
+				"object end: a.o.p",
+				"object field: a.o.r",
+				"array: a.o.r",
+				"object: a.o.r",
+				"object field: a.o.r.s",
+				"integer: a.o.r.s",
+				"object end: a.o.r",
+				"object: a.o.r",
+				"object field: a.o.r.t",
+				"integer: a.o.r.t",
+				"object end: a.o.r",
+				"array end: a.o.r",
+				"object end: a.o",
+				"argument list end: a",
+				"directive name: a.@f1",
+				"directive name: a.@f2",
+				"argument list: a.@f2",
+				"argument name: a.@f2.x",
+				"integer: a.@f2.x",
+				"argument list end: a.@f2",
+				"fragment inline: ...T",
+				"directive name: ...T.@i",
+				"selection set: ...T",
+				"field: ...T.b",
+				"selection set end: ...T",
+				"fragment inline: ...",
+				"selection set: ...",
+				"field: ....c",
+				"selection set end: ...",
+				"named spread: ",
+				"directive name: @s",
+				"argument list: @s",
+				"argument name: @s.x",
+				"integer: @s.x",
+				"argument list end: @s",
+				"field: d",
+				"selection set end: ",
+			},
+		},
+		{
+			decl(1),
+			`fragment F on T @d { a { b } } mutation { c }`,
+			[]string{
+				"fragment definition: ",
+				"fragment name: ",
+				"fragment type condition: ",
+				"directive name: @d",
+				"selection set: ",
+				"field: a",
+				"selection set: a",
+				"field: a.b",
+				"selection set end: a",
+				"selection set end: ",
+				"mutation definition: ",
+				"selection set: ",
+				"field: c",
+				"selection set end: ",
+			},
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			src := []byte(td.input)
+			var actual []string
+			err := gqlscan.ScanAllPath(src, func(i *gqlscan.Iterator) {
+				actual = append(actual,
+					i.Token().String()+": "+formatPath(src, i.Path()))
+			})
+			require.False(t, err.IsErr(), err.Error())
+			require.Equal(t, td.expect, actual)
+		})
+	}
+}
+
+func TestPathStop(t *testing.T) {
+	src := []byte(`{a {b c}}`)
+	var path string
+	err := gqlscan.ScanPath(src, func(i *gqlscan.Iterator) (err bool) {
+		path = formatPath(src, i.Path())
+		return string(i.Value()) == "c"
+	})
+	require.Equal(t, gqlscan.ErrCallbackFn, err.Code)
+	require.Equal(t, "a.c", path)
+}
+
+func TestPathOff(t *testing.T) {
+	require.False(t, gqlscan.ScanAllPath([]byte(`{a}`), func(*gqlscan.Iterator) {}).IsErr())
+	err := gqlscan.ScanAll([]byte(`{a}`), func(i *gqlscan.Iterator) {
+		require.Nil(t, i.Path())
+	})
+	require.False(t, err.IsErr())
+}
@@ -0,0 +1,176 @@
+package gqlscan
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrDefTooLarge is returned by ScanReader when the window
+// would have to grow beyond the maximum definition size
+// to hold a token.
+var ErrDefTooLarge = errors.New("definition too large")
+
+// Default ReaderConfig values.
+const (
+	DefaultBufferSize = 4 * 1024
+	DefaultMaxDefSize = 1024 * 1024
+)
+
+// ReaderConfig configures ScanReader.
+type ReaderConfig struct {
+	// BufferSize is the size of the window in bytes
+	// through which the input is read.
+	// DefaultBufferSize is used if BufferSize is zero.
+	BufferSize int
+
+	// MaxDefSize is the size in bytes the window grows to at most.
+	// The window grows beyond BufferSize only to hold a token,
+	// such as a long name, string or block string, that straddles
+	// its boundary and doesn't fit in it. Tokens never exceed the
+	// definition they're in, thus documents with definitions no larger
+	// than MaxDefSize are always scanned. Comments and whitespace
+	// aren't tokens and aren't limited.
+	// DefaultMaxDefSize is used if MaxDefSize is zero.
+	MaxDefSize int
+}
+
+// ScanReader calls fn for every token read from r just like Scan.
+// Instead of reading the entire document ScanReader reads it through
+// a fixed-size window that is moved forward token by token,
+// thus memory usage is bounded by the size of the window
+// regardless of the size of the document.
+//
+// Indexes returned by Iterator.IndexHead and Iterator.IndexTail
+// and Error.Index are relative to the beginning of the stream.
+//
+// WARNING: The byte slice returned by Iterator.Value refers to the
+// window and is only valid until fn returns, copy it if you need it later!
+//
+// Returns an Error if the document isn't lexically valid,
+// ErrDefTooLarge if a token exceeds conf.MaxDefSize
+// and errors returned by r.
+func ScanReader(
+	r io.Reader, conf ReaderConfig, fn func(*Iterator) (err bool),
+) error {
+	if conf.MaxDefSize < 1 {
+		conf.MaxDefSize = DefaultMaxDefSize
+	}
+	if conf.BufferSize < 1 {
+		conf.BufferSize = DefaultBufferSize
+	}
+	if conf.BufferSize > conf.MaxDefSize {
+		conf.BufferSize = conf.MaxDefSize
+	}
+
+	rd := &reader{
+		r:   r,
+		buf: make([]byte, 0, conf.BufferSize),
+		max: conf.MaxDefSize,
+	}
+	err := scanReader(rd.buf, rd, func(i *Iterator) (err bool) {
+		if rd.err != nil {
+			// The token may be cut off by the failed read
+			return true
+		}
+		return fn(i)
+	})
+	if rd.err != nil {
+		return rd.err
+	}
+	if err.IsErr() {
+		return err
+	}
+	return nil
+}
+
+// reader is the input of an Iterator scanning a reader.
+type reader struct {
+	r io.Reader
+
+	// buf is the window, which is the Iterator's str.
+	buf []byte
+
+	// max is the size the window grows to at most.
+	max int
+
+	// eof is true once the end of the input is reached or
+	// reading failed, which makes the Iterator consider
+	// the end of the window the end of the input.
+	eof bool
+
+	// err is the error that made reading fail.
+	err error
+}
+
+// fill reads more of the input into the window of i
+// discarding the bytes before keep.
+// Returns false if there is no more input.
+func (i *Iterator) fill(keep int) bool {
+	if i.r == nil {
+		return false
+	}
+	return i.r.fill(i, keep)
+}
+
+// ahead reads more of the input into the window of i
+// discarding the bytes before keep until there are at least
+// n bytes from the head on.
+// Returns false if there is no more input.
+func (i *Iterator) ahead(keep, n int) bool {
+	for i.head+n > len(i.str) {
+		if !i.fill(keep) {
+			return false
+		}
+		keep = 0
+	}
+	return true
+}
+
+func (r *reader) fill(i *Iterator, keep int) bool {
+	if r.eof {
+		return false
+	}
+	if keep > 0 {
+		// Keep the byte before too, errors may be reported at it
+		keep--
+	}
+	if keep > 0 {
+		// Move the window forward
+		i.str = r.buf[:copy(r.buf[:cap(r.buf)], i.str[keep:])]
+		i.head -= keep
+		if i.tail >= 0 {
+			i.tail -= keep
+		}
+		i.offset += keep
+	}
+	if len(i.str) == cap(r.buf) {
+		if cap(r.buf) >= r.max {
+			r.eof, r.err = true, ErrDefTooLarge
+			return false
+		}
+		c := 2 * cap(r.buf)
+		if c > r.max {
+			c = r.max
+		}
+		r.buf = append(make([]byte, 0, c), i.str...)
+		i.str = r.buf
+	}
+	for empty := 0; ; empty++ {
+		n, err := r.r.Read(r.buf[len(i.str):cap(r.buf)])
+		i.str = r.buf[:len(i.str)+n]
+		if err != nil {
+			r.eof = true
+			if !errors.Is(err, io.EOF) {
+				r.err = err
+			}
+			return n > 0
+		}
+		if n > 0 {
+			return true
+		}
+		if empty > 100 {
+			r.eof, r.err = true, io.ErrNoProgress
+			return false
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package gqlscan_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/graph-guard/gqlscan"
+
+	"github.com/stretchr/testify/require"
+)
+
+type token struct {
+	Type       gqlscan.Token
+	Value      string
+	Head, Tail int
+	Level      int
+}
+
+func scanTokens(t *testing.T, input string) []token {
+	var tokens []token
+	err := gqlscan.ScanAll([]byte(input), func(i *gqlscan.Iterator) {
+		tokens = append(tokens, token{
+			i.Token(), string(i.Value()),
+			i.IndexHead(), i.IndexTail(), i.LevelSelect(),
+		})
+	})
+	require.False(t, err.IsErr(), err.Error())
+	return tokens
+}
+
+func scanReaderTokens(
+	t *testing.T, r io.Reader, conf gqlscan.ReaderConfig,
+) []token {
+	var tokens []token
+	err := gqlscan.ScanReader(r, conf, func(i *gqlscan.Iterator) (err bool) {
+		tokens = append(tokens, token{
+			i.Token(), string(i.Value()),
+			i.IndexHead(), i.IndexTail(), i.LevelSelect(),
+		})
+		return false
+	})
+	require.NoError(t, err)
+	return tokens
+}
+
+var readers = []struct {
+	name string
+	make func(string) io.Reader
+}{
+	{"whole", func(s string) io.Reader { return strings.NewReader(s) }},
+	{"one byte", func(s string) io.Reader {
+		return iotest.OneByteReader(strings.NewReader(s))
+	}},
+	{"data err", func(s string) io.Reader {
+		return iotest.DataErrReader(strings.NewReader(s))
+	}},
+}
+
+func TestScanReader(t *testing.T) {
+	for _, td := range testdata {
+		t.Run(td.decl, func(t *testing.T) {
+			expect := scanTokens(t, td.input)
+			for _, r := range readers {
+				t.Run(r.name, func(t *testing.T) {
+					require.Equal(t, expect, scanReaderTokens(
+						t, r.make(td.input), gqlscan.ReaderConfig{BufferSize: 1},
+					))
+				})
+			}
+		})
+	}
+}
+
+func TestScanReaderConcatenated(t *testing.T) {
+	var b strings.Builder
+	for _, td := range testdata {
+		b.WriteString(td.input)
+		b.WriteString("\n")
+	}
+	input := b.String()
+	expect := scanTokens(t, input)
+	for _, r := range readers {
+		t.Run(r.name, func(t *testing.T) {
+			require.Equal(t, expect, scanReaderTokens(
+				t, r.make(input), gqlscan.ReaderConfig{
+					BufferSize: 16,
+					MaxDefSize: 4 * 1024,
+				},
+			))
+		})
+	}
+}
+
+func TestScanReaderErr(t *testing.T) {
+	for _, td := range testdataErr {
+		t.Run(td.decl, func(t *testing.T) {
+			for _, r := range readers {
+				t.Run(r.name, func(t *testing.T) {
+					err := gqlscan.ScanReader(
+						r.make(td.input), gqlscan.ReaderConfig{BufferSize: 1},
+						func(*gqlscan.Iterator) (err bool) { return false },
+					)
+					var e gqlscan.Error
+					require.ErrorAs(t, err, &e)
+					require.Equal(t, td.expectErr, e.Error())
+				})
+			}
+		})
+	}
+}
+
+func TestScanReaderFuncErr(t *testing.T) {
+	const input = "{a} # \"\"\" {\n query {b c}"
+	err := gqlscan.ScanReader(
+		strings.NewReader(input), gqlscan.ReaderConfig{BufferSize: 1},
+		func(i *gqlscan.Iterator) (err bool) {
+			return string(i.Value()) == "c"
+		},
+	)
+	var e gqlscan.Error
+	require.ErrorAs(t, err, &e)
+	require.Equal(t, gqlscan.ErrCallbackFn, e.Code)
+	require.Equal(t, strings.Index(input, "c")+1, e.Index)
+}
+
+func TestScanReaderDefTooLarge(t *testing.T) {
+	for _, td := range []struct {
+		decl   string
+		input  string
+		expect []string
+	}{
+		{decl(1), `{a} {b0123456789abcdef}`, []string{"", "", "a", "", "", ""}},
+		{decl(1), `{a} {b(s: "0123456789abcdef")}`, []string{
+			"", "", "a", "", "", "", "b", "", "s",
+		}},
+		{decl(1), `{a} {b(s: """0123456789abcdef""")}`, []string{
+			"", "", "a", "", "", "", "b", "", "s",
+		}},
+		{decl(1), `{a} {b(i: 12345678901234567890)}`, []string{
+			"", "", "a", "", "", "", "b", "", "i",
+		}},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			for _, r := range readers {
+				t.Run(r.name, func(t *testing.T) {
+					var values []string
+					err := gqlscan.ScanReader(
+						r.make(td.input),
+						gqlscan.ReaderConfig{BufferSize: 4, MaxDefSize: 16},
+						func(i *gqlscan.Iterator) (err bool) {
+							values = append(values, string(i.Value()))
+							return false
+						},
+					)
+					require.ErrorIs(t, err, gqlscan.ErrDefTooLarge)
+					require.Equal(t, td.expect, values)
+				})
+			}
+		})
+	}
+}
+
+func TestScanReaderBounded(t *testing.T) {
+	// Comments, whitespace and the number of tokens aren't limited
+	// by the maximum token size
+	var b strings.Builder
+	b.WriteString("# " + strings.Repeat("comment ", 64) + "\n")
+	for n := 0; n < 64; n++ {
+		b.WriteString(`query Q { a(s: "0123456789") ` +
+			strings.Repeat(" ", 64) + `b: c }`)
+	}
+	input := b.String()
+	expect := scanTokens(t, input)
+	for _, r := range readers {
+		t.Run(r.name, func(t *testing.T) {
+			require.Equal(t, expect, scanReaderTokens(
+				t, r.make(input), gqlscan.ReaderConfig{
+					BufferSize: 4,
+					MaxDefSize: 16,
+				},
+			))
+		})
+	}
+}
+
+func TestScanReaderValueObjField(t *testing.T) {
+	const input = `{f(a: {x: {y: "0123456789abcdef" z: 1}})}`
+	var fields []string
+	err := gqlscan.ScanReader(
+		strings.NewReader(input),
+		gqlscan.ReaderConfig{BufferSize: 64},
+		func(i *gqlscan.Iterator) (err bool) {
+			if i.Token() == gqlscan.TokenInt {
+				fields = append(fields, string(i.ValueObjField()))
+			}
+			return false
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"z"}, fields)
+}
+
+func TestScanReaderReadErr(t *testing.T) {
+	errRead := errors.New("read failed")
+	err := gqlscan.ScanReader(
+		io.MultiReader(strings.NewReader("{a} {b"), iotest.ErrReader(errRead)),
+		gqlscan.ReaderConfig{},
+		func(*gqlscan.Iterator) (err bool) { return false },
+	)
+	require.ErrorIs(t, err, errRead)
+}
@@ -0,0 +1,8 @@
+// Package transform provides source-to-source transformations
+// of GraphQL executable documents built on top of the gqlscan
+// token stream.
+//
+// All transformations append their output to a caller-provided buffer
+// and produce documents that are lexically valid
+// if the input document is lexically valid.
+package transform
@@ -0,0 +1,228 @@
+package transform
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/graph-guard/gqlscan"
+)
+
+// ErrFragCycle is returned when fragment spreads form a cycle.
+var ErrFragCycle = errors.New("fragment cycle")
+
+// InlineFragments appends src to buf replacing every named spread
+// with an equivalent inline fragment and removing all fragment
+// definitions, such that:
+//
+//	{ ...F @include(if: $x) }
+//	fragment F on T @d { a }
+//
+// becomes:
+//
+//	{ ...on T @include(if: $x) @d { a } }
+//
+// The inline fragment carries both the directives of the spread
+// and the directives of the fragment definition in that order.
+// Operations are separated by line-breaks.
+//
+// Returns a gqlscan.Error if src isn't lexically valid,
+// ErrFragCycle if spreads form a cycle, and gqlscan.ErrFragUndefined
+// or gqlscan.ErrFragAmbiguous if a spread can't be resolved.
+func InlineFragments(buf, src []byte) ([]byte, error) {
+	d, err := scanFragDoc(src)
+	if err != nil {
+		return buf, err
+	}
+	for n, o := range d.oprs {
+		if n > 0 {
+			buf = append(buf, '\n')
+		}
+		if buf, err = d.appendInlined(
+			buf, o.Start, o.End, nil,
+		); err != nil {
+			return buf, err
+		}
+	}
+	return buf, nil
+}
+
+// fragDoc is a document prepared for fragment inlining.
+type fragDoc struct {
+	src     []byte
+	oprs    []gqlscan.Span
+	frags   []fragDef
+	spreads []spread
+}
+
+// fragDef is a fragment definition.
+type fragDef struct {
+	name, typeCond []byte
+
+	// dirs is the span of the definition directives,
+	// zero if there are none.
+	dirs gqlscan.Span
+
+	// set is the span of the definition selection set.
+	set gqlscan.Span
+}
+
+// spread is a named spread.
+type spread struct {
+	name []byte
+
+	// nameStart and nameEnd are the span of the fragment name.
+	nameStart, nameEnd int
+
+	// end is the end of the spread including its directives.
+	end int
+}
+
+func scanFragDoc(src []byte) (*fragDoc, error) {
+	d := &fragDoc{src: src}
+
+	// inDirs is true while directives of a fragment definition
+	// or a named spread are being scanned.
+	var inDirs bool
+	var start int
+	if err := gqlscan.ScanAll(src, func(i *gqlscan.Iterator) {
+		switch i.Token() {
+		case gqlscan.TokenDefQry,
+			gqlscan.TokenDefMut,
+			gqlscan.TokenDefSub:
+			start = i.IndexHead()
+		case gqlscan.TokenDefFrag:
+			start = -1
+			d.frags = append(d.frags, fragDef{})
+		case gqlscan.TokenFragName:
+			d.frags[len(d.frags)-1].name = i.Value()
+		case gqlscan.TokenFragTypeCond:
+			d.frags[len(d.frags)-1].typeCond = i.Value()
+			inDirs = true
+		case gqlscan.TokenNamedSpread:
+			d.spreads = append(d.spreads, spread{
+				name:      i.Value(),
+				nameStart: i.IndexTail(),
+				nameEnd:   i.IndexHead(),
+				end:       i.IndexHead(),
+			})
+			inDirs = true
+		case gqlscan.TokenDirName:
+			if !inDirs {
+				break
+			}
+			if i.LevelSelect() > 0 {
+				d.spreads[len(d.spreads)-1].end = i.IndexHead()
+				break
+			}
+			f := &d.frags[len(d.frags)-1]
+			if f.dirs.End == 0 {
+				// Include the '@'
+				f.dirs.Start = i.IndexTail() - 1
+			}
+			f.dirs.End = i.IndexHead()
+		case gqlscan.TokenArgListEnd:
+			if !inDirs {
+				break
+			}
+			if i.LevelSelect() > 0 {
+				d.spreads[len(d.spreads)-1].end = i.IndexHead() + 1
+				break
+			}
+			d.frags[len(d.frags)-1].dirs.End = i.IndexHead() + 1
+		case gqlscan.TokenSet:
+			inDirs = false
+			if i.LevelSelect() == 0 && start == -1 {
+				d.frags[len(d.frags)-1].set.Start = i.IndexHead()
+			}
+		case gqlscan.TokenField,
+			gqlscan.TokenFieldAlias,
+			gqlscan.TokenFragInline,
+			gqlscan.TokenSetEnd:
+			inDirs = false
+			if i.Token() != gqlscan.TokenSetEnd || i.LevelSelect() != 1 {
+				break
+			}
+			if start == -1 {
+				d.frags[len(d.frags)-1].set.End = i.IndexHead() + 1
+				break
+			}
+			d.oprs = append(d.oprs, gqlscan.Span{
+				Start: start,
+				End:   i.IndexHead() + 1,
+			})
+		}
+	}); err.IsErr() {
+		return nil, err
+	}
+	return d, nil
+}
+
+// frag returns the index of the fragment definition called name.
+func (d *fragDoc) frag(name []byte) (int, error) {
+	f := -1
+	for n := range d.frags {
+		if string(d.frags[n].name) != string(name) {
+			continue
+		}
+		if f != -1 {
+			return -1, fmt.Errorf("%w: %q", gqlscan.ErrFragAmbiguous, name)
+		}
+		f = n
+	}
+	if f == -1 {
+		return -1, fmt.Errorf("%w: %q", gqlscan.ErrFragUndefined, name)
+	}
+	return f, nil
+}
+
+// appendInlined appends src[start:end] to buf inlining all named spreads.
+// stack holds the indexes of the fragments currently being inlined.
+func (d *fragDoc) appendInlined(
+	buf []byte, start, end int, stack []int,
+) ([]byte, error) {
+	k := sort.Search(len(d.spreads), func(k int) bool {
+		return d.spreads[k].nameStart >= start
+	})
+	pos := start
+	for ; k < len(d.spreads) && d.spreads[k].nameStart < end; k++ {
+		s := d.spreads[k]
+		f, err := d.frag(s.name)
+		if err != nil {
+			return buf, err
+		}
+		for n, sf := range stack {
+			if sf == f {
+				return buf, d.errCycle(stack[n:])
+			}
+		}
+
+		buf = append(buf, d.src[pos:s.nameStart]...)
+		buf = append(buf, "on "...)
+		buf = append(buf, d.frags[f].typeCond...)
+		buf = append(buf, d.src[s.nameEnd:s.end]...)
+		if dirs := d.frags[f].dirs; dirs.End > 0 {
+			buf = append(buf, ' ')
+			buf = append(buf, dirs.In(d.src)...)
+		}
+		buf = append(buf, ' ')
+		if buf, err = d.appendInlined(
+			buf, d.frags[f].set.Start, d.frags[f].set.End, append(stack, f),
+		); err != nil {
+			return buf, err
+		}
+		pos = s.end
+	}
+	return append(buf, d.src[pos:end]...), nil
+}
+
+func (d *fragDoc) errCycle(cycle []int) error {
+	var b strings.Builder
+	for _, f := range cycle {
+		b.Write(d.frags[f].name)
+		b.WriteString(" -> ")
+	}
+	b.Write(d.frags[cycle[0]].name)
+	return fmt.Errorf("%w: %s", ErrFragCycle, b.String())
+}
@@ -0,0 +1,120 @@
+package transform_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/transform"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInlineFragments(t *testing.T) {
+	for _, td := range []struct {
+		decl   string
+		input  string
+		expect string
+	}{
+		{
+			decl(1),
+			`{a}`,
+			`{a}`,
+		},
+		{
+			decl(1),
+			`{...F} fragment F on T {a}`,
+			`{...on T {a}}`,
+		},
+		{
+			decl(1),
+			`query Q($x: Boolean) { ...F @include(if: $x) b }
+			fragment F on T @d(a: [1]) { a }`,
+			`query Q($x: Boolean) { ...on T @include(if: $x) @d(a: [1]) { a } b }`,
+		},
+		{
+			decl(1),
+			`fragment G on U { g } { ... F } fragment F on T { f { ...G } }`,
+			`{ ... on T { f { ...on U { g } } } }`,
+		},
+		{
+			decl(1),
+			"{ ...F @d # comment\n x } fragment F on T @e # comment\n { a }",
+			"{ ...on T @d @e { a } # comment\n x }",
+		},
+		{
+			decl(1),
+			`query A {...F ...F} fragment F on T {a} mutation B {...on T {...F}}`,
+			"query A {...on T {a} ...on T {a}}\n" +
+				"mutation B {...on T {...on T {a}}}",
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			out, err := transform.InlineFragments(nil, []byte(td.input))
+			require.NoError(t, err)
+			require.Equal(t, td.expect, string(out))
+			requireNoFragments(t, out)
+		})
+	}
+}
+
+func TestInlineFragmentsErr(t *testing.T) {
+	for _, td := range []struct {
+		decl   string
+		input  string
+		expect error
+		msg    string
+	}{
+		{
+			decl(1),
+			`{...F} fragment F on T {...F}`,
+			transform.ErrFragCycle, "fragment cycle: F -> F",
+		},
+		{
+			decl(1),
+			`{...F} fragment F on T {...G} fragment G on T {a {...H}}
+			fragment H on T {...G}`,
+			transform.ErrFragCycle, "fragment cycle: G -> H -> G",
+		},
+		{
+			decl(1),
+			`{...F}`,
+			gqlscan.ErrFragUndefined, `undefined fragment: "F"`,
+		},
+		{
+			decl(1),
+			`{...F} fragment F on T {a} fragment F on T {b}`,
+			gqlscan.ErrFragAmbiguous, `ambiguous fragment: "F"`,
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			_, err := transform.InlineFragments(nil, []byte(td.input))
+			require.ErrorIs(t, err, td.expect)
+			require.Equal(t, td.msg, err.Error())
+		})
+	}
+
+	t.Run("scan error", func(t *testing.T) {
+		_, err := transform.InlineFragments(nil, []byte(`{...F`))
+		var e gqlscan.Error
+		require.ErrorAs(t, err, &e)
+		require.Equal(t, gqlscan.ErrUnexpEOF, e.Code)
+	})
+}
+
+// requireNoFragments makes sure doc is lexically valid and contains
+// neither fragment definitions nor named spreads.
+func requireNoFragments(t *testing.T, doc []byte) {
+	err := gqlscan.ScanAll(doc, func(i *gqlscan.Iterator) {
+		require.NotEqual(t, gqlscan.TokenDefFrag, i.Token())
+		require.NotEqual(t, gqlscan.TokenNamedSpread, i.Token())
+	})
+	require.False(t, err.IsErr(), err.Error())
+}
+
+func decl(skipFrames int) string {
+	_, filename, line, _ := runtime.Caller(skipFrames)
+	return fmt.Sprintf("%s:%d", filepath.Base(filename), line)
+}
@@ -0,0 +1,562 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/internal/ast"
+)
+
+// PruneSkipped appends src to buf evaluating the @skip and @include
+// directives of fields, named spreads and inline fragments,
+// such that:
+//
+//	query Q($x: Boolean!) { a @include(if: $x) b @skip(if: false) }
+//
+// given the variables {"x":false} becomes:
+//
+//	query Q { b }
+//
+// Selections that are skipped are removed, the evaluated directives
+// of selections that are included are removed as well.
+// The condition is either a boolean literal or a reference to
+// a variable with a boolean value in the JSON object vars or,
+// if the variable isn't defined in vars, with a boolean default value.
+// Directives with conditions that can't be evaluated are left in place.
+//
+// Fragment definitions and variable definitions that are no longer
+// used after pruning are removed. Selection sets that would become
+// empty are replaced with "__typename @skip(if: true)" which keeps
+// the document valid and has no effect on the result.
+//
+// Returns a gqlscan.Error if src isn't lexically valid.
+func PruneSkipped(buf, src, vars []byte) ([]byte, error) {
+	var values map[string]interface{}
+	if len(bytes.TrimSpace(vars)) > 0 {
+		if err := json.Unmarshal(vars, &values); err != nil {
+			return buf, fmt.Errorf("decoding variables: %w", err)
+		}
+	}
+	d, err := scanPruneDoc(src)
+	if err != nil {
+		return buf, err
+	}
+	d.evaluate(values)
+	return applyEdits(buf, src, d.edits()), nil
+}
+
+// pruneDoc is a document prepared for pruning.
+type pruneDoc struct {
+	src  []byte
+	defs []*pruneDef
+
+	// fragDefaults maps variable names to the raw boolean default
+	// value shared by all operations that define the variable.
+	// The value is nil if the operations define different defaults.
+	fragDefaults map[string][]byte
+}
+
+// pruneDef is an operation or fragment definition.
+type pruneDef struct {
+	frag bool
+	name []byte
+
+	// span is the span of the definition including the whitespace
+	// and comments preceding it.
+	span gqlscan.Span
+
+	// varList is the span of the variable list, zero if there is none.
+	varList gqlscan.Span
+	vars    []pruneVar
+	set     *pruneSet
+
+	// refs are the variable references outside of the variable list.
+	refs []pruneRef
+
+	removed bool
+}
+
+// pruneVar is a variable definition.
+type pruneVar struct {
+	name []byte
+	span gqlscan.Span
+
+	// def is the raw boolean default value, nil if there is none.
+	def     []byte
+	removed bool
+}
+
+// pruneRef is a variable reference.
+type pruneRef struct {
+	name  []byte
+	index int
+}
+
+// pruneSet is a selection set.
+type pruneSet struct {
+	// open and close are the indexes of the braces.
+	open, close int
+	sels        []*pruneSel
+}
+
+// emptied returns true if all selections of s are removed.
+func (s *pruneSet) emptied() bool {
+	for _, sel := range s.sels {
+		if !sel.removed {
+			return false
+		}
+	}
+	return true
+}
+
+// pruneSel is a field, a named spread or an inline fragment.
+type pruneSel struct {
+	// span is the span of the selection including the whitespace
+	// and comments preceding it.
+	span gqlscan.Span
+
+	// spread is the fragment name of a named spread.
+	spread []byte
+	dirs   []*pruneDir
+	set    *pruneSet
+
+	removed bool
+}
+
+// pruneDir is a @skip or @include directive.
+type pruneDir struct {
+	// span is the span of the directive including the whitespace
+	// and comments preceding it.
+	span gqlscan.Span
+	skip bool
+
+	// cond is TokenTrue, TokenFalse or TokenVarRef,
+	// zero if the condition can't be evaluated.
+	cond    gqlscan.Token
+	varName []byte
+
+	removed bool
+}
+
+func scanPruneDoc(src []byte) (*pruneDoc, error) {
+	d := &pruneDoc{src: src}
+	var (
+		def  *pruneDef
+		sets []*pruneSet
+		sel  *pruneSel
+		dir  *pruneDir
+
+		// lastEnd is the end index of the previous token.
+		lastEnd int
+
+		inVarList bool
+		afterDir  bool
+		afterIf   bool
+		aliased   bool
+	)
+	newSel := func() {
+		s := sets[len(sets)-1]
+		if len(s.sels) > 0 {
+			s.sels[len(s.sels)-1].span.End = lastEnd
+		}
+		sel = &pruneSel{span: gqlscan.Span{Start: lastEnd}}
+		s.sels = append(s.sels, sel)
+	}
+
+	err := gqlscan.ScanAll(src, func(i *gqlscan.Iterator) {
+		defer func() { lastEnd = ast.TokenEnd(i) }()
+
+		if afterIf {
+			afterIf = false
+			switch i.Token() {
+			case gqlscan.TokenTrue, gqlscan.TokenFalse:
+				dir.cond = i.Token()
+			case gqlscan.TokenVarRef:
+				dir.cond, dir.varName = i.Token(), i.Value()
+			}
+		}
+		if inVarList && len(def.vars) > 0 &&
+			i.Token() != gqlscan.TokenVarName &&
+			i.Token() != gqlscan.TokenVarListEnd {
+			v := &def.vars[len(def.vars)-1]
+			v.span.End = ast.TokenEnd(i)
+			switch i.Token() {
+			case gqlscan.TokenTrue, gqlscan.TokenFalse:
+				if !afterDir {
+					v.def = src[ast.ValueStart(i):ast.TokenEnd(i)]
+				}
+			}
+		}
+
+		switch i.Token() {
+		case gqlscan.TokenDefQry,
+			gqlscan.TokenDefMut,
+			gqlscan.TokenDefSub,
+			gqlscan.TokenDefFrag:
+			def = &pruneDef{
+				frag: i.Token() == gqlscan.TokenDefFrag,
+				span: gqlscan.Span{Start: lastEnd},
+			}
+			d.defs = append(d.defs, def)
+			sets, sel = sets[:0], nil
+		case gqlscan.TokenOprName, gqlscan.TokenFragName:
+			def.name = i.Value()
+		case gqlscan.TokenVarList:
+			def.varList.Start = i.IndexHead()
+			inVarList = true
+		case gqlscan.TokenVarName:
+			def.vars = append(def.vars, pruneVar{
+				name: i.Value(),
+				span: gqlscan.Span{Start: i.IndexTail() - 1, End: ast.TokenEnd(i)},
+			})
+			afterDir = false
+		case gqlscan.TokenVarListEnd:
+			def.varList.End = ast.TokenEnd(i)
+			inVarList, afterDir = false, false
+		case gqlscan.TokenVarRef:
+			if !inVarList {
+				def.refs = append(def.refs, pruneRef{
+					name:  i.Value(),
+					index: i.IndexTail() - 1,
+				})
+			}
+		case gqlscan.TokenDirName:
+			afterDir, dir = true, nil
+			if sel == nil || sel.set != nil {
+				// Not a selection directive
+				break
+			}
+			switch string(i.Value()) {
+			case "skip", "include":
+				dir = &pruneDir{
+					span: gqlscan.Span{Start: lastEnd, End: ast.TokenEnd(i)},
+					skip: string(i.Value()) == "skip",
+				}
+				sel.dirs = append(sel.dirs, dir)
+			}
+		case gqlscan.TokenArgName:
+			afterIf = dir != nil && string(i.Value()) == "if"
+		case gqlscan.TokenArgListEnd:
+			if dir != nil {
+				dir.span.End = ast.TokenEnd(i)
+			}
+		case gqlscan.TokenSet:
+			s := &pruneSet{open: i.IndexHead()}
+			if len(sets) == 0 {
+				def.set = s
+			} else {
+				sel.set = s
+			}
+			sets = append(sets, s)
+			dir = nil
+		case gqlscan.TokenSetEnd:
+			s := sets[len(sets)-1]
+			s.close = i.IndexHead()
+			s.sels[len(s.sels)-1].span.End = lastEnd
+			sets = sets[:len(sets)-1]
+			if len(sets) == 0 {
+				def.span.End = ast.TokenEnd(i)
+			} else {
+				p := sets[len(sets)-1]
+				sel = p.sels[len(p.sels)-1]
+			}
+		case gqlscan.TokenFieldAlias:
+			newSel()
+			aliased, dir = true, nil
+		case gqlscan.TokenField:
+			if !aliased {
+				newSel()
+			}
+			aliased, dir = false, nil
+		case gqlscan.TokenNamedSpread, gqlscan.TokenFragInline:
+			newSel()
+			if i.Token() == gqlscan.TokenNamedSpread {
+				sel.spread = i.Value()
+			}
+			dir = nil
+		}
+	})
+	if err.IsErr() {
+		return nil, err
+	}
+
+	d.fragDefaults = map[string][]byte{}
+	for _, def := range d.defs {
+		for _, v := range def.vars {
+			if p, ok := d.fragDefaults[string(v.name)]; ok &&
+				string(p) != string(v.def) {
+				d.fragDefaults[string(v.name)] = nil
+				continue
+			}
+			d.fragDefaults[string(v.name)] = v.def
+		}
+	}
+	return d, nil
+}
+
+// evaluate marks the skipped selections, the evaluated directives,
+// the fragment definitions and the variable definitions to be removed.
+func (d *pruneDoc) evaluate(values map[string]interface{}) {
+	for _, def := range d.defs {
+		defaults := d.fragDefaults
+		if !def.frag {
+			defaults = make(map[string][]byte, len(def.vars))
+			for _, v := range def.vars {
+				defaults[string(v.name)] = v.def
+			}
+		}
+		evaluateSet(def.set, values, defaults)
+	}
+
+	// Remove fragments that are only used by removed selections
+	before, after := map[*pruneDef]bool{}, map[*pruneDef]bool{}
+	for _, def := range d.defs {
+		if !def.frag {
+			d.reachable(def.set, false, before)
+			d.reachable(def.set, true, after)
+		}
+	}
+	for _, def := range d.defs {
+		// Unused fragments are kept together with the fragments they use
+		if def.frag && !before[def] {
+			d.reachable(def.set, true, after)
+		}
+	}
+	for _, def := range d.defs {
+		if def.frag && before[def] && !after[def] {
+			def.removed = true
+		}
+	}
+
+	// Remove variables that are only used by removed selections
+	// of the operation and the fragments reachable from it
+	for _, def := range d.defs {
+		if def.frag {
+			continue
+		}
+		before, after := map[*pruneDef]bool{}, map[*pruneDef]bool{}
+		d.reachable(def.set, false, before)
+		d.reachable(def.set, true, after)
+		usedBefore, usedAfter := map[string]bool{}, map[string]bool{}
+		d.usedVars(def, before, usedBefore, false)
+		d.usedVars(def, after, usedAfter, true)
+		for n := range def.vars {
+			v := &def.vars[n]
+			v.removed = usedBefore[string(v.name)] &&
+				!usedAfter[string(v.name)]
+		}
+	}
+}
+
+func evaluateSet(
+	s *pruneSet, values map[string]interface{}, defaults map[string][]byte,
+) {
+	for _, sel := range s.sels {
+		for _, dir := range sel.dirs {
+			cond, ok := dir.evaluate(values, defaults)
+			if !ok {
+				continue
+			}
+			dir.removed = true
+			if cond == dir.skip {
+				sel.removed = true
+			}
+		}
+		if !sel.removed && sel.set != nil {
+			evaluateSet(sel.set, values, defaults)
+		}
+	}
+}
+
+// evaluate returns the value of the condition and true
+// or false if the condition can't be evaluated.
+func (dir *pruneDir) evaluate(
+	values map[string]interface{}, defaults map[string][]byte,
+) (cond, ok bool) {
+	switch dir.cond {
+	case gqlscan.TokenTrue:
+		return true, true
+	case gqlscan.TokenFalse:
+		return false, true
+	case gqlscan.TokenVarRef:
+		if v, ok := values[string(dir.varName)]; ok {
+			b, ok := v.(bool)
+			return b, ok
+		}
+		switch string(defaults[string(dir.varName)]) {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// reachable adds the fragment definitions reachable from s to r
+// ignoring removed selections if pruned is true.
+func (d *pruneDoc) reachable(s *pruneSet, pruned bool, r map[*pruneDef]bool) {
+	var walk func(s *pruneSet)
+	walk = func(s *pruneSet) {
+		for _, sel := range s.sels {
+			if pruned && sel.removed {
+				continue
+			}
+			if sel.set != nil {
+				walk(sel.set)
+			}
+			if sel.spread == nil {
+				continue
+			}
+			if f := d.frag(sel.spread); f != nil && !r[f] {
+				r[f] = true
+				walk(f.set)
+			}
+		}
+	}
+	walk(s)
+}
+
+// usedVars adds the names of the variables referenced by opr
+// and the fragments in frags to used ignoring references
+// in removed selections and directives if pruned is true.
+func (d *pruneDoc) usedVars(
+	opr *pruneDef, frags map[*pruneDef]bool, used map[string]bool, pruned bool,
+) {
+	for _, def := range d.defs {
+		if def != opr && !frags[def] {
+			continue
+		}
+		var removed []gqlscan.Span
+		if pruned {
+			removed = def.set.removedSpans(removed)
+		}
+	REFS:
+		for _, r := range def.refs {
+			for _, s := range removed {
+				if r.index >= s.Start && r.index < s.End {
+					continue REFS
+				}
+			}
+			used[string(r.name)] = true
+		}
+	}
+}
+
+// removedSpans appends the spans of the removed selections
+// and directives in s to spans.
+func (s *pruneSet) removedSpans(spans []gqlscan.Span) []gqlscan.Span {
+	for _, sel := range s.sels {
+		if sel.removed {
+			spans = append(spans, sel.span)
+			continue
+		}
+		for _, dir := range sel.dirs {
+			if dir.removed {
+				spans = append(spans, dir.span)
+			}
+		}
+		if sel.set != nil {
+			spans = sel.set.removedSpans(spans)
+		}
+	}
+	return spans
+}
+
+// frag returns the first fragment definition called name or nil.
+func (d *pruneDoc) frag(name []byte) *pruneDef {
+	for _, def := range d.defs {
+		if def.frag && string(def.name) == string(name) {
+			return def
+		}
+	}
+	return nil
+}
+
+// edits returns the edits that prune the document.
+func (d *pruneDoc) edits() (edits []edit) {
+	for _, def := range d.defs {
+		if def.removed {
+			edits = append(edits, removal(d.src, def.span))
+			continue
+		}
+		edits = d.varListEdit(edits, def)
+		edits = def.set.edits(edits, d.src)
+	}
+	return edits
+}
+
+// varListEdit appends an edit that rebuilds the variable list
+// of def without the removed variables if any are removed.
+func (d *pruneDoc) varListEdit(edits []edit, def *pruneDef) []edit {
+	var removed bool
+	for _, v := range def.vars {
+		removed = removed || v.removed
+	}
+	if !removed {
+		return edits
+	}
+	e := edit{start: def.varList.Start, end: def.varList.End}
+	for _, v := range def.vars {
+		if v.removed {
+			continue
+		}
+		if len(e.text) < 1 {
+			e.text = append(e.text, '(')
+		} else {
+			e.text = append(e.text, ", "...)
+		}
+		e.text = append(e.text, v.span.In(d.src)...)
+	}
+	if len(e.text) > 0 {
+		e.text = append(e.text, ')')
+	}
+	return append(edits, e)
+}
+
+func (s *pruneSet) edits(edits []edit, src []byte) []edit {
+	if s.emptied() {
+		return append(edits, edit{
+			start: s.open + 1,
+			end:   s.close,
+			text:  []byte("__typename @skip(if: true)"),
+		})
+	}
+	for _, sel := range s.sels {
+		if sel.removed {
+			edits = append(edits, removal(src, sel.span))
+			continue
+		}
+		for _, dir := range sel.dirs {
+			if dir.removed {
+				edits = append(edits, removal(src, dir.span))
+			}
+		}
+		if sel.set != nil {
+			edits = sel.set.edits(edits, src)
+		}
+	}
+	return edits
+}
+
+// removal returns an edit that removes span from src.
+// The span is replaced with a space if the tokens around it
+// would otherwise be joined.
+func removal(src []byte, span gqlscan.Span) edit {
+	e := edit{start: span.Start, end: span.End}
+	if e.start < 1 || e.end >= len(src) {
+		return e
+	}
+	switch src[e.start-1] {
+	case ' ', '\t', '\n', '\r', ',', '{', '(', '[':
+		return e
+	}
+	switch src[e.end] {
+	case ' ', '\t', '\n', '\r', ',', '}', ')', ']', '#':
+		return e
+	}
+	e.text = []byte(" ")
+	return e
+}
@@ -0,0 +1,212 @@
+package transform_test
+
+import (
+	"testing"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/transform"
+	"github.com/graph-guard/gqlscan/validate"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneSkipped(t *testing.T) {
+	for _, td := range []struct {
+		decl   string
+		input  string
+		vars   string
+		expect string
+	}{
+		{
+			decl(1),
+			`{a b}`, ``,
+			`{a b}`,
+		},
+		{
+			decl(1),
+			`query Q($x: Boolean!) { a @include(if: $x) b @skip(if: false) }`,
+			`{"x": false}`,
+			`query Q { b }`,
+		},
+		{
+			decl(1),
+			`{ a @skip(if: true) b @include(if: true) @d c @skip(if: false) { d } }`,
+			``,
+			`{ b @d c { d } }`,
+		},
+		{
+			// Unknown conditions are left in place
+			decl(1),
+			`query($x: Boolean, $y: Boolean!, $z: Boolean = null) {
+				a @skip(if: $x) b @include(if: $y) c @skip(if: $z) d @skip(if: $w)
+			}`,
+			`{"x": null, "y": 1}`,
+			`query($x: Boolean, $y: Boolean!, $z: Boolean = null) {
+				a @skip(if: $x) b @include(if: $y) c @skip(if: $z) d @skip(if: $w)
+			}`,
+		},
+		{
+			// Defaults
+			decl(1),
+			`query($x: Boolean = true, $y: Boolean! = false @d(a: true)) {
+				a @skip(if: $x) b @include(if: $y) c
+			}`,
+			`{}`,
+			`query { c
+			}`,
+		},
+		{
+			decl(1),
+			`query($x: Boolean!, $y: Int) {
+				... on T @include(if: $x) { a(y: $y) }
+				... @skip(if: $x) { b }
+				...F @include(if: $x)
+				x: f(y: $y) @skip(if: $x) { g }
+			}
+			fragment F on T { f }`,
+			`{"x": true}`,
+			`query($y: Int) {
+				... on T { a(y: $y) }
+				...F
+			}
+			fragment F on T { f }`,
+		},
+		{
+			// Unused fragments and variables are removed
+			decl(1),
+			`query($x: Boolean!, $y: Int, $z: Int, $u: Int) {
+				...F @skip(if: $x) a(z: $z)
+			}
+			fragment F on T { b(y: $y) ...G }
+			fragment G on T { c }
+			fragment H on T { d }
+			fragment I on T { e @include(if: false) ...G }`,
+			`{"x": true}`,
+			`query($z: Int, $u: Int) { a(z: $z)
+			}
+			fragment G on T { c }
+			fragment H on T { d }
+			fragment I on T { ...G }`,
+		},
+		{
+			// Fragments used by other operations are kept
+			decl(1),
+			`query A($x: Boolean!) { ...F @skip(if: $x) a }
+			query B { ...F }
+			fragment F on T { f }`,
+			`{"x": true}`,
+			`query A { a }
+			query B { ...F }
+			fragment F on T { f }`,
+		},
+		{
+			// Variables used by fragments of other operations are removed
+			decl(1),
+			`query A($x: Boolean!) { a @include(if: $x) }
+			query B($x: Boolean!) { ...F }
+			fragment F on Query { b(v: $x) }`,
+			`{"x": false}`,
+			`query A {__typename @skip(if: true)}
+			query B($x: Boolean!) { ...F }
+			fragment F on Query { b(v: $x) }`,
+		},
+		{
+			// Empty selection sets
+			decl(1),
+			`query Q($x: Boolean = true) { a @skip(if: $x) { b } c { d @skip(if: $x) } }
+			mutation M { e @include(if: false) }`,
+			`null`,
+			`query Q { c {__typename @skip(if: true)} }
+			mutation M {__typename @skip(if: true)}`,
+		},
+		{
+			decl(1),
+			`{...F} fragment F on T { a @skip(if: true) }`,
+			``,
+			`{...F} fragment F on T {__typename @skip(if: true)}`,
+		},
+		{
+			// Fragments use defaults shared by all operations
+			decl(1),
+			`query A($x: Boolean = true, $y: Boolean = true) { ...F }
+			query B($x: Boolean = true, $y: Boolean = false) { ...F }
+			fragment F on T { a @skip(if: $x) b @skip(if: $y) c }`,
+			``,
+			`query A($y: Boolean = true) { ...F }
+			query B($y: Boolean = false) { ...F }
+			fragment F on T { b @skip(if: $y) c }`,
+		},
+		{
+			decl(1),
+			"{ a # comment\n b @skip(if: true) # comment\n c @include(if: true) }",
+			``,
+			"{ a # comment\n c }",
+		},
+		{
+			// No separators around the pruned selections
+			decl(1),
+			`{a @include(if:true)b}`, ``,
+			`{a b}`,
+		},
+		{
+			decl(1),
+			`{a b @skip(if:true)c}`, ``,
+			`{a c}`,
+		},
+		{
+			decl(1),
+			`{x:a @include(if:true)...F}fragment F on T{f}`, ``,
+			`{x:a ...F}fragment F on T{f}`,
+		},
+		{
+			decl(1),
+			`{a @skip(if:true)}{b}fragment F on T{f @skip(if:true)g}`, ``,
+			`{__typename @skip(if: true)}{b}fragment F on T{g}`,
+		},
+		{
+			decl(1),
+			`query($x:Boolean!){...F@skip(if:$x)a}fragment F on T{f}query{b}`,
+			`{"x":true}`,
+			`query{a} query{b}`,
+		},
+		{
+			decl(1),
+			`query($x:Boolean!,$y:Int){a(y:$y)@skip(if:$x)b}`, `{"x":true}`,
+			`query{b}`,
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			out, err := transform.PruneSkipped(
+				nil, []byte(td.input), []byte(td.vars),
+			)
+			require.NoError(t, err)
+			require.Equal(t, td.expect, string(out))
+			require.False(t, gqlscan.ScanAll(out, func(*gqlscan.Iterator) {}).IsErr())
+		})
+	}
+}
+
+func TestPruneSkippedValid(t *testing.T) {
+	src := `query A($x: Boolean!) { a @include(if: $x) }
+	query B($x: Boolean!) { ...F }
+	fragment F on Query { b(v: $x) }`
+	out, err := transform.PruneSkipped(nil, []byte(src), []byte(`{"x": false}`))
+	require.NoError(t, err)
+	v, err := validate.Validate(out)
+	require.NoError(t, err)
+	require.Empty(t, v)
+}
+
+func TestPruneSkippedErr(t *testing.T) {
+	t.Run("invalid variables", func(t *testing.T) {
+		_, err := transform.PruneSkipped(nil, []byte(`{a}`), []byte(`[`))
+		require.Error(t, err)
+	})
+
+	t.Run("scan error", func(t *testing.T) {
+		_, err := transform.PruneSkipped(nil, []byte(`{a @skip(if: }`), nil)
+		var e gqlscan.Error
+		require.ErrorAs(t, err, &e)
+		require.Equal(t, gqlscan.ErrUnexpToken, e.Code)
+	})
+}
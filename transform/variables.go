@@ -0,0 +1,554 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/internal/ast"
+	"github.com/graph-guard/gqlscan/internal/names"
+)
+
+// TypeFunc returns the type of the variable generated for the value
+// of argument arg of field field, where val is the first token
+// of the value. Returns nil if the type is unknown.
+type TypeFunc func(field, arg []byte, val gqlscan.Token) (typ []byte)
+
+// ExtractVariables appends src to doc replacing literal values
+// of field arguments with references to generated variables,
+// such that:
+//
+//	query Q { user(id: 5) { name } }
+//
+// becomes:
+//
+//	query Q($_v0: Int!) { user(id: $_v0) { name } }
+//
+// and appends the JSON object {"_v0":5} to vars.
+//
+// typeOf is called for every argument value and takes precedence over
+// type inference. If typeOf is nil or returns nil then the type
+// is inferred for Int, Float, String and Boolean literals.
+// Values of unknown type, null literals and values containing variable
+// references are left in place. Arguments of directives and arguments
+// inside fragment definitions are left in place as well,
+// use InlineFragments first to extract those.
+//
+// Generated variable names never collide with declared variable names.
+// Returns a gqlscan.Error if src isn't lexically valid.
+func ExtractVariables(
+	doc, vars, src []byte, typeOf TypeFunc,
+) (docOut, varsOut []byte, err error) {
+	declared := map[string]struct{}{}
+	if err := gqlscan.ScanAll(src, func(i *gqlscan.Iterator) {
+		if i.Token() == gqlscan.TokenVarName {
+			declared[string(i.Value())] = struct{}{}
+		}
+	}); err.IsErr() {
+		return doc, vars, err
+	}
+
+	var (
+		edits  []edit
+		varN   int
+		oprVar []byte // Declarations of the current operation
+		oprEnd int    // Var declarations insertion index
+
+		// oprPrefix and oprSuffix wrap the declarations
+		oprPrefix, oprSuffix string
+
+		inFrag     bool
+		inFieldArg bool
+		afterDir   bool // true if the last name was a directive name
+		field, arg []byte
+		val        literal
+		jw         jsonWriter
+		interpBuf  = make([]byte, 512)
+	)
+
+	vars = append(vars, '{')
+	firstVar := true
+	commitValue := func(end int) {
+		typ := []byte(nil)
+		if typeOf != nil {
+			typ = typeOf(field, arg, val.token)
+		}
+		if typ == nil {
+			typ = inferType(val.token)
+		}
+		if typ == nil || val.hasVarRef || val.token == gqlscan.TokenNull {
+			return
+		}
+
+		var name []byte
+		for {
+			name = append(name[:0], "_v"...)
+			name = strconv.AppendInt(name, int64(varN), 10)
+			varN++
+			if _, ok := declared[string(name)]; !ok {
+				break
+			}
+		}
+
+		edits = append(edits, edit{
+			start: val.start,
+			end:   end,
+			text:  append([]byte{'$'}, name...),
+		})
+		if len(oprVar) > 0 {
+			oprVar = append(oprVar, ", "...)
+		}
+		oprVar = append(oprVar, '$')
+		oprVar = append(oprVar, name...)
+		oprVar = append(oprVar, ": "...)
+		oprVar = append(oprVar, typ...)
+
+		if !firstVar {
+			vars = append(vars, ',')
+		}
+		firstVar = false
+		vars = appendJSONStr(vars, name)
+		vars = append(vars, ':')
+		vars = append(vars, jw.buf...)
+	}
+
+	if err := gqlscan.ScanAll(src, func(i *gqlscan.Iterator) {
+		switch i.Token() {
+		case gqlscan.TokenDefQry:
+			inFrag = false
+			oprVar = oprVar[:0]
+			oprEnd = i.IndexHead()
+			oprPrefix, oprSuffix = "(", ")"
+			if src[i.IndexHead()] == '{' {
+				oprPrefix, oprSuffix = "query(", ") "
+			} else {
+				oprEnd += len("query")
+			}
+			return
+		case gqlscan.TokenDefMut, gqlscan.TokenDefSub:
+			inFrag = false
+			oprVar = oprVar[:0]
+			oprEnd = i.IndexHead() + len("mutation")
+			if i.Token() == gqlscan.TokenDefSub {
+				oprEnd = i.IndexHead() + len("subscription")
+			}
+			oprPrefix, oprSuffix = "(", ")"
+			return
+		case gqlscan.TokenDefFrag:
+			inFrag = true
+			return
+		case gqlscan.TokenOprName:
+			oprEnd = i.IndexHead()
+			return
+		case gqlscan.TokenVarListEnd:
+			oprEnd = i.IndexHead()
+			oprPrefix, oprSuffix = ", ", ""
+			return
+		case gqlscan.TokenSetEnd:
+			if i.LevelSelect() == 1 && !inFrag && len(oprVar) > 0 {
+				t := append([]byte(oprPrefix), oprVar...)
+				edits = append(edits, edit{
+					start: oprEnd,
+					end:   oprEnd,
+					text:  append(t, oprSuffix...),
+				})
+			}
+			return
+		case gqlscan.TokenField:
+			field, afterDir = i.Value(), false
+			return
+		case gqlscan.TokenDirName:
+			afterDir = true
+			return
+		case gqlscan.TokenArgList:
+			inFieldArg = !inFrag && !afterDir
+			return
+		case gqlscan.TokenArgListEnd:
+			inFieldArg = false
+			return
+		case gqlscan.TokenArgName:
+			arg = i.Value()
+			val = literal{start: -1}
+			jw.reset()
+			return
+		}
+		if !inFieldArg {
+			return
+		}
+
+		// Argument value token
+		if val.start == -1 {
+			val.token = i.Token()
+			val.start = ast.ValueStart(i)
+		}
+		switch i.Token() {
+		case gqlscan.TokenVarRef:
+			val.hasVarRef = true
+		case gqlscan.TokenStrBlock:
+			jw.value()
+			jw.buf = append(jw.buf, '"')
+			i.ScanInterpreted(interpBuf, func(b []byte) (stop bool) {
+				jw.buf = appendJSONStrBody(jw.buf, b)
+				return false
+			})
+			jw.buf = append(jw.buf, '"')
+		default:
+			jw.write(i)
+		}
+		if jw.depth == 0 {
+			commitValue(ast.TokenEnd(i))
+		}
+	}); err.IsErr() {
+		return doc, vars, err
+	}
+	vars = append(vars, '}')
+	return applyEdits(doc, src, edits), vars, nil
+}
+
+// InlineVariables appends src to buf replacing every reference
+// to a variable defined in the JSON object vars with the value
+// of the variable and removing the inlined variable definitions.
+// References to variables that aren't defined in vars are left in place.
+//
+// isEnum reports whether the named type typeName is an enum type,
+// string values of variables of enum types are inlined
+// as enum values. If isEnum is nil then strings are always inlined
+// as string values.
+//
+// Returns a gqlscan.Error if src isn't lexically valid.
+func InlineVariables(
+	buf, src, vars []byte, isEnum func(typeName []byte) bool,
+) ([]byte, error) {
+	var values map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(vars))
+	d.UseNumber()
+	if err := d.Decode(&values); err != nil {
+		return buf, fmt.Errorf("decoding variables: %w", err)
+	}
+
+	type varDef struct {
+		name     []byte
+		typeName []byte
+		span     gqlscan.Span
+	}
+
+	var (
+		edits    []edit
+		defs     []varDef
+		listPos  int // Index of the first definition of the current list
+		listFrom int
+		inVarDef bool
+	)
+	typeOf := map[string][]byte{}
+	err := gqlscan.ScanAll(src, func(i *gqlscan.Iterator) {
+		switch i.Token() {
+		case gqlscan.TokenVarList:
+			listPos, listFrom = len(defs), i.IndexHead()
+		case gqlscan.TokenVarName:
+			defs = append(defs, varDef{
+				name: i.Value(),
+				span: gqlscan.Span{Start: i.IndexTail() - 1},
+			})
+			inVarDef = true
+		case gqlscan.TokenVarListEnd:
+			inVarDef = false
+
+			// Rebuild the variable list without inlined definitions
+			e := edit{start: listFrom, end: ast.TokenEnd(i)}
+			for _, v := range defs[listPos:] {
+				if _, ok := values[string(v.name)]; ok {
+					continue
+				}
+				if len(e.text) < 1 {
+					e.text = append(e.text, '(')
+				} else {
+					e.text = append(e.text, ", "...)
+				}
+				e.text = append(e.text, v.span.In(src)...)
+			}
+			if len(e.text) > 0 {
+				e.text = append(e.text, ')')
+			}
+			edits = append(edits, e)
+		case gqlscan.TokenVarRef:
+			v, ok := values[string(i.Value())]
+			if !ok {
+				return
+			}
+			edits = append(edits, edit{
+				start: i.IndexTail() - 1,
+				end:   i.IndexHead(),
+				value: v,
+				name:  i.Value(),
+			})
+		default:
+			if !inVarDef {
+				return
+			}
+			v := &defs[len(defs)-1]
+			v.span.End = ast.TokenEnd(i)
+			if i.Token() != gqlscan.TokenVarTypeName {
+				return
+			}
+			v.typeName = i.Value()
+			if _, ok := typeOf[string(v.name)]; !ok {
+				typeOf[string(v.name)] = v.typeName
+			}
+		}
+	})
+	if err.IsErr() {
+		return buf, err
+	}
+
+	for n := range edits {
+		e := &edits[n]
+		if e.name == nil {
+			continue
+		}
+		enum := isEnum != nil && isEnum(typeOf[string(e.name)])
+		var err error
+		if e.text, err = appendGraphQLValue(nil, e.value, enum); err != nil {
+			return buf, fmt.Errorf("variable %q: %w", e.name, err)
+		}
+	}
+	return applyEdits(buf, src, edits), nil
+}
+
+// edit replaces src[start:end] with text.
+type edit struct {
+	start, end int
+	text       []byte
+
+	// name and value are the name and the JSON value
+	// of the inlined variable.
+	name  []byte
+	value interface{}
+}
+
+// applyEdits appends src to buf applying edits.
+func applyEdits(buf, src []byte, edits []edit) []byte {
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].start < edits[j].start
+	})
+	pos := 0
+	for _, e := range edits {
+		buf = append(buf, src[pos:e.start]...)
+		buf = append(buf, e.text...)
+		pos = e.end
+	}
+	return append(buf, src[pos:]...)
+}
+
+// literal is an argument value.
+type literal struct {
+	token     gqlscan.Token
+	start     int
+	hasVarRef bool
+}
+
+func inferType(t gqlscan.Token) []byte {
+	switch t {
+	case gqlscan.TokenInt:
+		return []byte("Int!")
+	case gqlscan.TokenFloat:
+		return []byte("Float!")
+	case gqlscan.TokenStr, gqlscan.TokenStrBlock:
+		return []byte("String!")
+	case gqlscan.TokenTrue, gqlscan.TokenFalse:
+		return []byte("Boolean!")
+	}
+	return nil
+}
+
+// jsonWriter converts a stream of value tokens to JSON.
+type jsonWriter struct {
+	buf []byte
+
+	// depth is the current array and object nesting depth.
+	depth int
+
+	// empty is true after an array, an object or
+	// an object field was opened.
+	empty bool
+}
+
+func (w *jsonWriter) reset() {
+	w.buf, w.depth, w.empty = w.buf[:0], 0, false
+}
+
+// value writes a separator before a new value if necessary.
+func (w *jsonWriter) value() {
+	if w.depth > 0 && !w.empty {
+		w.buf = append(w.buf, ',')
+	}
+	w.empty = false
+}
+
+func (w *jsonWriter) write(i *gqlscan.Iterator) {
+	switch i.Token() {
+	case gqlscan.TokenArr, gqlscan.TokenObj:
+		w.value()
+		w.buf = append(w.buf, '[')
+		if i.Token() == gqlscan.TokenObj {
+			w.buf[len(w.buf)-1] = '{'
+		}
+		w.depth++
+		w.empty = true
+	case gqlscan.TokenArrEnd:
+		w.buf = append(w.buf, ']')
+		w.depth--
+		w.empty = false
+	case gqlscan.TokenObjEnd:
+		w.buf = append(w.buf, '}')
+		w.depth--
+		w.empty = false
+	case gqlscan.TokenObjField:
+		w.value()
+		w.buf = appendJSONStr(w.buf, i.Value())
+		w.buf = append(w.buf, ':')
+		w.empty = true
+	case gqlscan.TokenStr:
+		// GraphQL string escape sequences are valid in JSON
+		w.value()
+		w.buf = append(w.buf, '"')
+		for _, b := range i.Value() {
+			if b == '\t' {
+				w.buf = append(w.buf, `\t`...)
+				continue
+			}
+			w.buf = append(w.buf, b)
+		}
+		w.buf = append(w.buf, '"')
+	case gqlscan.TokenEnumVal:
+		w.value()
+		w.buf = appendJSONStr(w.buf, i.Value())
+	case gqlscan.TokenTrue:
+		w.value()
+		w.buf = append(w.buf, "true"...)
+	case gqlscan.TokenFalse:
+		w.value()
+		w.buf = append(w.buf, "false"...)
+	case gqlscan.TokenNull:
+		w.value()
+		w.buf = append(w.buf, "null"...)
+	default:
+		// Int and Float literals are valid JSON numbers
+		w.value()
+		w.buf = append(w.buf, i.Value()...)
+	}
+}
+
+func appendJSONStr(buf, s []byte) []byte {
+	buf = append(buf, '"')
+	buf = appendJSONStrBody(buf, s)
+	return append(buf, '"')
+}
+
+// appendJSONStrBody appends s escaped as JSON string contents.
+func appendJSONStrBody(buf, s []byte) []byte {
+	for _, b := range s {
+		switch b {
+		case '"', '\\':
+			buf = append(buf, '\\', b)
+		case '\n':
+			buf = append(buf, `\n`...)
+		case '\r':
+			buf = append(buf, `\r`...)
+		case '\t':
+			buf = append(buf, `\t`...)
+		default:
+			if b < 0x20 {
+				buf = append(buf, `\u00`...)
+				buf = append(buf, hexDigits[b>>4], hexDigits[b&0xF])
+				continue
+			}
+			buf = append(buf, b)
+		}
+	}
+	return buf
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendGraphQLValue appends the GraphQL literal of the JSON value v.
+func appendGraphQLValue(buf []byte, v interface{}, enum bool) ([]byte, error) {
+	var err error
+	switch v := v.(type) {
+	case nil:
+		buf = append(buf, "null"...)
+	case bool:
+		buf = strconv.AppendBool(buf, v)
+	case json.Number:
+		buf = append(buf, v...)
+	case string:
+		if enum {
+			if !names.IsName(v) || v == "true" || v == "false" || v == "null" {
+				return buf, fmt.Errorf("invalid enum value %q", v)
+			}
+			return append(buf, v...), nil
+		}
+		buf = appendGraphQLStr(buf, v)
+	case []interface{}:
+		buf = append(buf, '[')
+		for n, e := range v {
+			if n > 0 {
+				buf = append(buf, ", "...)
+			}
+			if buf, err = appendGraphQLValue(buf, e, enum); err != nil {
+				return buf, err
+			}
+		}
+		buf = append(buf, ']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			if !names.IsName(k) {
+				return buf, fmt.Errorf("invalid object field name %q", k)
+			}
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf = append(buf, '{')
+		for n, k := range keys {
+			if n > 0 {
+				buf = append(buf, ", "...)
+			}
+			buf = append(buf, k...)
+			buf = append(buf, ": "...)
+			// Enum types of input object fields are unknown
+			if buf, err = appendGraphQLValue(buf, v[k], false); err != nil {
+				return buf, err
+			}
+		}
+		buf = append(buf, '}')
+	}
+	return buf, nil
+}
+
+func appendGraphQLStr(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		switch {
+		case r == '"' || r == '\\':
+			buf = append(buf, '\\', byte(r))
+		case r == '\n':
+			buf = append(buf, `\n`...)
+		case r == '\r':
+			buf = append(buf, `\r`...)
+		case r == '\t':
+			buf = append(buf, `\t`...)
+		case r < 0x20:
+			buf = append(buf, `\u00`...)
+			buf = append(buf, hexDigits[r>>4], hexDigits[r&0xF])
+		default:
+			buf = append(buf, s[:size]...)
+		}
+		s = s[size:]
+	}
+	return append(buf, '"')
+}
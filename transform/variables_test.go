@@ -0,0 +1,204 @@
+package transform_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/transform"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractVariables(t *testing.T) {
+	typeOf := func(field, arg []byte, val gqlscan.Token) []byte {
+		switch {
+		case string(field) == "user" && string(arg) == "id":
+			return []byte("ID!")
+		case val == gqlscan.TokenEnumVal:
+			return []byte("Color!")
+		case val == gqlscan.TokenObj:
+			return []byte("Input!")
+		}
+		return nil
+	}
+
+	for _, td := range []struct {
+		decl       string
+		input      string
+		expectDoc  string
+		expectVars string
+	}{
+		{
+			decl(1),
+			`{a}`,
+			`{a}`,
+			`{}`,
+		},
+		{
+			decl(1),
+			`{ user(id: 5) { name } }`,
+			`query($_v0: ID!) { user(id: $_v0) { name } }`,
+			`{"_v0":5}`,
+		},
+		{
+			decl(1),
+			`query Q { f(i: -1, f: 1.5e3, s: "a\"\tb", b: true, n: null) }`,
+			`query Q($_v0: Int!, $_v1: Float!, $_v2: String!, $_v3: Boolean!) ` +
+				`{ f(i: $_v0, f: $_v1, s: $_v2, b: $_v3, n: null) }`,
+			`{"_v0":-1,"_v1":1.5e3,"_v2":"a\"\tb","_v3":true}`,
+		},
+		{
+			decl(1),
+			`query ($_v0: Int) { f(a: $_v0, b: 1) }`,
+			`query ($_v0: Int, $_v1: Int!) { f(a: $_v0, b: $_v1) }`,
+			`{"_v1":1}`,
+		},
+		{
+			decl(1),
+			`mutation { f(c: RED, o: {a: [1 "x"], b: {c: null}}, l: [1]) }`,
+			`mutation($_v0: Color!, $_v1: Input!) ` +
+				`{ f(c: $_v0, o: $_v1, l: [1]) }`,
+			`{"_v0":"RED","_v1":{"a":[1,"x"],"b":{"c":null}}}`,
+		},
+		{
+			decl(1),
+			`subscription S { f(o: {a: $v}) }`,
+			`subscription S { f(o: {a: $v}) }`,
+			`{}`,
+		},
+		{
+			decl(1),
+			`{ f(s: """
+			   a"b
+			   c
+			""") @include(if: true) { x @d(a: 1) } }
+			fragment F on T { f(x: 1) }`,
+			`query($_v0: String!) { f(s: $_v0) @include(if: true) { x @d(a: 1) } }
+			fragment F on T { f(x: 1) }`,
+			`{"_v0":"a\"b\nc"}`,
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			doc, vars, err := transform.ExtractVariables(
+				nil, nil, []byte(td.input), typeOf,
+			)
+			require.NoError(t, err)
+			require.Equal(t, td.expectDoc, string(doc))
+			require.Equal(t, td.expectVars, string(vars))
+			require.True(t, json.Valid(vars))
+			requireValid(t, doc)
+		})
+	}
+
+	t.Run("scan error", func(t *testing.T) {
+		_, _, err := transform.ExtractVariables(nil, nil, []byte(`{f(a:`), nil)
+		var e gqlscan.Error
+		require.ErrorAs(t, err, &e)
+		require.Equal(t, gqlscan.ErrUnexpEOF, e.Code)
+	})
+}
+
+func TestInlineVariables(t *testing.T) {
+	isEnum := func(typeName []byte) bool {
+		return string(typeName) == "Color"
+	}
+
+	for _, td := range []struct {
+		decl   string
+		input  string
+		vars   string
+		expect string
+	}{
+		{
+			decl(1),
+			`{a}`,
+			`{}`,
+			`{a}`,
+		},
+		{
+			decl(1),
+			`query Q($id: ID!) { user(id: $id) { name } }`,
+			`{"id": "5"}`,
+			`query Q { user(id: "5") { name } }`,
+		},
+		{
+			decl(1),
+			`query Q($a: Int = 1 @d(x: 2), $b: [Color!]!, $c: String, $d: T) ` +
+				`{ f(a: $a, b: $b, c: $c, d: $d) }`,
+			`{"b": ["RED", "GREEN"], "c": "x\n\"y\""}`,
+			`query Q($a: Int = 1 @d(x: 2), $d: T) ` +
+				`{ f(a: $a, b: [RED, GREEN], c: "x\n\"y\"", d: $d) }`,
+		},
+		{
+			decl(1),
+			`query($o: In, $n: Float) { f(o: $o, n: $n) } ` +
+				`fragment F on T { g(o: $o) }`,
+			`{"o": {"b": [1, 2.5], "a": null, "c": true}, "n": 1e3}`,
+			`query { f(o: {a: null, b: [1, 2.5], c: true}, n: 1e3) } ` +
+				`fragment F on T { g(o: {a: null, b: [1, 2.5], c: true}) }`,
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			out, err := transform.InlineVariables(
+				nil, []byte(td.input), []byte(td.vars), isEnum,
+			)
+			require.NoError(t, err)
+			require.Equal(t, td.expect, string(out))
+			requireValid(t, out)
+		})
+	}
+}
+
+func TestInlineVariablesErr(t *testing.T) {
+	for _, td := range []struct {
+		decl   string
+		input  string
+		vars   string
+		expect string
+	}{
+		{
+			decl(1),
+			`query($a: In) { f(a: $a) }`,
+			`[]`,
+			"decoding variables: json: cannot unmarshal " +
+				"array into Go value of type map[string]interface {}",
+		},
+		{
+			decl(1),
+			`query($a: In) { f(a: $a) }`,
+			`{"a": {"not a name": 1}}`,
+			`variable "a": invalid object field name "not a name"`,
+		},
+		{
+			decl(1),
+			`query($a: Color) { f(a: $a) }`,
+			`{"a": "true"}`,
+			`variable "a": invalid enum value "true"`,
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			_, err := transform.InlineVariables(
+				nil, []byte(td.input), []byte(td.vars),
+				func(typeName []byte) bool { return string(typeName) == "Color" },
+			)
+			require.Error(t, err)
+			require.Equal(t, td.expect, err.Error())
+		})
+	}
+}
+
+func TestExtractInlineVariablesRoundtrip(t *testing.T) {
+	const input = `query Q { f(a: 1, b: "x", c: 2.5, d: false) { g(h: "y") } }`
+	doc, vars, err := transform.ExtractVariables(nil, nil, []byte(input), nil)
+	require.NoError(t, err)
+	out, err := transform.InlineVariables(nil, doc, vars, nil)
+	require.NoError(t, err)
+	require.Equal(t, input, string(out))
+}
+
+// requireValid makes sure doc is lexically valid.
+func requireValid(t *testing.T, doc []byte) {
+	err := gqlscan.ScanAll(doc, func(*gqlscan.Iterator) {})
+	require.False(t, err.IsErr(), err.Error())
+}
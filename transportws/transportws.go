@@ -0,0 +1,428 @@
+// Package transportws implements the server side of the message
+// exchange of the graphql-transport-ws WebSocket subprotocol
+// on top of the gqlscan token stream.
+//
+// Conn reads and writes messages as a stream of JSON values and is
+// thus independent of the WebSocket implementation, which only needs
+// to provide the text messages of a connection as an io.ReadWriter.
+package transportws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/gqlerr"
+	"github.com/graph-guard/gqlscan/gqlhttp"
+)
+
+// Message types.
+const (
+	MsgConnectionInit = "connection_init"
+	MsgConnectionAck  = "connection_ack"
+	MsgPing           = "ping"
+	MsgPong           = "pong"
+	MsgSubscribe      = "subscribe"
+	MsgNext           = "next"
+	MsgError          = "error"
+	MsgComplete       = "complete"
+)
+
+// WebSocket close codes defined by the protocol.
+const (
+	CloseInvalidMessage      = 4400
+	CloseUnauthorized        = 4401
+	CloseSubscriberExists    = 4409
+	CloseTooManyInitRequests = 4429
+)
+
+// CloseError is returned when the protocol requires the WebSocket
+// connection to be closed with Code and Reason.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("close %d: %s", e.Code, e.Reason)
+}
+
+// Message is a protocol message.
+type Message struct {
+	Type    string          `json:"type"`
+	ID      string          `json:"id,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Subscription is a subscription requested by a subscribe message.
+type Subscription struct {
+	// Query is the GraphQL document.
+	Query []byte
+
+	// OperationName is the name of the operation to execute
+	// or nil if none was provided.
+	OperationName []byte
+
+	// Variables is the JSON object of variable values
+	// or nil if none was provided.
+	Variables json.RawMessage
+
+	// Extensions is the JSON object of extensions
+	// or nil if none was provided.
+	Extensions json.RawMessage
+
+	// Operation is the subscription operation selected by OperationName.
+	Operation gqlscan.Operation
+}
+
+// Event is a message read by Conn.Read.
+type Event struct {
+	// Type is either MsgConnectionInit, MsgSubscribe or MsgComplete.
+	Type string
+
+	// ID is the operation ID of subscribe and complete messages.
+	ID string
+
+	// Payload is the payload of connection_init messages
+	// or nil if there is none.
+	Payload json.RawMessage
+
+	// Subscription is the subscription of subscribe messages.
+	Subscription *Subscription
+}
+
+// Conn is the server side of a graphql-transport-ws connection.
+// Read must not be called concurrently, all other methods
+// may be called concurrently.
+type Conn struct {
+	dec *json.Decoder
+
+	lock   sync.Mutex
+	enc    *json.Encoder
+	inited bool
+	acked  bool
+
+	// active holds the IDs of the active operations.
+	active map[string]struct{}
+}
+
+// NewConn returns a new connection reading and writing
+// messages from and to rw.
+func NewConn(rw io.ReadWriter) *Conn {
+	return &Conn{
+		dec:    json.NewDecoder(rw),
+		enc:    json.NewEncoder(rw),
+		active: map[string]struct{}{},
+	}
+}
+
+// Read reads messages until it reads a connection_init, a valid
+// subscribe or a complete message. Ping messages are answered with
+// pong messages and pong messages are ignored.
+//
+// Subscribe messages are answered with an error message and skipped
+// if the query isn't lexically valid, if the operation can't be
+// selected, if it isn't a subscription or if it selects more than one
+// root field, including the fields selected through fragments.
+// Valid subscriptions are active until they're completed by either
+// the client or Complete or Error.
+//
+// Returns io.EOF when there are no more messages and a *CloseError
+// if the protocol requires the connection to be closed.
+func (c *Conn) Read() (Event, error) {
+	for {
+		var m Message
+		if err := c.dec.Decode(&m); err != nil {
+			if errors.Is(err, io.EOF) {
+				return Event{}, io.EOF
+			}
+			var errSyntax *json.SyntaxError
+			var errType *json.UnmarshalTypeError
+			if errors.As(err, &errSyntax) || errors.As(err, &errType) {
+				return Event{}, errInvalid("invalid message received")
+			}
+			return Event{}, err
+		}
+
+		switch m.Type {
+		case MsgConnectionInit:
+			c.lock.Lock()
+			inited := c.inited
+			c.inited = true
+			c.lock.Unlock()
+			if inited {
+				return Event{}, &CloseError{
+					Code:   CloseTooManyInitRequests,
+					Reason: "too many initialisation requests",
+				}
+			}
+			return Event{Type: m.Type, Payload: payload(m.Payload)}, nil
+		case MsgPing:
+			if err := c.write(Message{Type: MsgPong}); err != nil {
+				return Event{}, err
+			}
+		case MsgPong:
+		case MsgSubscribe:
+			s, err := c.subscribe(m)
+			if err != nil {
+				return Event{}, err
+			}
+			if s != nil {
+				return Event{Type: m.Type, ID: m.ID, Subscription: s}, nil
+			}
+		case MsgComplete:
+			if m.ID == "" {
+				return Event{}, errInvalid("missing operation id")
+			}
+			c.lock.Lock()
+			delete(c.active, m.ID)
+			c.lock.Unlock()
+			return Event{Type: m.Type, ID: m.ID}, nil
+		default:
+			return Event{}, errInvalid(
+				fmt.Sprintf("unexpected message type %q", m.Type),
+			)
+		}
+	}
+}
+
+// subscribe returns the subscription requested by m
+// or nil if m was answered with an error message.
+func (c *Conn) subscribe(m Message) (*Subscription, error) {
+	c.lock.Lock()
+	acked := c.acked
+	_, exists := c.active[m.ID]
+	c.lock.Unlock()
+	switch {
+	case !acked:
+		return nil, &CloseError{Code: CloseUnauthorized, Reason: "unauthorized"}
+	case m.ID == "":
+		return nil, errInvalid("missing operation id")
+	case exists:
+		return nil, &CloseError{
+			Code:   CloseSubscriberExists,
+			Reason: fmt.Sprintf("subscriber for %s already exists", m.ID),
+		}
+	}
+
+	b, _, err := gqlhttp.ReadJSONBody(nil, m.Payload)
+	switch {
+	case err != nil:
+		return nil, errInvalid(fmt.Sprintf("invalid subscribe payload: %s", err))
+	case b.Query == nil:
+		return nil, errInvalid("invalid subscribe payload: missing query")
+	case !isObjOrNil(b.Variables):
+		return nil, errInvalid(
+			"invalid subscribe payload: variables must be a JSON object",
+		)
+	case !isObjOrNil(b.Extensions):
+		return nil, errInvalid(
+			"invalid subscribe payload: extensions must be a JSON object",
+		)
+	}
+	s := &Subscription{
+		Query:         b.Query,
+		OperationName: b.OperationName,
+		Variables:     b.Variables,
+		Extensions:    b.Extensions,
+	}
+
+	if e := s.selectOperation(); e != nil {
+		// The error message completes the operation
+		return nil, c.write(Message{
+			Type: MsgError, ID: m.ID, Payload: mustMarshal([]*gqlerr.Error{e}),
+		})
+	}
+	c.lock.Lock()
+	c.active[m.ID] = struct{}{}
+	c.lock.Unlock()
+	return s, nil
+}
+
+// selectOperation selects the operation and makes sure
+// it's a subscription selecting a single root field.
+func (s *Subscription) selectOperation() *gqlerr.Error {
+	o, err := gqlscan.SelectOperation(s.Query, s.OperationName)
+	if err != nil {
+		e := &gqlerr.Error{Message: err.Error()}
+		var errScan gqlscan.Error
+		if errors.As(err, &errScan) {
+			e.Locations = []gqlerr.Location{
+				gqlerr.LocationOf(s.Query, errScan.Index),
+			}
+		}
+		return e
+	}
+	if o.Token != gqlscan.TokenDefSub {
+		return &gqlerr.Error{
+			Message: fmt.Sprintf(
+				"%s isn't a subscription", describe(o),
+			),
+			Locations: []gqlerr.Location{
+				gqlerr.LocationOf(s.Query, o.Span.Start),
+			},
+		}
+	}
+	s.Operation = o
+	if index := secondRootField(s.Query, o); index > -1 {
+		return &gqlerr.Error{
+			Message: fmt.Sprintf(
+				"%s must select only one top level field", describe(o),
+			),
+			Locations: []gqlerr.Location{gqlerr.LocationOf(s.Query, index)},
+		}
+	}
+	return nil
+}
+
+// secondRootField returns the index of the first root field of o
+// whose response name differs from the one of the first root field,
+// or -1 if there is none. Root fields are the fields of the selection
+// set of o and of the inline fragments and fragments spread in it.
+func secondRootField(src []byte, o gqlscan.Operation) int {
+	// Spans of valid definitions are valid definitions themselves
+	frags := make(map[string]gqlscan.Span, len(o.Frags))
+	for _, f := range o.Frags {
+		_ = gqlscan.Scan(f.In(src), func(i *gqlscan.Iterator) (stop bool) {
+			if i.Token() == gqlscan.TokenFragName {
+				frags[string(i.Value())] = f
+				return true
+			}
+			return false
+		})
+	}
+
+	var first []byte
+	index := -1
+	visited := map[string]bool{}
+	queue := []gqlscan.Span{o.Span}
+	for len(queue) > 0 && index < 0 {
+		span := queue[0]
+		queue = queue[1:]
+		var (
+			// root holds whether the open selection sets are root sets.
+			root    []bool
+			lastSel gqlscan.Token
+			alias   []byte
+		)
+		_ = gqlscan.Scan(span.In(src), func(i *gqlscan.Iterator) (stop bool) {
+			isRoot := len(root) > 0 && root[len(root)-1]
+			switch i.Token() {
+			case gqlscan.TokenSet:
+				root = append(root, len(root) == 0 ||
+					(isRoot && lastSel == gqlscan.TokenFragInline))
+			case gqlscan.TokenSetEnd:
+				root = root[:len(root)-1]
+			case gqlscan.TokenFieldAlias:
+				alias = i.Value()
+			case gqlscan.TokenField:
+				lastSel = gqlscan.TokenField
+				name := i.Value()
+				if alias != nil {
+					name, alias = alias, nil
+				}
+				if !isRoot {
+					break
+				}
+				if first == nil {
+					first = name
+				} else if string(name) != string(first) {
+					index = span.Start + i.IndexTail()
+					return true
+				}
+			case gqlscan.TokenFragInline:
+				lastSel = gqlscan.TokenFragInline
+			case gqlscan.TokenNamedSpread:
+				lastSel = gqlscan.TokenNamedSpread
+				if isRoot && !visited[string(i.Value())] {
+					visited[string(i.Value())] = true
+					queue = append(queue, frags[string(i.Value())])
+				}
+			}
+			return false
+		})
+	}
+	return index
+}
+
+// Ack answers the connection_init message with a connection_ack
+// message carrying payload, which may be nil.
+// Subscribe messages are only accepted after Ack.
+func (c *Conn) Ack(payload json.RawMessage) error {
+	c.lock.Lock()
+	c.acked = true
+	c.lock.Unlock()
+	return c.write(Message{Type: MsgConnectionAck, Payload: payload})
+}
+
+// Next sends the execution result of the operation with id.
+func (c *Conn) Next(id string, result json.RawMessage) error {
+	return c.write(Message{Type: MsgNext, ID: id, Payload: result})
+}
+
+// Error sends errs as the result of the operation with id
+// and completes the operation.
+func (c *Conn) Error(id string, errs ...*gqlerr.Error) error {
+	c.lock.Lock()
+	delete(c.active, id)
+	c.lock.Unlock()
+	return c.write(Message{
+		Type: MsgError, ID: id, Payload: mustMarshal(errs),
+	})
+}
+
+// Complete completes the operation with id.
+func (c *Conn) Complete(id string) error {
+	c.lock.Lock()
+	delete(c.active, id)
+	c.lock.Unlock()
+	return c.write(Message{Type: MsgComplete, ID: id})
+}
+
+func (c *Conn) write(m Message) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.enc.Encode(m)
+}
+
+func errInvalid(reason string) *CloseError {
+	return &CloseError{Code: CloseInvalidMessage, Reason: reason}
+}
+
+// payload returns nil if p is null.
+func payload(p json.RawMessage) json.RawMessage {
+	if string(p) == "null" {
+		return nil
+	}
+	return p
+}
+
+// isObjOrNil returns true if v is either nil or a valid JSON object.
+func isObjOrNil(v []byte) bool {
+	return v == nil || v[0] == '{'
+}
+
+func describe(o gqlscan.Operation) string {
+	kind := "query"
+	if o.Token == gqlscan.TokenDefMut {
+		kind = "mutation"
+	} else if o.Token == gqlscan.TokenDefSub {
+		kind = "subscription"
+	}
+	if o.Name == nil {
+		return "anonymous " + kind
+	}
+	return fmt.Sprintf("%s %q", kind, o.Name)
+}
+
+func mustMarshal(v interface{}) json.RawMessage {
+	b, err := json.Marshal(v)
+	if err != nil {
+		// Errors only contain marshalable values
+		panic(err)
+	}
+	return b
+}
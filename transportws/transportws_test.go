@@ -0,0 +1,260 @@
+package transportws_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/gqlerr"
+	"github.com/graph-guard/gqlscan/transportws"
+
+	"github.com/stretchr/testify/require"
+)
+
+// pipe returns the server side of an in-memory connection that reads
+// the client messages in input. The messages sent by the server are
+// received from the returned channel, which is closed when the server
+// side is closed.
+func pipe(t *testing.T, input ...string) (
+	server net.Conn, c *transportws.Conn, received <-chan transportws.Message,
+) {
+	server, client := net.Pipe()
+	t.Cleanup(func() { server.Close() })
+	go func() {
+		for _, m := range input {
+			if _, err := io.WriteString(client, m); err != nil {
+				return
+			}
+		}
+	}()
+	r := make(chan transportws.Message)
+	go func() {
+		defer close(r)
+		dec := json.NewDecoder(client)
+		for {
+			var m transportws.Message
+			if err := dec.Decode(&m); err != nil {
+				return
+			}
+			r <- m
+		}
+	}()
+	return server, transportws.NewConn(server), r
+}
+
+func TestConn(t *testing.T) {
+	server, c, received := pipe(t,
+		`{"type":"connection_init","payload":{"token":"x"}}`,
+		`{"type":"ping"}`,
+		`{"type":"pong"}`,
+		`{"type":"subscribe","id":"1","payload":{"query":"subscription {"}}`,
+		`{"type":"subscribe","id":"2","payload":{"query":"query Q {a}"}}`,
+		`{"type":"subscribe","id":"3","payload":{`+
+			`"query":"subscription {a ...F} fragment F on S {... on S {b}}"}}`,
+		`{"type":"subscribe","id":"4","payload":{`+
+			`"query":"subscription S($v: Int) {...F a} `+
+			`fragment F on S {a {b c}}",`+
+			`"operationName":"S","variables":{"v":1}}}`,
+		`{"type":"complete","id":"4"}`,
+	)
+	var messages []transportws.Message
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for m := range received {
+			messages = append(messages, m)
+		}
+	}()
+
+	e, err := c.Read()
+	require.NoError(t, err)
+	require.Equal(t, transportws.Event{
+		Type:    transportws.MsgConnectionInit,
+		Payload: json.RawMessage(`{"token":"x"}`),
+	}, e)
+	require.NoError(t, c.Ack(nil))
+
+	e, err = c.Read()
+	require.NoError(t, err)
+	require.Equal(t, transportws.MsgSubscribe, e.Type)
+	require.Equal(t, "4", e.ID)
+	require.Equal(t, "S", string(e.Subscription.OperationName))
+	require.Equal(t, `{"v":1}`, string(e.Subscription.Variables))
+	require.Equal(t, gqlscan.TokenDefSub, e.Subscription.Operation.Token)
+	require.Equal(t, "S", string(e.Subscription.Operation.Name))
+	require.NoError(t, c.Next("4", json.RawMessage(`{"data":{"a":1}}`)))
+
+	e, err = c.Read()
+	require.NoError(t, err)
+	require.Equal(t, transportws.Event{
+		Type: transportws.MsgComplete, ID: "4",
+	}, e)
+
+	require.NoError(t, c.Complete("5"))
+	require.NoError(t, server.Close())
+	<-done
+
+	require.Equal(t, []transportws.Message{
+		{Type: transportws.MsgConnectionAck},
+		{Type: transportws.MsgPong},
+		{
+			Type: transportws.MsgError, ID: "1",
+			Payload: json.RawMessage(`[{"message":"error at index 14: ` +
+				`unexpected end of file; expected selection",` +
+				`"locations":[{"line":1,"column":15}]}]`),
+		},
+		{
+			Type: transportws.MsgError, ID: "2",
+			Payload: json.RawMessage(`[{"message":"query \"Q\" ` +
+				`isn't a subscription","locations":[{"line":1,"column":1}]}]`),
+		},
+		{
+			Type: transportws.MsgError, ID: "3",
+			Payload: json.RawMessage(`[{"message":"anonymous subscription ` +
+				`must select only one top level field",` +
+				`"locations":[{"line":1,"column":50}]}]`),
+		},
+		{
+			Type: transportws.MsgNext, ID: "4",
+			Payload: json.RawMessage(`{"data":{"a":1}}`),
+		},
+		{Type: transportws.MsgComplete, ID: "5"},
+	}, messages)
+}
+
+func TestConnError(t *testing.T) {
+	const sub = `{"type":"subscribe","id":"1","payload":{"query":"subscription {a}"}}`
+	_, c, received := pipe(t, `{"type":"connection_init"}`, sub, sub)
+	_, err := c.Read()
+	require.NoError(t, err)
+	errc := make(chan error, 1)
+	go func() { errc <- c.Ack(json.RawMessage(`{"v":1}`)) }()
+	require.Equal(t, transportws.Message{
+		Type:    transportws.MsgConnectionAck,
+		Payload: json.RawMessage(`{"v":1}`),
+	}, <-received)
+	require.NoError(t, <-errc)
+
+	e, err := c.Read()
+	require.NoError(t, err)
+	require.Equal(t, "1", e.ID)
+
+	go func() { errc <- c.Error("1", &gqlerr.Error{Message: "failed"}) }()
+	require.Equal(t, transportws.Message{
+		Type:    transportws.MsgError,
+		ID:      "1",
+		Payload: json.RawMessage(`[{"message":"failed"}]`),
+	}, <-received)
+	require.NoError(t, <-errc)
+
+	// The error completed the operation, its ID can be reused
+	e, err = c.Read()
+	require.NoError(t, err)
+	require.Equal(t, "1", e.ID)
+}
+
+func TestConnClose(t *testing.T) {
+	const (
+		init = `{"type":"connection_init"}`
+		sub  = `{"type":"subscribe","id":"1","payload":{"query":"subscription {a}"}}`
+	)
+	for _, td := range []struct {
+		decl   string
+		input  []string
+		expect transportws.CloseError
+	}{
+		{
+			decl(1), []string{sub},
+			transportws.CloseError{Code: 4401, Reason: "unauthorized"},
+		},
+		{
+			decl(1), []string{init, init},
+			transportws.CloseError{
+				Code: 4429, Reason: "too many initialisation requests",
+			},
+		},
+		{
+			decl(1), []string{init, sub, sub},
+			transportws.CloseError{
+				Code: 4409, Reason: "subscriber for 1 already exists",
+			},
+		},
+		{
+			decl(1), []string{`{"type":`, `}`},
+			transportws.CloseError{
+				Code: 4400, Reason: "invalid message received",
+			},
+		},
+		{
+			decl(1), []string{`{"type":1}`},
+			transportws.CloseError{
+				Code: 4400, Reason: "invalid message received",
+			},
+		},
+		{
+			decl(1), []string{`{"type":"x"}`},
+			transportws.CloseError{
+				Code: 4400, Reason: `unexpected message type "x"`,
+			},
+		},
+		{
+			decl(1), []string{init, `{"type":"subscribe","payload":{"query":"{a}"}}`},
+			transportws.CloseError{Code: 4400, Reason: "missing operation id"},
+		},
+		{
+			decl(1), []string{`{"type":"complete"}`},
+			transportws.CloseError{Code: 4400, Reason: "missing operation id"},
+		},
+		{
+			decl(1), []string{init, `{"type":"subscribe","id":"1","payload":{}}`},
+			transportws.CloseError{
+				Code: 4400, Reason: "invalid subscribe payload: missing query",
+			},
+		},
+		{
+			decl(1), []string{init, `{"type":"subscribe","id":"1"}`},
+			transportws.CloseError{
+				Code:   4400,
+				Reason: "invalid subscribe payload: malformed JSON: unexpected end of input",
+			},
+		},
+		{
+			decl(1), []string{init, `{"type":"subscribe","id":"1",` +
+				`"payload":{"query":"subscription {a}","variables":[]}}`},
+			transportws.CloseError{
+				Code:   4400,
+				Reason: "invalid subscribe payload: variables must be a JSON object",
+			},
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			_, c, received := pipe(t, td.input...)
+			go func() {
+				for range received {
+				}
+			}()
+			for {
+				e, err := c.Read()
+				if err != nil {
+					var errClose *transportws.CloseError
+					require.ErrorAs(t, err, &errClose)
+					require.Equal(t, td.expect, *errClose)
+					return
+				}
+				if e.Type == transportws.MsgConnectionInit {
+					require.NoError(t, c.Ack(nil))
+				}
+			}
+		})
+	}
+}
+
+func decl(skipFrames int) string {
+	_, filename, line, _ := runtime.Caller(skipFrames)
+	return fmt.Sprintf("%s:%d", filepath.Base(filename), line)
+}
@@ -0,0 +1,10 @@
+// Package validate validates GraphQL executable documents
+// against the validation rules of the GraphQL specification
+// on top of the gqlscan token stream.
+//
+// Validate checks the rules that don't require a schema.
+// Schema.Validate additionally checks the rules that require a schema
+// loaded from an introspection result using LoadSchema.
+// Variables validates JSON variable values against
+// the variable definitions of an operation.
+package validate
@@ -0,0 +1,315 @@
+package validate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/internal/ast"
+)
+
+// ErrNoSchema is returned by LoadSchema when the introspection result
+// doesn't contain a __schema object.
+var ErrNoSchema = errors.New("missing __schema")
+
+// Schema is a compact in-memory GraphQL schema
+// loaded from an introspection result.
+type Schema struct {
+	// roots maps TokenDefQry, TokenDefMut and TokenDefSub
+	// to the root operation types.
+	roots map[gqlscan.Token]*schemaType
+	types map[string]*schemaType
+	dirs  map[string]*schemaDir
+}
+
+// typeKind is the kind of a named type.
+type typeKind int8
+
+const (
+	_ typeKind = iota
+	kindScalar
+	kindObject
+	kindInterface
+	kindUnion
+	kindEnum
+	kindInputObject
+)
+
+// schemaType is a named type.
+type schemaType struct {
+	kind typeKind
+	name string
+
+	// fields are the fields of object and interface types and
+	// the input fields of input object types.
+	fields map[string]*schemaField
+
+	// possible are the names of the possible types of abstract types.
+	possible map[string]bool
+}
+
+// isComposite returns true for object, interface and union types.
+func (t *schemaType) isComposite() bool {
+	return t.kind == kindObject || t.kind == kindInterface ||
+		t.kind == kindUnion
+}
+
+// isLeaf returns true for scalar and enum types.
+func (t *schemaType) isLeaf() bool {
+	return t.kind == kindScalar || t.kind == kindEnum
+}
+
+// possibleTypes returns the names of the object types
+// that an object of type t can be of.
+func (t *schemaType) possibleTypes() map[string]bool {
+	if t.kind == kindObject {
+		return map[string]bool{t.name: true}
+	}
+	return t.possible
+}
+
+// schemaField is a field, an argument or an input field.
+type schemaField struct {
+	name string
+	typ  *ast.TypeRef
+	args []*schemaField
+
+	// hasDefault is true for arguments and input fields
+	// with a default value.
+	hasDefault bool
+}
+
+// schemaDir is a directive definition.
+type schemaDir struct {
+	name      string
+	args      []*schemaField
+	locations map[string]bool
+}
+
+// LoadSchema loads a schema from the JSON introspection result src.
+// src is either the response to an introspection query
+// with the __schema object in "data" or an object containing
+// __schema at the top level.
+//
+// Returns ErrNoSchema if src doesn't contain a __schema object.
+func LoadSchema(src []byte) (*Schema, error) {
+	var r struct {
+		Data struct {
+			Schema *jsonSchema `json:"__schema"`
+		} `json:"data"`
+		Schema *jsonSchema `json:"__schema"`
+	}
+	if err := json.Unmarshal(src, &r); err != nil {
+		return nil, fmt.Errorf("decoding introspection result: %w", err)
+	}
+	js := r.Schema
+	if js == nil {
+		js = r.Data.Schema
+	}
+	if js == nil {
+		return nil, ErrNoSchema
+	}
+
+	s := &Schema{
+		roots: map[gqlscan.Token]*schemaType{},
+		types: make(map[string]*schemaType, len(js.Types)),
+		dirs:  make(map[string]*schemaDir, len(js.Directives)),
+	}
+	for _, jt := range js.Types {
+		t := &schemaType{name: jt.Name, kind: kinds[jt.Kind]}
+		if t.kind == 0 {
+			return nil, fmt.Errorf("type %q: unknown kind %q", jt.Name, jt.Kind)
+		}
+		if len(jt.Fields)+len(jt.InputFields) > 0 {
+			t.fields = make(map[string]*schemaField)
+		}
+		for _, jf := range jt.Fields {
+			f := &schemaField{name: jf.Name}
+			var err error
+			if f.typ, err = jf.Type.typeRef(); err != nil {
+				return nil, fmt.Errorf("field %s.%s: %w", jt.Name, jf.Name, err)
+			}
+			if f.args, err = inputValues(jf.Args); err != nil {
+				return nil, fmt.Errorf("field %s.%s: %w", jt.Name, jf.Name, err)
+			}
+			t.fields[f.name] = f
+		}
+		fields, err := inputValues(jt.InputFields)
+		if err != nil {
+			return nil, fmt.Errorf("type %s: %w", jt.Name, err)
+		}
+		for _, f := range fields {
+			t.fields[f.name] = f
+		}
+		if len(jt.PossibleTypes) > 0 {
+			t.possible = make(map[string]bool, len(jt.PossibleTypes))
+		}
+		for _, p := range jt.PossibleTypes {
+			t.possible[p.Name] = true
+		}
+		s.types[t.name] = t
+	}
+
+	for tok, r := range map[gqlscan.Token]*jsonName{
+		gqlscan.TokenDefQry: js.QueryType,
+		gqlscan.TokenDefMut: js.MutationType,
+		gqlscan.TokenDefSub: js.SubscriptionType,
+	} {
+		if r == nil {
+			continue
+		}
+		if s.roots[tok] = s.types[r.Name]; s.roots[tok] == nil {
+			return nil, fmt.Errorf("undefined root type %q", r.Name)
+		}
+	}
+
+	for _, jd := range js.Directives {
+		d := &schemaDir{
+			name:      jd.Name,
+			locations: make(map[string]bool, len(jd.Locations)),
+		}
+		for _, l := range jd.Locations {
+			d.locations[l] = true
+		}
+		var err error
+		if d.args, err = inputValues(jd.Args); err != nil {
+			return nil, fmt.Errorf("directive @%s: %w", jd.Name, err)
+		}
+		s.dirs[d.name] = d
+	}
+	return s, nil
+}
+
+var kinds = map[string]typeKind{
+	"SCALAR":       kindScalar,
+	"OBJECT":       kindObject,
+	"INTERFACE":    kindInterface,
+	"UNION":        kindUnion,
+	"ENUM":         kindEnum,
+	"INPUT_OBJECT": kindInputObject,
+}
+
+type jsonSchema struct {
+	QueryType        *jsonName `json:"queryType"`
+	MutationType     *jsonName `json:"mutationType"`
+	SubscriptionType *jsonName `json:"subscriptionType"`
+	Types            []struct {
+		Kind   string `json:"kind"`
+		Name   string `json:"name"`
+		Fields []struct {
+			Name string           `json:"name"`
+			Args []jsonInputValue `json:"args"`
+			Type jsonTypeRef      `json:"type"`
+		} `json:"fields"`
+		InputFields   []jsonInputValue `json:"inputFields"`
+		PossibleTypes []jsonName       `json:"possibleTypes"`
+	} `json:"types"`
+	Directives []struct {
+		Name      string           `json:"name"`
+		Locations []string         `json:"locations"`
+		Args      []jsonInputValue `json:"args"`
+	} `json:"directives"`
+}
+
+type jsonName struct {
+	Name string `json:"name"`
+}
+
+type jsonInputValue struct {
+	Name         string      `json:"name"`
+	Type         jsonTypeRef `json:"type"`
+	DefaultValue *string     `json:"defaultValue"`
+}
+
+type jsonTypeRef struct {
+	Kind   string       `json:"kind"`
+	Name   string       `json:"name"`
+	OfType *jsonTypeRef `json:"ofType"`
+}
+
+func (r *jsonTypeRef) typeRef() (*ast.TypeRef, error) {
+	switch r.Kind {
+	case "NON_NULL", "LIST":
+		if r.OfType == nil {
+			return nil, fmt.Errorf("%s type without ofType", r.Kind)
+		}
+		t, err := r.OfType.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		if r.Kind == "LIST" {
+			return &ast.TypeRef{Elem: t}, nil
+		}
+		t.NonNull = true
+		return t, nil
+	}
+	if r.Name == "" {
+		return nil, fmt.Errorf("%s type without name", r.Kind)
+	}
+	return &ast.TypeRef{Name: []byte(r.Name)}, nil
+}
+
+func inputValues(js []jsonInputValue) ([]*schemaField, error) {
+	fields := make([]*schemaField, len(js))
+	for n, j := range js {
+		t, err := j.Type.typeRef()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", j.Name, err)
+		}
+		fields[n] = &schemaField{
+			name:       j.Name,
+			typ:        t,
+			hasDefault: j.DefaultValue != nil,
+		}
+	}
+	return fields, nil
+}
+
+var (
+	fieldTypename = &schemaField{
+		name: "__typename",
+		typ:  &ast.TypeRef{Name: []byte("String"), NonNull: true},
+	}
+	fieldSchema = &schemaField{
+		name: "__schema",
+		typ:  &ast.TypeRef{Name: []byte("__Schema"), NonNull: true},
+	}
+	fieldType = &schemaField{
+		name: "__type",
+		typ:  &ast.TypeRef{Name: []byte("__Type")},
+		args: []*schemaField{{
+			name: "name",
+			typ:  &ast.TypeRef{Name: []byte("String"), NonNull: true},
+		}},
+	}
+)
+
+// field returns the field called name of t including the implicit
+// introspection fields or nil if there's no such field.
+func (s *Schema) field(t *schemaType, name []byte) *schemaField {
+	switch string(name) {
+	case "__typename":
+		return fieldTypename
+	case "__schema":
+		if t == s.roots[gqlscan.TokenDefQry] {
+			return fieldSchema
+		}
+	case "__type":
+		if t == s.roots[gqlscan.TokenDefQry] {
+			return fieldType
+		}
+	}
+	if t.kind == kindUnion {
+		return nil
+	}
+	return t.fields[string(name)]
+}
+
+// namedType returns the named type of t or nil if it's undefined.
+func (s *Schema) namedType(t *ast.TypeRef) *schemaType {
+	for t.Elem != nil {
+		t = t.Elem
+	}
+	return s.types[string(t.Name)]
+}
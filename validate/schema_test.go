@@ -0,0 +1,238 @@
+package validate_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/validate"
+
+	"github.com/stretchr/testify/require"
+)
+
+func loadSchema(t *testing.T) *validate.Schema {
+	src, err := os.ReadFile("testdata/schema.json")
+	require.NoError(t, err)
+	s, err := validate.LoadSchema(src)
+	require.NoError(t, err)
+	return s
+}
+
+func TestLoadSchema(t *testing.T) {
+	for _, input := range []string{
+		`{"data": {"__schema": {"queryType": {"name": "Q"},
+		"types": [{"kind": "OBJECT", "name": "Q", "fields": [
+			{"name": "a", "args": [], "type": {"kind": "SCALAR", "name": "Int"}}
+		]}], "directives": []}}}`,
+		`{"__schema": {"queryType": {"name": "Q"},
+		"types": [{"kind": "OBJECT", "name": "Q", "fields": [
+			{"name": "a", "args": [], "type": {"kind": "SCALAR", "name": "Int"}}
+		]}], "directives": []}}`,
+	} {
+		s, err := validate.LoadSchema([]byte(input))
+		require.NoError(t, err)
+		v, err := s.Validate([]byte(`{a}`))
+		require.NoError(t, err)
+		require.Nil(t, v)
+	}
+}
+
+func TestLoadSchemaErr(t *testing.T) {
+	for _, td := range []struct {
+		decl  string
+		input string
+		msg   string
+	}{
+		{
+			decl(1),
+			`{"data": {}}`,
+			"missing __schema",
+		},
+		{
+			decl(1),
+			`{"__schema": {"types": [{"kind": "THING", "name": "T"}]}}`,
+			`type "T": unknown kind "THING"`,
+		},
+		{
+			decl(1),
+			`{"__schema": {"queryType": {"name": "Q"}, "types": []}}`,
+			`undefined root type "Q"`,
+		},
+		{
+			decl(1),
+			`{"__schema": {"types": [{"kind": "OBJECT", "name": "Q",
+			"fields": [{"name": "a", "type": {"kind": "LIST"}}]}]}}`,
+			"field Q.a: LIST type without ofType",
+		},
+		{
+			decl(1),
+			`{"__schema": {"directives": [{"name": "d", "args": [
+				{"name": "a", "type": {"kind": "SCALAR"}}
+			]}]}}`,
+			"directive @d: a: SCALAR type without name",
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			_, err := validate.LoadSchema([]byte(td.input))
+			require.Error(t, err)
+			require.Equal(t, td.msg, err.Error())
+		})
+	}
+	_, err := validate.LoadSchema([]byte(`{}`))
+	require.ErrorIs(t, err, validate.ErrNoSchema)
+
+	_, err = validate.LoadSchema([]byte(`{"__schema": []}`))
+	require.Error(t, err)
+	require.True(t, strings.HasPrefix(
+		err.Error(), "decoding introspection result: ",
+	), err.Error())
+}
+
+func TestSchemaValidate(t *testing.T) {
+	s := loadSchema(t)
+	for _, td := range []struct {
+		decl   string
+		input  string
+		expect []validate.Violation
+	}{
+		{
+			decl(1),
+			`query Q($id: ID!, $f: UserFilter, $skip: Boolean = false)
+			@auth(role: ADMIN) {
+				user(id: $id) { __typename id name friends(first: 2) { ...U } }
+				users(filter: $f) @skip(if: $skip) { ... on Node { id } ...N }
+				search(q: "x") { ... on User { name } ... on Post { title } ...R }
+				__schema { types { name } } __type(name: "User") { kind }
+			}
+			fragment U on User { id role }
+			fragment N on Node { id ... on Post { title } }
+			fragment R on SearchResult { __typename }
+			mutation M($n: String = "a") { setName(id: 1, name: $n) { id } }`,
+			nil,
+		},
+		{
+			decl(1),
+			`{ user(id: 1) { nick id { x } friends } x: users(foo: 1) { id } node { id } }`,
+			[]validate.Violation{
+				{validate.RuleFieldDefined, 16,
+					`field "nick" is not defined on type "User"`},
+				{validate.RuleLeafSel, 21,
+					`field "id" of type "ID!" must not have a selection set`},
+				{validate.RuleLeafSel, 30,
+					`field "friends" of type "[User!]!" must have a selection set`},
+				{validate.RuleArgDefined, 49,
+					`unknown argument "foo" on field "Query.users"`},
+				{validate.RuleArgRequired, 64,
+					`argument "id" of type "ID!" is required on field "Query.node"`},
+			},
+		},
+		{
+			decl(1),
+			`subscription S { s } mutation M { setName(name: "x") { id } }`,
+			[]validate.Violation{
+				{validate.RuleOprTypeDefined, 0,
+					`schema doesn't support subscription operations`},
+				{validate.RuleArgRequired, 34,
+					`argument "id" of type "ID!" is required on field "Mutation.setName"`},
+			},
+		},
+		{
+			decl(1),
+			`{ user(id: 1) { ...F ...G ... on Int { id } ... on X { id } ... on Post { id } ...P } }
+			fragment F on Role { x } fragment G on Unknown { x } fragment P on Post { id }`,
+			[]validate.Violation{
+				{validate.RuleFragOnComposite, 33,
+					`fragment cannot condition on non composite type "Int"`},
+				{validate.RuleFragTypeDefined, 51,
+					`unknown type "X"`},
+				{validate.RuleFragSpreadPossible, 67,
+					`fragment cannot be spread here as objects of type "User" ` +
+						`can never be of type "Post"`},
+				{validate.RuleFragSpreadPossible, 82,
+					`fragment "P" cannot be spread here as objects of type "User" ` +
+						`can never be of type "Post"`},
+				{validate.RuleFragOnComposite, 105,
+					`fragment cannot condition on non composite type "Role"`},
+				{validate.RuleFragTypeDefined, 130,
+					`unknown type "Unknown"`},
+			},
+		},
+		{
+			decl(1),
+			`query @skip(if: true) { user(id: 1) @auth(role: ADMIN) @skip(if: true, x: 1) @foo { id } }`,
+			[]validate.Violation{
+				{validate.RuleDirLocation, 6,
+					`directive "@skip" may not be used on QUERY`},
+				{validate.RuleArgDefined, 71,
+					`unknown argument "x" on directive "@skip"`},
+				{validate.RuleDirDefined, 77,
+					`unknown directive "@foo"`},
+			},
+		},
+		{
+			decl(1),
+			`query($i: Int, $s: String!, $ids: [ID], $n: ID!, $r: Role = ADMIN, $nul: ID = null) {
+				a: user(id: $i) { id }
+				b: user(id: $n) { id }
+				c: user(id: $nul) { id }
+				users(first: $i, filter: {name: $s, ids: $ids, sub: {role: $r, name: $i}, limit: $i}) { id }
+				d: users(filter: {name: $n}) @include(if: $nul) { id }
+				...F
+			}
+			fragment F on Query { e: user(id: $s) { id } }`,
+			[]validate.Violation{
+				{validate.RuleVarUsageAllowed, 102,
+					`variable "$i" of type "Int" used in position expecting type "ID!"`},
+				{validate.RuleVarUsageAllowed, 156,
+					`variable "$nul" of type "ID" used in position expecting type "ID!"`},
+				{validate.RuleVarUsageAllowed, 214,
+					`variable "$ids" of type "[ID]" used in position expecting type "[ID!]"`},
+				{validate.RuleVarUsageAllowed, 242,
+					`variable "$i" of type "Int" used in position expecting type "String!"`},
+				{validate.RuleVarUsageAllowed, 294,
+					`variable "$n" of type "ID!" used in position expecting type "String!"`},
+				{validate.RuleVarUsageAllowed, 312,
+					`variable "$nul" of type "ID" used in position expecting type "Boolean!"`},
+				{validate.RuleVarUsageAllowed, 376,
+					`variable "$s" of type "String!" used in position expecting type "ID!"`},
+			},
+		},
+		{
+			// Single items in list positions
+			decl(1),
+			`query($i: Int, $s: String!) {
+				a: users(filter: {name: $s, limit: 1, or: {name: $i, limit: 1}}) { id }
+				b: users(filter: {name: $s, or: [{name: $s}, {name: $i}]}) { id }
+			}`,
+			[]validate.Violation{
+				{validate.RuleVarUsageAllowed, 83,
+					`variable "$i" of type "Int" used in position expecting type "String!"`},
+				{validate.RuleVarUsageAllowed, 162,
+					`variable "$i" of type "Int" used in position expecting type "String!"`},
+			},
+		},
+		{
+			// Schema-less rules are checked too
+			decl(1),
+			`query($v: Int) { user(id: 1) { id } }`,
+			[]validate.Violation{
+				{validate.RuleVarUsed, 6,
+					`variable "$v" is never used in anonymous operation`},
+			},
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			v, err := s.Validate([]byte(td.input))
+			require.NoError(t, err)
+			require.Equal(t, td.expect, v)
+		})
+	}
+
+	t.Run("scan error", func(t *testing.T) {
+		_, err := s.Validate([]byte(`{user(`))
+		var e gqlscan.Error
+		require.ErrorAs(t, err, &e)
+		require.Equal(t, gqlscan.ErrUnexpEOF, e.Code)
+	})
+}
@@ -0,0 +1,393 @@
+package validate
+
+import (
+	"fmt"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/internal/ast"
+)
+
+// Validate returns all violations of the validation rules checked by
+// the package-level Validate function and of the rules that require
+// a schema sorted by index:
+//
+//   - operation type defined
+//   - field selections
+//   - leaf field selections
+//   - argument names
+//   - required arguments
+//   - fragment spread type existence
+//   - fragments on composite types
+//   - fragment spread is possible
+//   - directives are defined
+//   - directives are in valid locations
+//   - all variable usages are allowed
+//
+// Selections on undefined fields and types aren't validated any further.
+//
+// Returns a gqlscan.Error if src isn't lexically valid.
+func (s *Schema) Validate(src []byte) ([]Violation, error) {
+	return validate(src, s)
+}
+
+// varUsage is a variable reference and its expected type.
+type varUsage struct {
+	ref *ast.Value
+	typ *ast.TypeRef
+
+	// hasDefault is true if the argument or input field
+	// has a default value.
+	hasDefault bool
+}
+
+// schemaValidator validates a document against a schema.
+type schemaValidator struct {
+	*validator
+	schema *Schema
+
+	// usages maps definitions to the variable usages
+	// in their arguments.
+	usages map[*ast.Definition][]varUsage
+	def    *ast.Definition
+}
+
+func (v *validator) validateSchema(s *Schema) {
+	sv := &schemaValidator{
+		validator: v,
+		schema:    s,
+		usages:    map[*ast.Definition][]varUsage{},
+	}
+	for _, def := range v.doc.Defs {
+		sv.def = def
+		sv.definition(def)
+	}
+	for _, def := range v.doc.Defs {
+		if def.Token != gqlscan.TokenDefFrag {
+			sv.varUsages(def)
+		}
+	}
+}
+
+var dirLocations = map[gqlscan.Token]string{
+	gqlscan.TokenDefQry:      "QUERY",
+	gqlscan.TokenDefMut:      "MUTATION",
+	gqlscan.TokenDefSub:      "SUBSCRIPTION",
+	gqlscan.TokenDefFrag:     "FRAGMENT_DEFINITION",
+	gqlscan.TokenField:       "FIELD",
+	gqlscan.TokenFragInline:  "INLINE_FRAGMENT",
+	gqlscan.TokenNamedSpread: "FRAGMENT_SPREAD",
+}
+
+func (v *schemaValidator) definition(def *ast.Definition) {
+	v.dirs(def.Dirs, dirLocations[def.Token])
+	for _, vd := range def.Vars {
+		v.dirs(vd.Dirs, "VARIABLE_DEFINITION")
+	}
+
+	var t *schemaType
+	if def.Token == gqlscan.TokenDefFrag {
+		t = v.typeCond(def.TypeCond)
+	} else if t = v.schema.roots[def.Token]; t == nil {
+		v.report(
+			RuleOprTypeDefined, def.Index,
+			"schema doesn't support %s operations",
+			oprKinds[def.Token],
+		)
+	}
+	if t != nil {
+		v.sels(def.Sels, t)
+	}
+}
+
+var oprKinds = map[gqlscan.Token]string{
+	gqlscan.TokenDefQry: "query",
+	gqlscan.TokenDefMut: "mutation",
+	gqlscan.TokenDefSub: "subscription",
+}
+
+// typeCond returns the composite type called cond
+// or nil if the type is undefined or not composite.
+func (v *schemaValidator) typeCond(cond ast.Name) *schemaType {
+	t := v.schema.types[string(cond.Val)]
+	if t == nil {
+		v.report(
+			RuleFragTypeDefined, cond.Index,
+			"unknown type %q", cond.Val,
+		)
+		return nil
+	}
+	if !t.isComposite() {
+		v.report(
+			RuleFragOnComposite, cond.Index,
+			"fragment cannot condition on non composite type %q", cond.Val,
+		)
+		return nil
+	}
+	return t
+}
+
+func (v *schemaValidator) sels(sels []*ast.Selection, parent *schemaType) {
+	for _, s := range sels {
+		v.dirs(s.Dirs, dirLocations[s.Token])
+		switch s.Token {
+		case gqlscan.TokenField:
+			v.field(s, parent)
+		case gqlscan.TokenFragInline:
+			t := parent
+			if s.Name.Val != nil {
+				if t = v.typeCond(s.Name); t == nil {
+					continue
+				}
+				v.spreadPossible(s.Name.Index, "fragment", parent, t)
+			}
+			v.sels(s.Sels, t)
+		case gqlscan.TokenNamedSpread:
+			f := v.frags[string(s.Name.Val)]
+			if f == nil {
+				continue
+			}
+			t := v.schema.types[string(f.TypeCond.Val)]
+			if t == nil || !t.isComposite() {
+				// Reported for the fragment definition
+				continue
+			}
+			v.spreadPossible(
+				s.Name.Index, fmt.Sprintf("fragment %q", s.Name.Val), parent, t,
+			)
+		}
+	}
+}
+
+func (v *schemaValidator) field(s *ast.Selection, parent *schemaType) {
+	f := v.schema.field(parent, s.Name.Val)
+	if f == nil {
+		v.report(
+			RuleFieldDefined, s.Name.Index,
+			"field %q is not defined on type %q", s.Name.Val, parent.name,
+		)
+		return
+	}
+	v.args(
+		s.Args, f.args,
+		fmt.Sprintf("field \"%s.%s\"", parent.name, f.name), s.Index(),
+	)
+
+	t := v.schema.namedType(f.typ)
+	switch {
+	case t == nil:
+	case t.isLeaf() && len(s.Sels) > 0:
+		v.report(
+			RuleLeafSel, s.Name.Index,
+			"field %q of type %q must not have a selection set",
+			s.Name.Val, f.typ,
+		)
+	case !t.isLeaf() && len(s.Sels) == 0:
+		v.report(
+			RuleLeafSel, s.Name.Index,
+			"field %q of type %q must have a selection set",
+			s.Name.Val, f.typ,
+		)
+	case t.isComposite():
+		v.sels(s.Sels, t)
+	}
+}
+
+// spreadPossible reports a fragment of type t that
+// can never apply within the parent type.
+func (v *schemaValidator) spreadPossible(
+	index int, frag string, parent, t *schemaType,
+) {
+	pt := parent.possibleTypes()
+	for n := range t.possibleTypes() {
+		if pt[n] {
+			return
+		}
+	}
+	v.report(
+		RuleFragSpreadPossible, index,
+		"%s cannot be spread here as objects of type %q "+
+			"can never be of type %q", frag, parent.name, t.name,
+	)
+}
+
+func (v *schemaValidator) dirs(dirs []*ast.Directive, location string) {
+	for _, d := range dirs {
+		sd := v.schema.dirs[string(d.Name.Val)]
+		if sd == nil {
+			v.report(
+				RuleDirDefined, d.Name.Index,
+				"unknown directive \"@%s\"", d.Name.Val,
+			)
+			continue
+		}
+		if !sd.locations[location] {
+			v.report(
+				RuleDirLocation, d.Name.Index,
+				"directive \"@%s\" may not be used on %s", d.Name.Val, location,
+			)
+		}
+		v.args(
+			d.Args, sd.args,
+			fmt.Sprintf("directive \"@%s\"", d.Name.Val), d.Name.Index,
+		)
+	}
+}
+
+// args checks the arguments args of owner against their definitions.
+func (v *schemaValidator) args(
+	args []*ast.Argument, defs []*schemaField, owner string, index int,
+) {
+	for _, a := range args {
+		d := findField(defs, a.Name.Val)
+		if d == nil {
+			v.report(
+				RuleArgDefined, a.Name.Index,
+				"unknown argument %q on %s", a.Name.Val, owner,
+			)
+			continue
+		}
+		v.value(a.Val, d.typ, d.hasDefault)
+	}
+	for _, d := range defs {
+		if !d.typ.NonNull || d.hasDefault {
+			continue
+		}
+		if !hasArg(args, d.name) {
+			v.report(
+				RuleArgRequired, index,
+				"argument %q of type %q is required on %s",
+				d.name, d.typ, owner,
+			)
+		}
+	}
+}
+
+// value records the variable usages in val of type t.
+func (v *schemaValidator) value(val *ast.Value, t *ast.TypeRef, hasDefault bool) {
+	switch val.Token {
+	case gqlscan.TokenVarRef:
+		v.usages[v.def] = append(v.usages[v.def], varUsage{
+			ref:        val,
+			typ:        t,
+			hasDefault: hasDefault,
+		})
+	case gqlscan.TokenArr:
+		if t.Elem == nil {
+			return
+		}
+		for _, item := range val.Items {
+			v.value(item, t.Elem, false)
+		}
+	case gqlscan.TokenObj:
+		// Input coercion accepts a single item in list positions
+		for t.Elem != nil {
+			t = t.Elem
+		}
+		it := v.schema.types[string(t.Name)]
+		if it == nil || it.kind != kindInputObject {
+			return
+		}
+		for _, f := range val.Fields {
+			if d := it.fields[string(f.Name.Val)]; d != nil {
+				v.value(f.Val, d.typ, d.hasDefault)
+			}
+		}
+	}
+}
+
+// varUsages reports the variable usages in opr and the fragments
+// reachable from opr that aren't allowed.
+func (v *schemaValidator) varUsages(opr *ast.Definition) {
+	vars := map[string]*ast.VarDef{}
+	for _, vd := range opr.Vars {
+		if vars[string(vd.Name.Val)] == nil {
+			vars[string(vd.Name.Val)] = vd
+		}
+	}
+	visited := map[*ast.Definition]bool{}
+	var visit func(def *ast.Definition)
+	var spreads func(sels []*ast.Selection)
+	visit = func(def *ast.Definition) {
+		visited[def] = true
+		for _, u := range v.usages[def] {
+			vd := vars[string(u.ref.Raw)]
+			if vd == nil || vd.Type == nil || usageAllowed(vd, u) {
+				continue
+			}
+			v.report(
+				RuleVarUsageAllowed, u.ref.Index,
+				"variable \"$%s\" of type %q used in position "+
+					"expecting type %q", u.ref.Raw, vd.Type, u.typ,
+			)
+		}
+		spreads(def.Sels)
+	}
+	spreads = func(sels []*ast.Selection) {
+		for _, s := range sels {
+			spreads(s.Sels)
+			if s.Token != gqlscan.TokenNamedSpread {
+				continue
+			}
+			if f := v.frags[string(s.Name.Val)]; f != nil && !visited[f] {
+				visit(f)
+			}
+		}
+	}
+	visit(opr)
+}
+
+// usageAllowed returns true if the variable vd
+// is allowed to be used in the position of u.
+func usageAllowed(vd *ast.VarDef, u varUsage) bool {
+	varType, locType := vd.Type, u.typ
+	if locType.NonNull && !varType.NonNull {
+		hasNonNullDefault := vd.Def != nil &&
+			vd.Def.Token != gqlscan.TokenNull
+		if !hasNonNullDefault && !u.hasDefault {
+			return false
+		}
+		locType = nullable(locType)
+	}
+	return typesCompatible(varType, locType)
+}
+
+func typesCompatible(varType, locType *ast.TypeRef) bool {
+	switch {
+	case locType.NonNull:
+		return varType.NonNull &&
+			typesCompatible(nullable(varType), nullable(locType))
+	case varType.NonNull:
+		return typesCompatible(nullable(varType), locType)
+	case locType.Elem != nil:
+		return varType.Elem != nil &&
+			typesCompatible(varType.Elem, locType.Elem)
+	case varType.Elem != nil:
+		return false
+	}
+	return string(varType.Name) == string(locType.Name)
+}
+
+// nullable returns the nullable variant of t.
+func nullable(t *ast.TypeRef) *ast.TypeRef {
+	c := *t
+	c.NonNull = false
+	return &c
+}
+
+func findField(fields []*schemaField, name []byte) *schemaField {
+	for _, f := range fields {
+		if f.name == string(name) {
+			return f
+		}
+	}
+	return nil
+}
+
+func hasArg(args []*ast.Argument, name string) bool {
+	for _, a := range args {
+		if string(a.Name.Val) == name {
+			return true
+		}
+	}
+	return false
+}
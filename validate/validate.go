@@ -0,0 +1,461 @@
+package validate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/internal/ast"
+)
+
+// Rule is a validation rule.
+type Rule int
+
+const (
+	_ Rule = iota
+	RuleOprNameUnique
+	RuleLoneAnonOpr
+	RuleSubSingleRoot
+	RuleFragNameUnique
+	RuleFragSpreadKnown
+	RuleFragUnused
+	RuleFragCycle
+	RuleArgNameUnique
+	RuleVarNameUnique
+	RuleObjFieldNameUnique
+	RuleVarDefined
+	RuleVarUsed
+
+	// Rules that require a schema.
+	RuleOprTypeDefined
+	RuleFieldDefined
+	RuleLeafSel
+	RuleArgDefined
+	RuleArgRequired
+	RuleFragTypeDefined
+	RuleFragOnComposite
+	RuleFragSpreadPossible
+	RuleDirDefined
+	RuleDirLocation
+	RuleVarUsageAllowed
+)
+
+func (r Rule) String() string {
+	switch r {
+	case RuleOprNameUnique:
+		return "operation name uniqueness"
+	case RuleLoneAnonOpr:
+		return "lone anonymous operation"
+	case RuleSubSingleRoot:
+		return "subscription single root field"
+	case RuleFragNameUnique:
+		return "fragment name uniqueness"
+	case RuleFragSpreadKnown:
+		return "fragment spread target defined"
+	case RuleFragUnused:
+		return "fragments must be used"
+	case RuleFragCycle:
+		return "fragment spreads must not form cycles"
+	case RuleArgNameUnique:
+		return "argument uniqueness"
+	case RuleVarNameUnique:
+		return "variable uniqueness"
+	case RuleObjFieldNameUnique:
+		return "input object field uniqueness"
+	case RuleVarDefined:
+		return "all variable uses defined"
+	case RuleVarUsed:
+		return "all variables used"
+	case RuleOprTypeDefined:
+		return "operation type defined"
+	case RuleFieldDefined:
+		return "field selections"
+	case RuleLeafSel:
+		return "leaf field selections"
+	case RuleArgDefined:
+		return "argument names"
+	case RuleArgRequired:
+		return "required arguments"
+	case RuleFragTypeDefined:
+		return "fragment spread type existence"
+	case RuleFragOnComposite:
+		return "fragments on composite types"
+	case RuleFragSpreadPossible:
+		return "fragment spread is possible"
+	case RuleDirDefined:
+		return "directives are defined"
+	case RuleDirLocation:
+		return "directives are in valid locations"
+	case RuleVarUsageAllowed:
+		return "all variable usages are allowed"
+	}
+	return ""
+}
+
+// Violation is a violation of a validation rule.
+type Violation struct {
+	Rule Rule
+
+	// Index is the index of the offending token in the source document.
+	Index int
+
+	// Msg describes the violation.
+	Msg string
+}
+
+func (v Violation) Error() string {
+	return fmt.Sprintf("violation at index %d: %s", v.Index, v.Msg)
+}
+
+// Validate returns all violations of the validation rules
+// that don't require a schema sorted by index:
+//
+//   - operation name uniqueness
+//   - lone anonymous operation
+//   - subscription single root field
+//   - fragment name uniqueness
+//   - fragment spread target defined
+//   - fragments must be used
+//   - fragment spreads must not form cycles
+//   - argument uniqueness
+//   - variable uniqueness
+//   - input object field uniqueness
+//   - all variable uses defined
+//   - all variables used
+//
+// The subscription single root field rule only considers
+// the fields selected directly in the root selection set of the
+// subscription, fields selected through fragments are ignored.
+//
+// Returns a gqlscan.Error if src isn't lexically valid.
+func Validate(src []byte) ([]Violation, error) {
+	return validate(src, nil)
+}
+
+// validate returns all violations of the rules that don't require
+// a schema and of the schema rules if s isn't nil.
+func validate(src []byte, s *Schema) ([]Violation, error) {
+	d, err := ast.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	v := &validator{doc: d, frags: map[string]*ast.Definition{}}
+	v.validate()
+	if s != nil {
+		v.validateSchema(s)
+	}
+	sort.SliceStable(v.violations, func(i, j int) bool {
+		return v.violations[i].Index < v.violations[j].Index
+	})
+	return v.violations, nil
+}
+
+type validator struct {
+	doc *ast.Document
+
+	// frags maps fragment names to the first fragment definition
+	// of that name.
+	frags map[string]*ast.Definition
+
+	violations []Violation
+}
+
+func (v *validator) report(r Rule, index int, format string, a ...interface{}) {
+	v.violations = append(v.violations, Violation{
+		Rule:  r,
+		Index: index,
+		Msg:   fmt.Sprintf(format, a...),
+	})
+}
+
+func (v *validator) validate() {
+	var oprs int
+	oprNames := map[string]bool{}
+	for _, def := range v.doc.Defs {
+		if def.Token == gqlscan.TokenDefFrag {
+			if _, ok := v.frags[string(def.Name.Val)]; ok {
+				v.report(
+					RuleFragNameUnique, def.Name.Index,
+					"there can be only one fragment named %q", def.Name.Val,
+				)
+				continue
+			}
+			v.frags[string(def.Name.Val)] = def
+			continue
+		}
+		oprs++
+		if def.Name.Val == nil {
+			continue
+		}
+		if oprNames[string(def.Name.Val)] {
+			v.report(
+				RuleOprNameUnique, def.Name.Index,
+				"there can be only one operation named %q", def.Name.Val,
+			)
+		}
+		oprNames[string(def.Name.Val)] = true
+	}
+
+	used := map[*ast.Definition]bool{}
+	for _, def := range v.doc.Defs {
+		v.uniqueArgs(def.Dirs)
+		for _, vd := range def.Vars {
+			v.uniqueArgs(vd.Dirs)
+			v.uniqueObjFields(vd.Def)
+		}
+		v.uniqueSels(def.Sels)
+
+		if def.Token == gqlscan.TokenDefFrag {
+			continue
+		}
+		if def.Name.Val == nil && oprs > 1 {
+			v.report(
+				RuleLoneAnonOpr, def.Index,
+				"anonymous operation must be the only defined operation",
+			)
+		}
+		if def.Token == gqlscan.TokenDefSub {
+			v.subSingleRoot(def)
+		}
+		v.varUsage(def, used)
+	}
+
+	v.fragCycles()
+	for _, def := range v.doc.Defs {
+		if def.Token == gqlscan.TokenDefFrag && !used[def] &&
+			v.frags[string(def.Name.Val)] == def {
+			v.report(
+				RuleFragUnused, def.Name.Index,
+				"fragment %q is never used", def.Name.Val,
+			)
+		}
+	}
+}
+
+// uniqueSels checks argument and input object field uniqueness
+// in sels and reports spreads of undefined fragments.
+func (v *validator) uniqueSels(sels []*ast.Selection) {
+	for _, s := range sels {
+		if s.Token == gqlscan.TokenNamedSpread {
+			if _, ok := v.frags[string(s.Name.Val)]; !ok {
+				v.report(
+					RuleFragSpreadKnown, s.Name.Index,
+					"unknown fragment %q", s.Name.Val,
+				)
+			}
+		}
+		v.uniqueArgs(s.Dirs)
+		v.uniqueArgList(s.Args)
+		v.uniqueSels(s.Sels)
+	}
+}
+
+func (v *validator) uniqueArgs(dirs []*ast.Directive) {
+	for _, d := range dirs {
+		v.uniqueArgList(d.Args)
+	}
+}
+
+func (v *validator) uniqueArgList(args []*ast.Argument) {
+	for n, a := range args {
+		for _, p := range args[:n] {
+			if string(p.Name.Val) == string(a.Name.Val) {
+				v.report(
+					RuleArgNameUnique, a.Name.Index,
+					"there can be only one argument named %q", a.Name.Val,
+				)
+				break
+			}
+		}
+		v.uniqueObjFields(a.Val)
+	}
+}
+
+func (v *validator) uniqueObjFields(val *ast.Value) {
+	if val == nil {
+		return
+	}
+	for _, item := range val.Items {
+		v.uniqueObjFields(item)
+	}
+	for n, f := range val.Fields {
+		for _, p := range val.Fields[:n] {
+			if string(p.Name.Val) == string(f.Name.Val) {
+				v.report(
+					RuleObjFieldNameUnique, f.Name.Index,
+					"there can be only one input field named %q", f.Name.Val,
+				)
+				break
+			}
+		}
+		v.uniqueObjFields(f.Val)
+	}
+}
+
+func (v *validator) subSingleRoot(def *ast.Definition) {
+	var first []byte
+	for _, s := range def.Sels {
+		if s.Token != gqlscan.TokenField {
+			continue
+		}
+		if first == nil {
+			first = s.ResponseName()
+			continue
+		}
+		if string(s.ResponseName()) != string(first) {
+			v.report(
+				RuleSubSingleRoot, s.Index(),
+				"%s must select only one top level field",
+				describe(def, "subscription"),
+			)
+			return
+		}
+	}
+}
+
+// varUsage checks variable uniqueness and usage in opr and marks
+// all fragments reachable from opr in used.
+func (v *validator) varUsage(opr *ast.Definition, used map[*ast.Definition]bool) {
+	defined := map[string]bool{}
+	for _, vd := range opr.Vars {
+		if defined[string(vd.Name.Val)] {
+			v.report(
+				RuleVarNameUnique, vd.Name.Index,
+				"there can be only one variable named \"$%s\"", vd.Name.Val,
+			)
+		}
+		defined[string(vd.Name.Val)] = true
+	}
+
+	refs := map[string]bool{}
+	visited := map[*ast.Definition]bool{}
+	var walkVal func(val *ast.Value)
+	walkVal = func(val *ast.Value) {
+		if val == nil {
+			return
+		}
+		if val.Token == gqlscan.TokenVarRef {
+			refs[string(val.Raw)] = true
+			if !defined[string(val.Raw)] {
+				v.report(
+					RuleVarDefined, val.Index,
+					"variable \"$%s\" is not defined by %s",
+					val.Raw, describe(opr, "operation"),
+				)
+			}
+		}
+		for _, item := range val.Items {
+			walkVal(item)
+		}
+		for _, f := range val.Fields {
+			walkVal(f.Val)
+		}
+	}
+	walkDirs := func(dirs []*ast.Directive) {
+		for _, d := range dirs {
+			for _, a := range d.Args {
+				walkVal(a.Val)
+			}
+		}
+	}
+	var walkSels func(sels []*ast.Selection)
+	walkSels = func(sels []*ast.Selection) {
+		for _, s := range sels {
+			for _, a := range s.Args {
+				walkVal(a.Val)
+			}
+			walkDirs(s.Dirs)
+			walkSels(s.Sels)
+			if s.Token != gqlscan.TokenNamedSpread {
+				continue
+			}
+			f := v.frags[string(s.Name.Val)]
+			if f == nil || visited[f] {
+				continue
+			}
+			visited[f], used[f] = true, true
+			walkDirs(f.Dirs)
+			walkSels(f.Sels)
+		}
+	}
+	for _, vd := range opr.Vars {
+		walkDirs(vd.Dirs)
+	}
+	walkDirs(opr.Dirs)
+	walkSels(opr.Sels)
+
+	for _, vd := range opr.Vars {
+		if !refs[string(vd.Name.Val)] {
+			v.report(
+				RuleVarUsed, vd.Name.Index,
+				"variable \"$%s\" is never used in %s",
+				vd.Name.Val, describe(opr, "operation"),
+			)
+		}
+	}
+}
+
+// fragCycles reports every cycle of fragment spreads once
+// at the spread that closes the cycle.
+func (v *validator) fragCycles() {
+	visited := map[*ast.Definition]bool{}
+
+	// stack holds the fragments of the current path.
+	var stack []*ast.Definition
+
+	var visit func(f *ast.Definition)
+	var spreads func(sels []*ast.Selection)
+	spreads = func(sels []*ast.Selection) {
+		for _, s := range sels {
+			spreads(s.Sels)
+			if s.Token != gqlscan.TokenNamedSpread {
+				continue
+			}
+			f := v.frags[string(s.Name.Val)]
+			if f == nil {
+				continue
+			}
+			for n := range stack {
+				if stack[n] != f {
+					continue
+				}
+				var b strings.Builder
+				for _, p := range stack[n:] {
+					b.Write(p.Name.Val)
+					b.WriteString(" -> ")
+				}
+				b.Write(s.Name.Val)
+				v.report(
+					RuleFragCycle, s.Name.Index,
+					"fragment cycle: %s", b.String(),
+				)
+				break
+			}
+			if !visited[f] {
+				visit(f)
+			}
+		}
+	}
+	visit = func(f *ast.Definition) {
+		visited[f] = true
+		stack = append(stack, f)
+		spreads(f.Sels)
+		stack = stack[:len(stack)-1]
+	}
+	for _, def := range v.doc.Defs {
+		if def.Token == gqlscan.TokenDefFrag &&
+			v.frags[string(def.Name.Val)] == def && !visited[def] {
+			visit(def)
+		}
+	}
+}
+
+// describe returns "<kind> "name"" for named operations
+// and "anonymous <kind>" for anonymous operations.
+func describe(opr *ast.Definition, kind string) string {
+	if opr.Name.Val == nil {
+		return "anonymous " + kind
+	}
+	return fmt.Sprintf("%s %q", kind, opr.Name.Val)
+}
@@ -0,0 +1,226 @@
+package validate_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/validate"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	for _, td := range []struct {
+		decl   string
+		input  string
+		expect []validate.Violation
+	}{
+		{
+			decl(1),
+			`{a}`,
+			nil,
+		},
+		{
+			decl(1),
+			`query Q($a: Int, $b: [String!]! = ["x"] @d(a: {x: 1, y: 2}))
+			@d(a: $a, b: $b) {
+				f(a: 1, b: [{x: 1, y: 2}, {x: $a}]) @d(a: 1) { ...F ...on T { g } }
+			}
+			fragment F on T { h { ...G } }
+			fragment G on T { i }
+			subscription S { s { a b } s ... { t } }`,
+			nil,
+		},
+		{
+			decl(1),
+			`query Q {a} mutation Q {b} subscription Q {c}`,
+			[]validate.Violation{
+				{validate.RuleOprNameUnique, 21,
+					`there can be only one operation named "Q"`},
+				{validate.RuleOprNameUnique, 40,
+					`there can be only one operation named "Q"`},
+			},
+		},
+		{
+			decl(1),
+			`{a} query Q {b} {c}`,
+			[]validate.Violation{
+				{validate.RuleLoneAnonOpr, 0,
+					`anonymous operation must be the only defined operation`},
+				{validate.RuleLoneAnonOpr, 16,
+					`anonymous operation must be the only defined operation`},
+			},
+		},
+		{
+			decl(1),
+			`subscription S {a b: a c ...F} fragment F on S {d e}`,
+			[]validate.Violation{
+				{validate.RuleSubSingleRoot, 18,
+					`subscription "S" must select only one top level field`},
+			},
+		},
+		{
+			decl(1),
+			`subscription {a a @d ...on S {b}}`,
+			nil,
+		},
+		{
+			decl(1),
+			`subscription {a b}`,
+			[]validate.Violation{
+				{validate.RuleSubSingleRoot, 16,
+					`anonymous subscription must select only one top level field`},
+			},
+		},
+		{
+			decl(1),
+			`{...F} fragment F on T {a} fragment F on U {b}`,
+			[]validate.Violation{
+				{validate.RuleFragNameUnique, 36,
+					`there can be only one fragment named "F"`},
+			},
+		},
+		{
+			decl(1),
+			`{a {...F ...G} ...F} fragment F on T {...H}`,
+			[]validate.Violation{
+				{validate.RuleFragSpreadKnown, 12,
+					`unknown fragment "G"`},
+				{validate.RuleFragSpreadKnown, 41,
+					`unknown fragment "H"`},
+			},
+		},
+		{
+			decl(1),
+			`{a} fragment F on T {...G} fragment G on T {a}
+			fragment H on T {a} query {...I} fragment I on T {a}`,
+			[]validate.Violation{
+				{validate.RuleLoneAnonOpr, 0,
+					`anonymous operation must be the only defined operation`},
+				{validate.RuleFragUnused, 13,
+					`fragment "F" is never used`},
+				{validate.RuleFragUnused, 36,
+					`fragment "G" is never used`},
+				{validate.RuleFragUnused, 59,
+					`fragment "H" is never used`},
+				{validate.RuleLoneAnonOpr, 70,
+					`anonymous operation must be the only defined operation`},
+			},
+		},
+		{
+			decl(1),
+			`{...F ...X} fragment F on T {a {...G}} fragment G on T {...F ...G}
+			fragment X on T {...Y} fragment Y on T {...Z} fragment Z on T {...X}`,
+			[]validate.Violation{
+				{validate.RuleFragCycle, 59,
+					`fragment cycle: F -> G -> F`},
+				{validate.RuleFragCycle, 64,
+					`fragment cycle: G -> G`},
+				{validate.RuleFragCycle, 136,
+					`fragment cycle: X -> Y -> Z -> X`},
+			},
+		},
+		{
+			decl(1),
+			`query($v: Int @d(a: 1, a: 2)) @d(b: 1, b: 2) {
+				f(a: 1, b: 2, a: 3) @d(c: 1, c: 2) {g(x: $v, x: $v)}
+			}`,
+			[]validate.Violation{
+				{validate.RuleArgNameUnique, 23,
+					`there can be only one argument named "a"`},
+				{validate.RuleArgNameUnique, 39,
+					`there can be only one argument named "b"`},
+				{validate.RuleArgNameUnique, 65,
+					`there can be only one argument named "a"`},
+				{validate.RuleArgNameUnique, 80,
+					`there can be only one argument named "c"`},
+				{validate.RuleArgNameUnique, 96,
+					`there can be only one argument named "x"`},
+			},
+		},
+		{
+			decl(1),
+			`query Q($a: Int, $b: Int, $a: Int) {f(a: $a, b: $b)}`,
+			[]validate.Violation{
+				{validate.RuleVarNameUnique, 26,
+					`there can be only one variable named "$a"`},
+			},
+		},
+		{
+			decl(1),
+			`query($v: In = {a: 1, a: {b: 1, b: 2}}) {
+				f(a: [{x: 1, x: 2}], b: {c: [[{d: 1, d: 1}]]}) @d(a: {e: 1, e: 2})
+			}`,
+			[]validate.Violation{
+				{validate.RuleVarUsed, 6,
+					`variable "$v" is never used in anonymous operation`},
+				{validate.RuleObjFieldNameUnique, 22,
+					`there can be only one input field named "a"`},
+				{validate.RuleObjFieldNameUnique, 32,
+					`there can be only one input field named "b"`},
+				{validate.RuleObjFieldNameUnique, 59,
+					`there can be only one input field named "x"`},
+				{validate.RuleObjFieldNameUnique, 83,
+					`there can be only one input field named "d"`},
+				{validate.RuleObjFieldNameUnique, 106,
+					`there can be only one input field named "e"`},
+			},
+		},
+		{
+			decl(1),
+			`query Q($a: Int, $b: Int) @d(a: $a) {...F}
+			query R {...F}
+			fragment F on T {f(a: [{x: $c}]) {...G}}
+			fragment G on T {g @d(x: $b)}`,
+			[]validate.Violation{
+				{validate.RuleVarDefined, 91,
+					`variable "$c" is not defined by operation "Q"`},
+				{validate.RuleVarDefined, 91,
+					`variable "$c" is not defined by operation "R"`},
+				{validate.RuleVarDefined, 133,
+					`variable "$b" is not defined by operation "R"`},
+			},
+		},
+		{
+			decl(1),
+			`query Q($a: Int, $b: Int, $c: Int @d(x: $c)) {f(a: $a)}`,
+			[]validate.Violation{
+				{validate.RuleVarUsed, 17,
+					`variable "$b" is never used in operation "Q"`},
+			},
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			v, err := validate.Validate([]byte(td.input))
+			require.NoError(t, err)
+			require.Equal(t, td.expect, v)
+		})
+	}
+}
+
+func TestValidateErr(t *testing.T) {
+	_, err := validate.Validate([]byte(`{a(b: 1`))
+	var e gqlscan.Error
+	require.ErrorAs(t, err, &e)
+	require.Equal(t, gqlscan.ErrUnexpEOF, e.Code)
+}
+
+func TestViolationError(t *testing.T) {
+	v := validate.Violation{
+		Rule:  validate.RuleFragUnused,
+		Index: 4,
+		Msg:   `fragment "F" is never used`,
+	}
+	require.Equal(t,
+		`violation at index 4: fragment "F" is never used`, v.Error(),
+	)
+	require.Equal(t, "fragments must be used", v.Rule.String())
+}
+
+func decl(skipFrames int) string {
+	_, filename, line, _ := runtime.Caller(skipFrames)
+	return fmt.Sprintf("%s:%d", filepath.Base(filename), line)
+}
@@ -0,0 +1,151 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/graph-guard/gqlscan/internal/ast"
+)
+
+// VarError is an invalid variable value.
+type VarError struct {
+	// Path is the JSON pointer to the invalid value
+	// in the variables object, such as "/ids/1".
+	Path string
+
+	// Msg describes the error.
+	Msg string
+}
+
+func (e VarError) Error() string {
+	return e.Path + ": " + e.Msg
+}
+
+// Variables validates the JSON object of variable values vars
+// against the variable definitions of the operation called oprName
+// in src, or the only operation if oprName is empty,
+// and returns all errors in order of definition.
+//
+// The values of variables of non-null types must be present and
+// non-null unless the variable has a default value, list values
+// must match the nesting of list types and the values of built-in
+// scalar types must be of the matching JSON type. Non-list values
+// are accepted for list types like a list of one item.
+// Values of other named types aren't validated.
+//
+// Returns a gqlscan.Error if src isn't lexically valid and
+// gqlscan.ErrOprNotFound, gqlscan.ErrOprAmbiguous,
+// gqlscan.ErrFragUndefined or gqlscan.ErrFragAmbiguous
+// (possibly wrapped) if the operation can't be selected.
+func Variables(src, oprName, vars []byte) ([]VarError, error) {
+	d, err := ast.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	opr, err := d.Opr(oprName)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]interface{}
+	if len(bytes.TrimSpace(vars)) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(vars))
+		dec.UseNumber()
+		if err := dec.Decode(&values); err != nil {
+			return nil, fmt.Errorf("decoding variables: %w", err)
+		}
+	}
+
+	var errs []VarError
+	for _, vd := range opr.Vars {
+		// Names never contain '~' and '/', no escaping required
+		path := "/" + string(vd.Name.Val)
+		val, ok := values[string(vd.Name.Val)]
+		if !ok {
+			if vd.Type.NonNull && vd.Def == nil {
+				errs = append(errs, VarError{
+					Path: path,
+					Msg: fmt.Sprintf(
+						"missing value for non-null variable of type %q", vd.Type,
+					),
+				})
+			}
+			continue
+		}
+		errs = checkValue(errs, path, val, vd.Type)
+	}
+	return errs, nil
+}
+
+// checkValue appends an error to errs for every part of val
+// that isn't a valid value of type t.
+func checkValue(
+	errs []VarError, path string, val interface{}, t *ast.TypeRef,
+) []VarError {
+	if val == nil {
+		if t.NonNull {
+			errs = append(errs, VarError{
+				Path: path,
+				Msg:  fmt.Sprintf("expected non-null value of type %q", t),
+			})
+		}
+		return errs
+	}
+	if t.Elem != nil {
+		items, ok := val.([]interface{})
+		if !ok {
+			return checkValue(errs, path, val, t.Elem)
+		}
+		for n, item := range items {
+			errs = checkValue(errs, path+"/"+strconv.Itoa(n), item, t.Elem)
+		}
+		return errs
+	}
+	if !isScalarValue(val, string(t.Name)) {
+		errs = append(errs, VarError{
+			Path: path,
+			Msg:  fmt.Sprintf("expected value of type %q", t),
+		})
+	}
+	return errs
+}
+
+// isScalarValue returns false if typeName is a built-in scalar type
+// and val isn't a valid value of that type, otherwise returns true.
+func isScalarValue(val interface{}, typeName string) bool {
+	switch typeName {
+	case "Int":
+		n, ok := val.(json.Number)
+		return ok && isInt(n)
+	case "Float":
+		_, ok := val.(json.Number)
+		return ok
+	case "String":
+		_, ok := val.(string)
+		return ok
+	case "Boolean":
+		_, ok := val.(bool)
+		return ok
+	case "ID":
+		switch v := val.(type) {
+		case string:
+			return true
+		case json.Number:
+			_, err := strconv.ParseInt(v.String(), 10, 64)
+			return err == nil
+		}
+		return false
+	}
+	return true
+}
+
+// isInt returns true if n is an integral
+// 32-bit signed integer value.
+func isInt(n json.Number) bool {
+	f, err := n.Float64()
+	return err == nil && f == math.Trunc(f) &&
+		f >= math.MinInt32 && f <= math.MaxInt32
+}
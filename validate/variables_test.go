@@ -0,0 +1,156 @@
+package validate_test
+
+import (
+	"testing"
+
+	"github.com/graph-guard/gqlscan"
+	"github.com/graph-guard/gqlscan/validate"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariables(t *testing.T) {
+	for _, td := range []struct {
+		decl    string
+		input   string
+		oprName string
+		vars    string
+		expect  []validate.VarError
+	}{
+		{
+			decl(1),
+			`{a}`, "", ``,
+			nil,
+		},
+		{
+			decl(1),
+			`query($a: Int, $b: ID! = 1, $c: String) {a}`, "", `null`,
+			nil,
+		},
+		{
+			decl(1),
+			`query(
+				$i: Int!, $f: Float!, $s: String!, $b: Boolean!,
+				$id1: ID!, $id2: ID!, $e: Role!, $o: Filter!, $x: Int
+			) {a}`, "",
+			`{"i": 42, "f": 1, "s": "x", "b": false, "id1": "a", "id2": 7,
+			"e": "ADMIN", "o": {"a": [1]}, "x": null, "undeclared": 1}`,
+			nil,
+		},
+		{
+			decl(1),
+			`query($id: ID!, $d: ID! = "x", $n: Int = 1) {a}`, "",
+			`{"n": null}`,
+			[]validate.VarError{
+				{Path: "/id",
+					Msg: `missing value for non-null variable of type "ID!"`},
+			},
+		},
+		{
+			decl(1),
+			`query($id: ID!, $d: ID! = "x") {a}`, "",
+			`{"id": null, "d": null}`,
+			[]validate.VarError{
+				{Path: "/id", Msg: `expected non-null value of type "ID!"`},
+				{Path: "/d", Msg: `expected non-null value of type "ID!"`},
+			},
+		},
+		{
+			decl(1),
+			`query(
+				$i1: Int, $i2: Int, $i3: Int, $i4: Int, $f: Float,
+				$s: String, $b: Boolean, $id1: ID, $id2: ID
+			) {a}`, "",
+			`{"i1": 1.0, "i2": 1.5, "i3": 2147483648, "i4": "1", "f": "1.5",
+			"s": 1, "b": "true", "id1": 1.5, "id2": true}`,
+			[]validate.VarError{
+				{Path: "/i2", Msg: `expected value of type "Int"`},
+				{Path: "/i3", Msg: `expected value of type "Int"`},
+				{Path: "/i4", Msg: `expected value of type "Int"`},
+				{Path: "/f", Msg: `expected value of type "Float"`},
+				{Path: "/s", Msg: `expected value of type "String"`},
+				{Path: "/b", Msg: `expected value of type "Boolean"`},
+				{Path: "/id1", Msg: `expected value of type "ID"`},
+				{Path: "/id2", Msg: `expected value of type "ID"`},
+			},
+		},
+		{
+			decl(1),
+			`query($ids: [Int!], $m: [[String]!]!, $one: [Int], $n: [Int]!) {a}`, "",
+			`{"ids": [1, null, "3", 4], "m": [["a", null], null, "b", [1]],
+			"one": 1, "n": [null]}`,
+			[]validate.VarError{
+				{Path: "/ids/1", Msg: `expected non-null value of type "Int!"`},
+				{Path: "/ids/2", Msg: `expected value of type "Int!"`},
+				{Path: "/m/1", Msg: `expected non-null value of type "[String]!"`},
+				{Path: "/m/3/0", Msg: `expected value of type "String"`},
+			},
+		},
+		{
+			decl(1),
+			`query A {a} query B($v: [Boolean!]! @d(a: 1)) {b}`, "B",
+			`{"v": true}`,
+			nil,
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			errs, err := validate.Variables(
+				[]byte(td.input), []byte(td.oprName), []byte(td.vars),
+			)
+			require.NoError(t, err)
+			require.Equal(t, td.expect, errs)
+		})
+	}
+}
+
+func TestVariablesErr(t *testing.T) {
+	for _, td := range []struct {
+		decl    string
+		input   string
+		oprName string
+		vars    string
+		expect  error
+		msg     string
+	}{
+		{
+			decl(1),
+			`query A {a}`, "B", `{}`,
+			gqlscan.ErrOprNotFound, `operation not found: "B"`,
+		},
+		{
+			decl(1),
+			`query A {a} query B {b}`, "", `{}`,
+			gqlscan.ErrOprAmbiguous, `ambiguous operation`,
+		},
+		{
+			decl(1),
+			`query A {...F}`, "", `{}`,
+			gqlscan.ErrFragUndefined, `undefined fragment: "F"`,
+		},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			_, err := validate.Variables(
+				[]byte(td.input), []byte(td.oprName), []byte(td.vars),
+			)
+			require.ErrorIs(t, err, td.expect)
+			require.Equal(t, td.msg, err.Error())
+		})
+	}
+
+	t.Run("invalid variables", func(t *testing.T) {
+		_, err := validate.Variables([]byte(`{a}`), nil, []byte(`[1]`))
+		require.Error(t, err)
+	})
+
+	t.Run("scan error", func(t *testing.T) {
+		_, err := validate.Variables([]byte(`query($a: [Int) {a}`), nil, nil)
+		var e gqlscan.Error
+		require.ErrorAs(t, err, &e)
+		require.Equal(t, gqlscan.ErrInvalType, e.Code)
+	})
+}
+
+func TestVarError(t *testing.T) {
+	e := validate.VarError{Path: "/ids/1", Msg: "expected value"}
+	require.Equal(t, "/ids/1: expected value", e.Error())
+}
@@ -0,0 +1,628 @@
+package gqlscan
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/graph-guard/gqlscan/internal/names"
+)
+
+// ErrIncomplete is returned by Writer.Bytes if the document
+// isn't complete yet.
+var ErrIncomplete = errors.New("incomplete document")
+
+// Writer writes GraphQL executable documents.
+// The methods of the writer mirror the token types and
+// the written document scans to the same sequence of tokens.
+// Names, types and string values are validated and escaped.
+//
+// The first invalid call is recorded and makes all subsequent calls
+// no-ops, the error is returned by Bytes.
+type Writer struct {
+	buf []byte
+	err error
+
+	// stack holds the open brackets:
+	// '{' for selection sets, '(' for argument lists,
+	// '$' for variable lists, '[' for arrays and 'o' for objects.
+	stack []byte
+
+	// last is the type of the last written token.
+	last Token
+
+	// sel is the type of the current selection.
+	sel Token
+
+	// needSet is true if a selection set is required next.
+	needSet bool
+
+	defs int
+}
+
+// NewWriter creates a new writer appending to buf.
+func NewWriter(buf []byte) *Writer {
+	return &Writer{buf: buf}
+}
+
+// Bytes returns the written document.
+// Returns ErrIncomplete if the document isn't complete,
+// or the error of the first invalid call.
+func (w *Writer) Bytes() ([]byte, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	if len(w.stack) > 0 || w.needSet || w.defs < 1 {
+		return nil, ErrIncomplete
+	}
+	return w.buf, nil
+}
+
+// BeginQuery begins a query definition.
+// name is optional.
+func (w *Writer) BeginQuery(name string) {
+	w.beginOpr(TokenDefQry, "query", name)
+}
+
+// BeginMutation begins a mutation definition.
+// name is optional.
+func (w *Writer) BeginMutation(name string) {
+	w.beginOpr(TokenDefMut, "mutation", name)
+}
+
+// BeginSubscription begins a subscription definition.
+// name is optional.
+func (w *Writer) BeginSubscription(name string) {
+	w.beginOpr(TokenDefSub, "subscription", name)
+}
+
+// BeginFragment begins a fragment definition.
+func (w *Writer) BeginFragment(name, typeCond string) {
+	if !w.beginDef(TokenDefFrag) ||
+		!w.checkFragName(name) || !w.checkName(typeCond) {
+		return
+	}
+	w.buf = append(w.buf, "fragment "...)
+	w.buf = append(w.buf, name...)
+	w.buf = append(w.buf, " on "...)
+	w.buf = append(w.buf, typeCond...)
+	w.last = TokenFragTypeCond
+}
+
+// VarDef writes a variable definition.
+// Values written directly after the definition are written as
+// the default value of the variable.
+func (w *Writer) VarDef(name, typ string) {
+	w.closeArgs()
+	switch {
+	case w.err != nil:
+		return
+	case len(w.stack) == 0 && w.needSet &&
+		(w.last == TokenDefQry ||
+			w.last == TokenDefMut ||
+			w.last == TokenDefSub ||
+			w.last == TokenOprName):
+		w.buf = append(w.buf, '(')
+		w.stack = append(w.stack, '$')
+	case w.top() == '$' &&
+		(w.last == TokenVarTypeName ||
+			w.last == TokenDirName ||
+			w.last == TokenArgListEnd ||
+			isValEnd(w.last)):
+		w.buf = append(w.buf, ' ')
+	default:
+		w.fail(TokenVarName)
+		return
+	}
+	if !w.checkName(name) {
+		return
+	}
+	if !isType(typ) {
+		w.err = fmt.Errorf("invalid type %q", typ)
+		return
+	}
+	w.buf = append(w.buf, '$')
+	w.buf = append(w.buf, name...)
+	w.buf = append(w.buf, ": "...)
+	w.buf = append(w.buf, typ...)
+	w.last = TokenVarTypeName
+}
+
+// EndVarList ends the variable list.
+// Calling EndVarList is only necessary before writing
+// operation directives, the variable list is ended
+// automatically by BeginSet.
+func (w *Writer) EndVarList() {
+	w.closeArgs()
+	if w.err != nil {
+		return
+	}
+	if w.top() != '$' {
+		w.fail(TokenVarListEnd)
+		return
+	}
+	w.buf = append(w.buf, ')')
+	w.stack = w.stack[:len(w.stack)-1]
+	w.last = TokenVarListEnd
+}
+
+// Directive writes a directive.
+// Arguments written directly after the directive
+// are written as directive arguments.
+func (w *Writer) Directive(name string) {
+	w.closeArgs()
+	if w.err != nil {
+		return
+	}
+	switch w.last {
+	case TokenDefQry, TokenDefMut, TokenDefSub, TokenOprName,
+		TokenFragTypeCond, TokenVarListEnd, TokenVarTypeName,
+		TokenDirName, TokenArgListEnd,
+		TokenField, TokenNamedSpread, TokenFragInline:
+	default:
+		if !isValEnd(w.last) || w.top() != '$' {
+			w.fail(TokenDirName)
+			return
+		}
+	}
+	if !w.checkName(name) {
+		return
+	}
+	w.buf = append(w.buf, " @"...)
+	w.buf = append(w.buf, name...)
+	w.last = TokenDirName
+}
+
+// BeginSet begins a selection set.
+func (w *Writer) BeginSet() {
+	w.closeArgs()
+	if w.err != nil {
+		return
+	}
+	if w.top() == '$' {
+		w.EndVarList()
+	}
+	if !w.needSet && (w.top() != '{' || w.sel != TokenField ||
+		(w.last != TokenField &&
+			w.last != TokenArgListEnd &&
+			w.last != TokenDirName)) {
+		w.fail(TokenSet)
+		return
+	}
+	w.buf = append(w.buf, " {"...)
+	w.stack = append(w.stack, '{')
+	w.last, w.needSet = TokenSet, false
+}
+
+// EndSet ends a selection set.
+func (w *Writer) EndSet() {
+	if !w.selection(TokenSetEnd) || w.last == TokenSet {
+		w.fail(TokenSetEnd)
+		return
+	}
+	w.buf = append(w.buf, " }"...)
+	w.stack = w.stack[:len(w.stack)-1]
+	w.last = TokenSetEnd
+	if len(w.stack) == 0 {
+		// End of definition
+		w.last = 0
+	}
+}
+
+// Alias writes a field alias.
+// The aliased field must be written next.
+func (w *Writer) Alias(name string) {
+	if !w.selection(TokenFieldAlias) || !w.checkName(name) {
+		return
+	}
+	w.buf = append(w.buf, ' ')
+	w.buf = append(w.buf, name...)
+	w.buf = append(w.buf, ':')
+	w.last = TokenFieldAlias
+}
+
+// Field writes a field.
+func (w *Writer) Field(name string) {
+	if w.last != TokenFieldAlias && !w.selection(TokenField) ||
+		!w.checkName(name) {
+		return
+	}
+	w.buf = append(w.buf, ' ')
+	w.buf = append(w.buf, name...)
+	w.last, w.sel = TokenField, TokenField
+}
+
+// Spread writes a named fragment spread.
+func (w *Writer) Spread(name string) {
+	if !w.selection(TokenNamedSpread) || !w.checkFragName(name) {
+		return
+	}
+	w.buf = append(w.buf, " ..."...)
+	w.buf = append(w.buf, name...)
+	w.last, w.sel = TokenNamedSpread, TokenNamedSpread
+}
+
+// BeginFragInline begins an inline fragment.
+// typeCond is optional.
+// The selection set of the fragment must be begun after
+// the optional directives.
+func (w *Writer) BeginFragInline(typeCond string) {
+	if !w.selection(TokenFragInline) {
+		return
+	}
+	w.buf = append(w.buf, " ..."...)
+	if typeCond != "" {
+		if !w.checkName(typeCond) {
+			return
+		}
+		w.buf = append(w.buf, "on "...)
+		w.buf = append(w.buf, typeCond...)
+	}
+	w.last, w.sel, w.needSet = TokenFragInline, TokenFragInline, true
+}
+
+// Arg writes an argument name.
+// The value of the argument must be written next.
+func (w *Writer) Arg(name string) {
+	switch {
+	case w.err != nil:
+		return
+	case w.top() == '(' && isValEnd(w.last):
+		w.buf = append(w.buf, ' ')
+	case w.last == TokenField || w.last == TokenDirName:
+		w.buf = append(w.buf, '(')
+		w.stack = append(w.stack, '(')
+	default:
+		w.fail(TokenArgName)
+		return
+	}
+	if !w.checkName(name) {
+		return
+	}
+	w.buf = append(w.buf, name...)
+	w.buf = append(w.buf, ": "...)
+	w.last = TokenArgName
+}
+
+// Str writes a string value.
+func (w *Writer) Str(s string) {
+	if !utf8.ValidString(s) {
+		w.setErr(errors.New("invalid UTF-8 string"))
+	}
+	if !w.value(TokenStr) {
+		return
+	}
+	w.buf = append(w.buf, '"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			w.buf = append(w.buf, '\\', byte(r))
+		case r == '\n':
+			w.buf = append(w.buf, `\n`...)
+		case r == '\r':
+			w.buf = append(w.buf, `\r`...)
+		case r == '\t':
+			w.buf = append(w.buf, `\t`...)
+		case r < 0x20:
+			w.buf = append(w.buf, `\u00`...)
+			w.buf = append(w.buf, "0123456789abcdef"[r>>4])
+			w.buf = append(w.buf, "0123456789abcdef"[r&0xF])
+		default:
+			w.buf = utf8.AppendRune(w.buf, r)
+		}
+	}
+	w.buf = append(w.buf, '"')
+}
+
+// StrBlock writes a block string value with s as its raw body.
+// Only triple quotes are escaped, the indentation and
+// leading and trailing blank lines of s are subject to
+// the block string value semantics.
+// s must not end with a quote or a backslash, which would escape
+// the closing triple quote, and must not contain control characters
+// other than horizontal tabs, line-feeds and carriage-returns.
+func (w *Writer) StrBlock(s string) {
+	switch {
+	case !utf8.ValidString(s):
+		w.setErr(errors.New("invalid UTF-8 block string"))
+	case strings.HasSuffix(s, `"`):
+		w.setErr(errors.New("block string ending with a quote"))
+	case strings.HasSuffix(s, `\`):
+		w.setErr(errors.New("block string ending with a backslash"))
+	default:
+		for i := 0; i < len(s); i++ {
+			if s[i] < 0x20 && s[i] != '\t' && s[i] != '\n' && s[i] != '\r' {
+				w.setErr(fmt.Errorf(
+					"illegal control character 0x%x in block string", s[i],
+				))
+				break
+			}
+		}
+	}
+	if !w.value(TokenStrBlock) {
+		return
+	}
+	w.buf = append(w.buf, `"""`...)
+	w.buf = append(w.buf, strings.ReplaceAll(s, `"""`, `\"""`)...)
+	w.buf = append(w.buf, `"""`...)
+}
+
+// Int writes an integer value.
+func (w *Writer) Int(v int64) {
+	if !w.value(TokenInt) {
+		return
+	}
+	w.buf = strconv.AppendInt(w.buf, v, 10)
+}
+
+// Float writes a float value.
+func (w *Writer) Float(v float64) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		w.setErr(fmt.Errorf("invalid float value %v", v))
+	}
+	if !w.value(TokenFloat) {
+		return
+	}
+	l := len(w.buf)
+	w.buf = strconv.AppendFloat(w.buf, v, 'g', -1, 64)
+	if !strings.ContainsAny(string(w.buf[l:]), ".e") {
+		// Make sure it's not scanned as an integer
+		w.buf = append(w.buf, ".0"...)
+	}
+}
+
+// Bool writes a boolean value.
+func (w *Writer) Bool(v bool) {
+	t := TokenFalse
+	if v {
+		t = TokenTrue
+	}
+	if !w.value(t) {
+		return
+	}
+	w.buf = strconv.AppendBool(w.buf, v)
+}
+
+// Null writes a null value.
+func (w *Writer) Null() {
+	if !w.value(TokenNull) {
+		return
+	}
+	w.buf = append(w.buf, "null"...)
+}
+
+// Enum writes an enum value.
+func (w *Writer) Enum(name string) {
+	if name == "true" || name == "false" || name == "null" {
+		w.setErr(fmt.Errorf("invalid enum value %q", name))
+	}
+	if !w.checkName(name) || !w.value(TokenEnumVal) {
+		return
+	}
+	w.buf = append(w.buf, name...)
+}
+
+// VarRef writes a variable reference.
+func (w *Writer) VarRef(name string) {
+	if strings.IndexByte(string(w.stack), '$') != -1 {
+		// Default values and variable directives are constant
+		w.fail(TokenVarRef)
+	}
+	if !w.checkName(name) || !w.value(TokenVarRef) {
+		return
+	}
+	w.buf = append(w.buf, '$')
+	w.buf = append(w.buf, name...)
+}
+
+// BeginArr begins an array value.
+func (w *Writer) BeginArr() {
+	if !w.value(TokenArr) {
+		return
+	}
+	w.buf = append(w.buf, '[')
+	w.stack = append(w.stack, '[')
+}
+
+// EndArr ends an array value.
+func (w *Writer) EndArr() {
+	if w.err != nil {
+		return
+	}
+	if w.top() != '[' || (w.last != TokenArr && !isValEnd(w.last)) {
+		w.fail(TokenArrEnd)
+		return
+	}
+	w.buf = append(w.buf, ']')
+	w.stack = w.stack[:len(w.stack)-1]
+	w.last = TokenArrEnd
+}
+
+// BeginObj begins an object value.
+func (w *Writer) BeginObj() {
+	if !w.value(TokenObj) {
+		return
+	}
+	w.buf = append(w.buf, '{')
+	w.stack = append(w.stack, 'o')
+}
+
+// ObjField writes an object field name.
+// The value of the field must be written next.
+func (w *Writer) ObjField(name string) {
+	switch {
+	case w.err != nil:
+		return
+	case w.top() == 'o' && w.last == TokenObj:
+	case w.top() == 'o' && isValEnd(w.last):
+		w.buf = append(w.buf, ' ')
+	default:
+		w.fail(TokenObjField)
+		return
+	}
+	if !w.checkName(name) {
+		return
+	}
+	w.buf = append(w.buf, name...)
+	w.buf = append(w.buf, ": "...)
+	w.last = TokenObjField
+}
+
+// EndObj ends an object value.
+func (w *Writer) EndObj() {
+	if w.err != nil {
+		return
+	}
+	if w.top() != 'o' || !isValEnd(w.last) {
+		// Empty objects aren't accepted by the scanner
+		w.fail(TokenObjEnd)
+		return
+	}
+	w.buf = append(w.buf, '}')
+	w.stack = w.stack[:len(w.stack)-1]
+	w.last = TokenObjEnd
+}
+
+func (w *Writer) beginOpr(t Token, keyword, name string) {
+	if !w.beginDef(t) {
+		return
+	}
+	w.buf = append(w.buf, keyword...)
+	if name != "" {
+		if !w.checkName(name) {
+			return
+		}
+		w.buf = append(w.buf, ' ')
+		w.buf = append(w.buf, name...)
+		t = TokenOprName
+	}
+	w.last = t
+}
+
+func (w *Writer) beginDef(t Token) bool {
+	if w.err != nil {
+		return false
+	}
+	if w.last != 0 || len(w.stack) > 0 {
+		w.fail(t)
+		return false
+	}
+	if w.defs > 0 {
+		w.buf = append(w.buf, '\n')
+	}
+	w.defs++
+	w.needSet = true
+	return true
+}
+
+// selection returns true if a selection or the end of a selection set
+// represented by t can be written next.
+func (w *Writer) selection(t Token) bool {
+	w.closeArgs()
+	if w.err != nil {
+		return false
+	}
+	if w.needSet || w.top() != '{' {
+		w.fail(t)
+		return false
+	}
+	switch w.last {
+	case TokenSet, TokenSetEnd, TokenField, TokenArgListEnd,
+		TokenDirName, TokenNamedSpread:
+		return true
+	}
+	w.fail(t)
+	return false
+}
+
+// value returns true if a value of type t can be written next
+// and writes the necessary separators.
+func (w *Writer) value(t Token) bool {
+	if w.err != nil {
+		return false
+	}
+	switch {
+	case w.last == TokenArgName || w.last == TokenObjField:
+	case w.top() == '[' && w.last == TokenArr:
+	case w.top() == '[' && isValEnd(w.last):
+		w.buf = append(w.buf, ' ')
+	case w.top() == '$' && w.last == TokenVarTypeName:
+		w.buf = append(w.buf, " = "...)
+	default:
+		w.fail(t)
+		return false
+	}
+	w.last = t
+	return true
+}
+
+// closeArgs closes an argument list if one was completed.
+func (w *Writer) closeArgs() {
+	if w.err == nil && w.top() == '(' && isValEnd(w.last) {
+		w.buf = append(w.buf, ')')
+		w.stack = w.stack[:len(w.stack)-1]
+		w.last = TokenArgListEnd
+	}
+}
+
+func (w *Writer) top() byte {
+	if len(w.stack) < 1 {
+		return 0
+	}
+	return w.stack[len(w.stack)-1]
+}
+
+func (w *Writer) checkName(name string) bool {
+	if w.err != nil {
+		return false
+	}
+	if !names.IsName(name) {
+		w.err = fmt.Errorf("invalid name %q", name)
+		return false
+	}
+	return true
+}
+
+func (w *Writer) checkFragName(name string) bool {
+	if name == "on" {
+		w.setErr(fmt.Errorf("illegal fragment name %q", name))
+	}
+	return w.checkName(name)
+}
+
+func (w *Writer) fail(t Token) {
+	if w.last == 0 {
+		w.setErr(fmt.Errorf("unexpected %s", t))
+		return
+	}
+	w.setErr(fmt.Errorf("unexpected %s after %s", t, w.last))
+}
+
+func (w *Writer) setErr(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// isValEnd returns true if t completes a value.
+func isValEnd(t Token) bool {
+	switch t {
+	case TokenStr, TokenStrBlock, TokenInt, TokenFloat,
+		TokenTrue, TokenFalse, TokenNull, TokenEnumVal,
+		TokenVarRef, TokenArrEnd, TokenObjEnd:
+		return true
+	}
+	return false
+}
+
+// isType returns true if s is a valid type reference.
+func isType(s string) bool {
+	s = strings.TrimSuffix(s, "!")
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		return isType(s[1 : len(s)-1])
+	}
+	return names.IsName(s)
+}
@@ -0,0 +1,426 @@
+package gqlscan_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/graph-guard/gqlscan"
+
+	"github.com/stretchr/testify/require"
+)
+
+type TestInputWriter struct {
+	decl   string
+	write  func(w *gqlscan.Writer)
+	output string
+	expect []Expect
+}
+
+var testdataWriter = []TestInputWriter{
+	InputWriter(
+		func(w *gqlscan.Writer) {
+			w.BeginQuery("")
+			w.BeginSet()
+			w.Field("foo")
+			w.EndSet()
+		},
+		`query { foo }`,
+		Token(gqlscan.TokenDefQry),
+		Token(gqlscan.TokenSet),
+		Token(gqlscan.TokenField, "foo"),
+		Token(gqlscan.TokenSetEnd),
+	),
+	InputWriter(
+		func(w *gqlscan.Writer) {
+			w.BeginQuery("Q")
+			w.VarDef("a", "[Int!]!")
+			w.BeginArr()
+			w.Int(1)
+			w.Int(-2)
+			w.EndArr()
+			w.Directive("vd")
+			w.Arg("x")
+			w.Bool(true)
+			w.VarDef("b", "In")
+			w.EndVarList()
+			w.Directive("od")
+			w.Arg("v")
+			w.VarRef("b")
+			w.BeginSet()
+			w.Alias("x")
+			w.Field("f")
+			w.Arg("a")
+			w.BeginObj()
+			w.ObjField("s")
+			w.Str("a\"\\\n\t\x01é")
+			w.ObjField("n")
+			w.Null()
+			w.EndObj()
+			w.Arg("e")
+			w.Enum("RED")
+			w.Directive("include")
+			w.Arg("if")
+			w.Bool(false)
+			w.BeginSet()
+			w.Spread("F")
+			w.Directive("sd")
+			w.BeginFragInline("T")
+			w.Directive("id")
+			w.BeginSet()
+			w.Field("y")
+			w.Arg("f")
+			w.Float(1)
+			w.Arg("g")
+			w.Float(-2.5e-10)
+			w.EndSet()
+			w.BeginFragInline("")
+			w.BeginSet()
+			w.Field("z")
+			w.Arg("b")
+			w.StrBlock("block \"\"\" string\n\t")
+			w.EndSet()
+			w.EndSet()
+			w.EndSet()
+			w.BeginFragment("F", "T")
+			w.Directive("fd")
+			w.BeginSet()
+			w.Field("w")
+			w.EndSet()
+		},
+		`query Q($a: [Int!]! = [1 -2] @vd(x: true) $b: In) @od(v: $b) {`+
+			` x: f(a: {s: "a\"\\\n\t\u0001é" n: null} e: RED)`+
+			` @include(if: false) { ...F @sd ...on T @id`+
+			` { y(f: 1.0 g: -2.5e-10) } ...`+
+			` { z(b: """block \""" string`+"\n\t"+`""") } } }`+"\n"+
+			`fragment F on T @fd { w }`,
+		Token(gqlscan.TokenDefQry),
+		Token(gqlscan.TokenOprName, "Q"),
+		Token(gqlscan.TokenVarList),
+		Token(gqlscan.TokenVarName, "a"),
+		Token(gqlscan.TokenVarTypeArr),
+		Token(gqlscan.TokenVarTypeName, "Int"),
+		Token(gqlscan.TokenVarTypeNotNull),
+		Token(gqlscan.TokenVarTypeArrEnd),
+		Token(gqlscan.TokenVarTypeNotNull),
+		Token(gqlscan.TokenArr),
+		Token(gqlscan.TokenInt, "1"),
+		Token(gqlscan.TokenInt, "-2"),
+		Token(gqlscan.TokenArrEnd),
+		Token(gqlscan.TokenDirName, "vd"),
+		Token(gqlscan.TokenArgList),
+		Token(gqlscan.TokenArgName, "x"),
+		Token(gqlscan.TokenTrue),
+		Token(gqlscan.TokenArgListEnd),
+		Token(gqlscan.TokenVarName, "b"),
+		Token(gqlscan.TokenVarTypeName, "In"),
+		Token(gqlscan.TokenVarListEnd),
+		Token(gqlscan.TokenDirName, "od"),
+		Token(gqlscan.TokenArgList),
+		Token(gqlscan.TokenArgName, "v"),
+		Token(gqlscan.TokenVarRef, "b"),
+		Token(gqlscan.TokenArgListEnd),
+		Token(gqlscan.TokenSet),
+		Token(gqlscan.TokenFieldAlias, "x"),
+		Token(gqlscan.TokenField, "f"),
+		Token(gqlscan.TokenArgList),
+		Token(gqlscan.TokenArgName, "a"),
+		Token(gqlscan.TokenObj),
+		Token(gqlscan.TokenObjField, "s"),
+		Token(gqlscan.TokenStr, `a\"\\\n\t\u0001é`),
+		Token(gqlscan.TokenObjField, "n"),
+		Token(gqlscan.TokenNull),
+		Token(gqlscan.TokenObjEnd),
+		Token(gqlscan.TokenArgName, "e"),
+		Token(gqlscan.TokenEnumVal, "RED"),
+		Token(gqlscan.TokenArgListEnd),
+		Token(gqlscan.TokenDirName, "include"),
+		Token(gqlscan.TokenArgList),
+		Token(gqlscan.TokenArgName, "if"),
+		Token(gqlscan.TokenFalse),
+		Token(gqlscan.TokenArgListEnd),
+		Token(gqlscan.TokenSet),
+		Token(gqlscan.TokenNamedSpread, "F"),
+		Token(gqlscan.TokenDirName, "sd"),
+		Token(gqlscan.TokenFragInline, "T"),
+		Token(gqlscan.TokenDirName, "id"),
+		Token(gqlscan.TokenSet),
+		Token(gqlscan.TokenField, "y"),
+		Token(gqlscan.TokenArgList),
+		Token(gqlscan.TokenArgName, "f"),
+		Token(gqlscan.TokenFloat, "1.0"),
+		Token(gqlscan.TokenArgName, "g"),
+		Token(gqlscan.TokenFloat, "-2.5e-10"),
+		Token(gqlscan.TokenArgListEnd),
+		Token(gqlscan.TokenSetEnd),
+		Token(gqlscan.TokenFragInline),
+		Token(gqlscan.TokenSet),
+		Token(gqlscan.TokenField, "z"),
+		Token(gqlscan.TokenArgList),
+		Token(gqlscan.TokenArgName, "b"),
+		Token(gqlscan.TokenStrBlock, "block \\\"\"\" string\n\t"),
+		Token(gqlscan.TokenArgListEnd),
+		Token(gqlscan.TokenSetEnd),
+		Token(gqlscan.TokenSetEnd),
+		Token(gqlscan.TokenSetEnd),
+		Token(gqlscan.TokenDefFrag),
+		Token(gqlscan.TokenFragName, "F"),
+		Token(gqlscan.TokenFragTypeCond, "T"),
+		Token(gqlscan.TokenDirName, "fd"),
+		Token(gqlscan.TokenSet),
+		Token(gqlscan.TokenField, "w"),
+		Token(gqlscan.TokenSetEnd),
+	),
+	InputWriter(
+		func(w *gqlscan.Writer) {
+			w.BeginMutation("")
+			w.VarDef("v", "I")
+			w.Int(0)
+			w.BeginSet()
+			w.Field("m")
+			w.Arg("a")
+			w.BeginArr()
+			w.EndArr()
+			w.Arg("o")
+			w.BeginObj()
+			w.ObjField("x")
+			w.BeginArr()
+			w.EndArr()
+			w.EndObj()
+			w.BeginSet()
+			w.Field("x")
+			w.EndSet()
+			w.EndSet()
+			w.BeginSubscription("S")
+			w.BeginSet()
+			w.Field("s")
+			w.EndSet()
+		},
+		`mutation($v: I = 0) { m(a: [] o: {x: []}) { x } }`+"\n"+
+			`subscription S { s }`,
+		Token(gqlscan.TokenDefMut),
+		Token(gqlscan.TokenVarList),
+		Token(gqlscan.TokenVarName, "v"),
+		Token(gqlscan.TokenVarTypeName, "I"),
+		Token(gqlscan.TokenInt, "0"),
+		Token(gqlscan.TokenVarListEnd),
+		Token(gqlscan.TokenSet),
+		Token(gqlscan.TokenField, "m"),
+		Token(gqlscan.TokenArgList),
+		Token(gqlscan.TokenArgName, "a"),
+		Token(gqlscan.TokenArr),
+		Token(gqlscan.TokenArrEnd),
+		Token(gqlscan.TokenArgName, "o"),
+		Token(gqlscan.TokenObj),
+		Token(gqlscan.TokenObjField, "x"),
+		Token(gqlscan.TokenArr),
+		Token(gqlscan.TokenArrEnd),
+		Token(gqlscan.TokenObjEnd),
+		Token(gqlscan.TokenArgListEnd),
+		Token(gqlscan.TokenSet),
+		Token(gqlscan.TokenField, "x"),
+		Token(gqlscan.TokenSetEnd),
+		Token(gqlscan.TokenSetEnd),
+		Token(gqlscan.TokenDefSub),
+		Token(gqlscan.TokenOprName, "S"),
+		Token(gqlscan.TokenSet),
+		Token(gqlscan.TokenField, "s"),
+		Token(gqlscan.TokenSetEnd),
+	),
+	InputWriter(
+		func(w *gqlscan.Writer) {
+			w.BeginQuery("")
+			w.BeginSet()
+			w.Field("f")
+			w.Arg("a")
+			w.StrBlock(`a\b\"""c\\d`)
+			w.EndSet()
+		},
+		`query { f(a: """a\b\\"""c\\d""") }`,
+		Token(gqlscan.TokenDefQry),
+		Token(gqlscan.TokenSet),
+		Token(gqlscan.TokenField, "f"),
+		Token(gqlscan.TokenArgList),
+		Token(gqlscan.TokenArgName, "a"),
+		Token(gqlscan.TokenStrBlock, `a\b\\"""c\\d`),
+		Token(gqlscan.TokenArgListEnd),
+		Token(gqlscan.TokenSetEnd),
+	),
+}
+
+func TestWriter(t *testing.T) {
+	for _, td := range testdataWriter {
+		t.Run(td.decl, func(t *testing.T) {
+			w := gqlscan.NewWriter(nil)
+			td.write(w)
+			out, err := w.Bytes()
+			require.NoError(t, err)
+			require.Equal(t, td.output, string(out))
+
+			j := 0
+			serr := gqlscan.ScanAll(out, func(i *gqlscan.Iterator) {
+				require.True(
+					t, j < len(td.expect),
+					"exceeding expectation set at: %d {T: %s; V: %s}",
+					j, i.Token().String(), i.Value(),
+				)
+				require.Equal(
+					t, td.expect[j].Type.String(), i.Token().String(),
+					"unexpected type at index %d (%s)",
+					j, td.expect[j].Decl,
+				)
+				require.Equal(
+					t, td.expect[j].Value, string(i.Value()),
+					"unexpected value at index %d (%s)",
+					j, td.expect[j].Decl,
+				)
+				j++
+			})
+			require.False(t, serr.IsErr(), serr.Error())
+			for _, e := range td.expect[j:] {
+				t.Errorf("missing {T: %s; V: %s}", e.Type, e.Value)
+			}
+		})
+	}
+}
+
+func TestWriterErr(t *testing.T) {
+	for _, td := range []struct {
+		decl   string
+		write  func(w *gqlscan.Writer)
+		expect string
+	}{
+		{decl(1), func(w *gqlscan.Writer) {}, "incomplete document"},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.BeginQuery("")
+		}, "incomplete document"},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.BeginQuery("")
+			w.BeginSet()
+			w.Field("f")
+		}, "incomplete document"},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.Field("f")
+		}, "unexpected field"},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.BeginQuery("")
+			w.BeginSet()
+			w.EndSet()
+		}, "unexpected selection set end after selection set"},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.BeginQuery("1nvalid")
+		}, `invalid name "1nvalid"`},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.BeginFragment("on", "T")
+		}, `illegal fragment name "on"`},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.BeginQuery("")
+			w.VarDef("v", "[Int")
+		}, `invalid type "[Int"`},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.BeginQuery("")
+			w.VarDef("v", "Int")
+			w.VarRef("x")
+		}, "unexpected variable reference after variable type name"},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.BeginQuery("")
+			w.BeginSet()
+			w.Field("f")
+			w.Arg("a")
+			w.Field("g")
+		}, "unexpected field after argument name"},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.BeginQuery("")
+			w.BeginSet()
+			w.Field("f")
+			w.Arg("a")
+			w.Enum("null")
+		}, `invalid enum value "null"`},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.BeginQuery("")
+			w.BeginSet()
+			w.Field("f")
+			w.Arg("a")
+			w.Float(math.NaN())
+		}, "invalid float value NaN"},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.BeginQuery("")
+			w.BeginSet()
+			w.Field("f")
+			w.Arg("a")
+			w.Str("\xff")
+		}, "invalid UTF-8 string"},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.BeginQuery("")
+			w.BeginSet()
+			w.Field("f")
+			w.Arg("a")
+			w.StrBlock(`"`)
+		}, "block string ending with a quote"},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.BeginQuery("")
+			w.BeginSet()
+			w.Field("f")
+			w.Arg("a")
+			w.StrBlock(`abc\`)
+		}, "block string ending with a backslash"},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.BeginQuery("")
+			w.BeginSet()
+			w.Field("f")
+			w.Arg("a")
+			w.StrBlock("\x00")
+		}, "illegal control character 0x0 in block string"},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.BeginQuery("")
+			w.BeginSet()
+			w.Spread("F")
+			w.BeginSet()
+		}, "unexpected selection set after named spread"},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.BeginQuery("")
+			w.BeginSet()
+			w.BeginFragInline("T")
+			w.Field("f")
+		}, "unexpected field after fragment inline"},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.BeginFragment("F", "T")
+			w.VarDef("v", "T")
+		}, "unexpected variable name after fragment type condition"},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.BeginQuery("")
+			w.BeginSet()
+			w.Field("f")
+			w.Arg("a")
+			w.BeginArr()
+			w.ObjField("x")
+		}, "unexpected object field after array"},
+		{decl(1), func(w *gqlscan.Writer) {
+			w.BeginQuery("")
+			w.BeginSet()
+			w.Field("f")
+			w.Arg("a")
+			w.BeginObj()
+			w.EndObj()
+		}, "unexpected object end after object"},
+	} {
+		t.Run(td.decl, func(t *testing.T) {
+			w := gqlscan.NewWriter(nil)
+			td.write(w)
+			out, err := w.Bytes()
+			require.Error(t, err)
+			require.Equal(t, td.expect, err.Error())
+			require.Nil(t, out)
+		})
+	}
+}
+
+func InputWriter(
+	write func(w *gqlscan.Writer), output string, e ...Expect,
+) TestInputWriter {
+	return TestInputWriter{
+		decl:   decl(2),
+		write:  write,
+		output: output,
+		expect: e,
+	}
+}